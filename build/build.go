@@ -239,6 +239,19 @@ func parseOverlayFiles(xctx XContext, pkg *PackageData, isTest bool, fileSet *to
 	return jsFiles, files
 }
 
+// overlayTestFiles returns the test files of the package that came from
+// the natives overlay.
+func overlayTestFiles(srcs *sources.Sources) []*ast.File {
+	var files []*ast.File
+	for _, file := range srcs.Files {
+		name := filepath.Base(srcs.FileSet.Position(file.Pos()).Filename)
+		if strings.HasPrefix(name, "gopherjs__") && strings.HasSuffix(name, "_test.go") {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
 // parserOriginalFiles loads and parses the original files to augment.
 func parserOriginalFiles(pkg *PackageData, fileSet *token.FileSet) ([]*ast.File, error) {
 	var files []*ast.File
@@ -608,6 +621,9 @@ type Options struct {
 	BuildTags      []string
 	TestedPackage  string
 	NoCache        bool
+	// BlockingCallbackErrors reports functions passed to JavaScript that may
+	// block as errors instead of warnings.
+	BlockingCallbackErrors bool
 }
 
 // PrintError message to the terminal.
@@ -618,6 +634,14 @@ func (o *Options) PrintError(format string, a ...any) {
 	fmt.Fprintf(os.Stderr, format, a...)
 }
 
+// PrintWarning message to the terminal.
+func (o *Options) PrintWarning(format string, a ...any) {
+	if o.Color {
+		format = "\x1B[33m" + format + "\x1B[39m"
+	}
+	fmt.Fprintf(os.Stderr, format, a...)
+}
+
 // PrintSuccess message to the terminal.
 func (o *Options) PrintSuccess(format string, a ...any) {
 	if o.Color {
@@ -975,11 +999,11 @@ func (s *Session) GetSortedSources() []*sources.Sources {
 }
 
 func (s *Session) loadTestPackage(pkg *PackageData) (*sources.Sources, error) {
-	_, err := s.LoadPackages(pkg.TestPackage())
+	testSrcs, err := s.LoadPackages(pkg.TestPackage())
 	if err != nil {
 		return nil, err
 	}
-	_, err = s.LoadPackages(pkg.XTestPackage())
+	xTestSrcs, err := s.LoadPackages(pkg.XTestPackage())
 	if err != nil {
 		return nil, err
 	}
@@ -988,6 +1012,12 @@ func (s *Session) loadTestPackage(pkg *PackageData) (*sources.Sources, error) {
 	fset := token.NewFileSet()
 	tests := testmain.TestMain{Package: pkg.Package, Context: pkg.bctx}
 	tests.Scan(fset)
+	if err := tests.ScanOverlay(overlayTestFiles(testSrcs), testmain.LocInPackage); err != nil {
+		return nil, err
+	}
+	if err := tests.ScanOverlay(overlayTestFiles(xTestSrcs), testmain.LocExternal); err != nil {
+		return nil, err
+	}
 	mainPkg, mainFile, err := tests.Synthesize(fset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate testmain package for %s: %w", pkg.ImportPath, err)
@@ -1192,6 +1222,9 @@ func (s *Session) prepareAndCompilePackages(rootSrcs *sources.Sources) (*compile
 	if err := compiler.PrepareAllSources(allSources, s.SourcesForImport, tContext); err != nil {
 		return nil, err
 	}
+	if err := s.checkBlockingCallbacks(allSources); err != nil {
+		return nil, err
+	}
 
 	// Compile all the sources into archives.
 	for _, srcs := range allSources {
@@ -1208,6 +1241,60 @@ func (s *Session) prepareAndCompilePackages(rootSrcs *sources.Sources) (*compile
 	return rootArchive, nil
 }
 
+// checkBlockingCallbacks reports functions passed to JavaScript that may
+// block, which fails at runtime unless they run in a goroutine. They are
+// printed as warnings unless the BlockingCallbackErrors option is set.
+// Packages of the standard library and of GopherJS itself are skipped, along
+// with their test variants, since users can't change them.
+func (s *Session) checkBlockingCallbacks(allSources []*sources.Sources) error {
+	if s.options.Quiet && !s.options.BlockingCallbackErrors {
+		return nil
+	}
+	gorootSrc := filepath.Join(s.xctx.Env().GOROOT, "src") + string(filepath.Separator)
+	var errList errlist.ErrorList
+	for _, srcs := range allSources {
+		importPath := strings.TrimSuffix(srcs.ImportPath, "_test")
+		if importPath == gopherjsModule || strings.HasPrefix(importPath, gopherjsModule+"/") || strings.HasPrefix(srcs.Dir+string(filepath.Separator), gorootSrc) {
+			continue
+		}
+		for _, cb := range srcs.TypeInfo.BlockingCallbacks() {
+			cb.Pos = s.sourcePosition(cb.Pos)
+			for i := range cb.Chain {
+				cb.Chain[i].Pos = s.sourcePosition(cb.Chain[i].Pos)
+			}
+			if s.options.BlockingCallbackErrors {
+				errList = errList.Append(cb)
+			} else {
+				s.options.PrintWarning("warning: %s\n", cb)
+			}
+		}
+	}
+	return errList.ErrOrNil()
+}
+
+// gopherjsModule is the import path of the GopherJS module.
+const gopherjsModule = "github.com/gopherjs/gopherjs"
+
+// sourcePosition returns pos with the name of the file users can find its
+// source in. The embedded GopherJS packages and the natives overlaying
+// standard library packages are parsed with names of files in GOROOT that
+// don't exist, so they are named relative to the GopherJS module instead.
+func (s *Session) sourcePosition(pos token.Position) token.Position {
+	rel, err := filepath.Rel(filepath.Join(s.xctx.Env().GOROOT, "src"), pos.Filename)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return pos
+	}
+	rel = filepath.ToSlash(rel)
+	dir, name := path.Split(rel)
+	switch {
+	case strings.HasPrefix(rel, gopherjsModule+"/"):
+		pos.Filename = rel
+	case strings.HasPrefix(name, "gopherjs__"):
+		pos.Filename = path.Join(gopherjsModule, "compiler/natives/src", dir, strings.TrimPrefix(name, "gopherjs__"))
+	}
+	return pos
+}
+
 func (s *Session) compilePackage(srcs *sources.Sources, tContext *types.Context) (*compiler.Archive, error) {
 	if archive, ok := s.UpToDateArchives[srcs.ImportPath]; ok {
 		return archive, nil
@@ -13,6 +13,7 @@ import (
 	"golang.org/x/tools/go/buildutil"
 
 	"github.com/gopherjs/gopherjs/compiler/gopherjspkg"
+	"github.com/gopherjs/gopherjs/compiler/incjs"
 )
 
 func init() {
@@ -39,7 +40,10 @@ func TestSimpleCtx(t *testing.T) {
 				name:     `embeddedCtx`,
 				buildCtx: ec,
 				wantPkg: &PackageData{
-					Package:   expectedPackage(&ec.bctx, "github.com/gopherjs/gopherjs/js", "wasm"),
+					Package: expectedPackage(&ec.bctx, "github.com/gopherjs/gopherjs/js", "wasm"),
+					JSFiles: []incjs.File{{
+						Path: filepath.Join(e.GOROOT, "src", "github.com/gopherjs/gopherjs/js", "marshal.inc.js"),
+					}},
 					IsVirtual: true,
 				},
 			}, {
@@ -59,7 +63,11 @@ func TestSimpleCtx(t *testing.T) {
 				if err != nil {
 					t.Fatalf("ec.Import(%q) returned error: %s. Want: no error.", importPath, err)
 				}
-				if diff := cmp.Diff(test.wantPkg, got, cmpopts.IgnoreUnexported(*got)); diff != "" {
+				opts := cmp.Options{
+					cmpopts.IgnoreUnexported(*got),
+					cmpopts.IgnoreFields(incjs.File{}, "ModTime", "Content"),
+				}
+				if diff := cmp.Diff(test.wantPkg, got, opts); diff != "" {
 					t.Errorf("ec.Import(%q) returned diff (-want,+got):\n%s", importPath, diff)
 				}
 			})
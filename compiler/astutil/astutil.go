@@ -178,6 +178,23 @@ func OverrideSignature(d *ast.FuncDecl) bool {
 	return hasDirective(d, `override-signature`)
 }
 
+// Import returns the argument of the gopherjs:import directive on a function
+// and whether the directive is present.
+//
+// `//gopherjs:import` is a GopherJS-specific directive, which binds a Go
+// function declared without a body to a JavaScript function, for example:
+//
+//	//gopherjs:import Math.hypot
+//	func hypot(x, y float64) float64
+//
+// The argument is a property path looked up in the global object, optionally
+// prefixed with `new` to call a constructor. The compiler calls the function
+// directly, converting arguments and the result according to the Go
+// signature, similar to what `//go:wasmimport` does for WebAssembly.
+func Import(d *ast.FuncDecl) (string, bool) {
+	return directiveArgument(d, `import`)
+}
+
 // directiveMatcher is a regex which matches a GopherJS directive
 // and finds the directive action.
 var directiveMatcher = regexp.MustCompile(`^\/(?:\/|\*)gopherjs:([\w-]+)`)
@@ -191,13 +208,27 @@ var directiveMatcher = regexp.MustCompile(`^\/(?:\/|\*)gopherjs:([\w-]+)`)
 //
 // see https://pkg.go.dev/cmd/compile#hdr-Compiler_Directives
 func hasDirective(node ast.Node, directiveAction string) bool {
-	foundDirective := false
+	_, found := directiveArgument(node, directiveAction)
+	return found
+}
+
+// directiveArgument is like hasDirective, but also returns the text following
+// the directive action on the same line, without surrounding whitespace.
+func directiveArgument(node ast.Node, directiveAction string) (string, bool) {
+	argument, foundDirective := "", false
 	ast.Inspect(node, func(n ast.Node) bool {
 		switch a := n.(type) {
 		case *ast.Comment:
-			m := directiveMatcher.FindStringSubmatch(a.Text)
-			if len(m) == 2 && m[1] == directiveAction {
-				foundDirective = true
+			m := directiveMatcher.FindStringSubmatchIndex(a.Text)
+			if len(m) == 4 && a.Text[m[2]:m[3]] == directiveAction {
+				rest := a.Text[m[1]:]
+				if strings.HasPrefix(a.Text, `/*`) {
+					rest = strings.TrimSuffix(rest, `*/`)
+				}
+				if i := strings.IndexByte(rest, '\n'); i >= 0 {
+					rest = rest[:i]
+				}
+				argument, foundDirective = strings.TrimSpace(rest), true
 			}
 			return false
 		case *ast.CommentGroup:
@@ -206,7 +237,7 @@ func hasDirective(node ast.Node, directiveAction string) bool {
 			return n == node
 		}
 	})
-	return foundDirective
+	return argument, foundDirective
 }
 
 // HasDirectivePrefix determines if any line in the given file
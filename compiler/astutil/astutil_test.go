@@ -479,6 +479,76 @@ func TestHasDirectiveOnField(t *testing.T) {
 	}
 }
 
+func TestImport(t *testing.T) {
+	tests := []struct {
+		desc   string
+		src    string
+		want   string
+		wantOK bool
+	}{
+		{
+			desc: `no directive`,
+			src: `package testpackage;
+				// foo has no directive
+				func foo()`,
+			wantOK: false,
+		}, {
+			desc: `property path`,
+			src: `package testpackage;
+				//gopherjs:import Math.max
+				func foo(a, b float64) float64`,
+			want:   `Math.max`,
+			wantOK: true,
+		}, {
+			desc: `constructor with extra spaces`,
+			src: `package testpackage;
+				// foo creates a date.
+				//gopherjs:import   new Date
+				func foo() *js.Object`,
+			want:   `new Date`,
+			wantOK: true,
+		}, {
+			desc: `multiline comment`,
+			src: `package testpackage;
+				/*gopherjs:import console.log
+				  foo logs its arguments.
+				*/
+				func foo(args ...any)`,
+			want:   `console.log`,
+			wantOK: true,
+		}, {
+			desc: `single line block comment`,
+			src: `package testpackage;
+				/*gopherjs:import console.log*/
+				func foo(args ...any)`,
+			want:   `console.log`,
+			wantOK: true,
+		}, {
+			desc: `missing argument`,
+			src: `package testpackage;
+				//gopherjs:import
+				func foo()`,
+			want:   ``,
+			wantOK: true,
+		}, {
+			desc: `prefix directive`,
+			src: `package testpackage;
+				//gopherjs:imports Math.max
+				func foo()`,
+			wantOK: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			fdecl := srctesting.ParseFuncDecl(t, test.src)
+			if got, ok := Import(fdecl); got != test.want || ok != test.wantOK {
+				t.Errorf(`Import() returned %q, %t, want %q, %t`, got, ok, test.want, test.wantOK)
+			}
+		})
+	}
+}
+
 func TestEndsWithReturn(t *testing.T) {
 	tests := []struct {
 		desc string
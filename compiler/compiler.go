@@ -16,6 +16,7 @@ import (
 	"github.com/gopherjs/gopherjs/compiler/internal/dce"
 	"github.com/gopherjs/gopherjs/compiler/linkname"
 	"github.com/gopherjs/gopherjs/compiler/prelude"
+	"github.com/gopherjs/gopherjs/internal/experiments"
 	"github.com/gopherjs/gopherjs/internal/sourcemapx"
 )
 
@@ -165,6 +166,11 @@ func WriteProgramCode(pkgs []*Archive, w *sourcemapx.Filter, goVersion string) e
 			return err
 		}
 	}
+	if experiments.Env.MemStats {
+		if _, err := writeF(w, false, "$memStats.enabled = true;\n"); err != nil {
+			return err
+		}
+	}
 	if _, err := writeF(w, false, "\n"); err != nil {
 		return err
 	}
@@ -2,6 +2,7 @@ package compiler
 
 import (
 	"bytes"
+	"fmt"
 	"go/types"
 	"regexp"
 	"sort"
@@ -12,9 +13,11 @@ import (
 	"github.com/gopherjs/gopherjs/internal/sourcemapx"
 	"golang.org/x/tools/go/packages"
 
+	"github.com/gopherjs/gopherjs/compiler/errlist"
 	"github.com/gopherjs/gopherjs/compiler/internal/dce"
 	"github.com/gopherjs/gopherjs/compiler/linkname"
 	"github.com/gopherjs/gopherjs/compiler/sources"
+	"github.com/gopherjs/gopherjs/internal/experiments"
 	"github.com/gopherjs/gopherjs/internal/srctesting"
 )
 
@@ -582,6 +585,30 @@ func TestLengthParenthesizingIssue841(t *testing.T) {
 	}
 }
 
+func TestMemStatsExperiment(t *testing.T) {
+	src := `
+		package main
+
+		type point struct{ x, y int32 }
+
+		func main() { println(&point{}) }`
+
+	srcFiles := []srctesting.Source{{Name: `main.go`, Contents: []byte(src)}}
+	instrumented := regexp.MustCompile(`\$recordAlloc\(8\)`)
+
+	for _, enabled := range []bool{false, true} {
+		t.Run(fmt.Sprintf("enabled=%v", enabled), func(t *testing.T) {
+			defer func(old bool) { experiments.Env.MemStats = old }(experiments.Env.MemStats)
+			experiments.Env.MemStats = enabled
+
+			decl := declSelection(t, srcFiles, nil).FindDecl(`type:command-line-arguments.point`)
+			if got := instrumented.Match(decl.TypeDeclCode); got != enabled {
+				t.Errorf("Got struct constructor instrumented: %v. Want: %v. Type declaration code:\n%s", got, enabled, decl.TypeDeclCode)
+			}
+		})
+	}
+}
+
 func TestDeclNaming_Import(t *testing.T) {
 	src1 := `
 		package main
@@ -1334,3 +1361,162 @@ func checkForDeclFullNames(t *testing.T, archives map[string]*Archive, expectedF
 		}
 	}
 }
+
+func TestImportDirective(t *testing.T) {
+	src := `
+		package main
+
+		//gopherjs:import Math.hypot
+		func hypot(x, y float64) float64
+
+		//gopherjs:import console.log
+		func log(format string, args ...any)
+
+		//gopherjs:import new Date
+		func newDate(ms int) any
+
+		func main() {
+			log("%f", hypot(3, 4))
+			println(newDate(0))
+		}`
+
+	srcFiles := []srctesting.Source{{Name: `main.go`, Contents: []byte(src)}}
+	sel := declSelection(t, srcFiles, nil)
+	tests := []struct {
+		decl string
+		want string
+	}{
+		{decl: `func:command-line-arguments.hypot`, want: `return $parseFloat($global.Math.hypot(x, y));`},
+		{decl: `func:command-line-arguments.log`, want: `$global.console.log($externalize(format, $String), ...($externalize(args, sliceType) || []));`},
+		{decl: `func:command-line-arguments.newDate`, want: `return $internalize(new $global.Date(ms), $emptyInterface);`},
+	}
+	for _, test := range tests {
+		decl := sel.FindDecl(test.decl)
+		if decl.Blocking {
+			t.Errorf("Got: %s is blocking. Want: imported functions don't block.", test.decl)
+		}
+		if !strings.Contains(string(decl.FuncDeclCode), test.want) {
+			t.Errorf("Got: %s code:\n%s\nWant: it contains %s", test.decl, decl.FuncDeclCode, test.want)
+		}
+	}
+}
+
+func TestImportDirective_Errors(t *testing.T) {
+	src := `
+		package main
+
+		//gopherjs:import Math.max
+		func withBody(a, b float64) float64 { return 0 }
+
+		//gopherjs:import JSON.parse
+		func multipleResults(s string) (any, error)
+
+		//gopherjs:import alert("hi")
+		func badPath()
+
+		type point struct{}
+
+		//gopherjs:import Math.max
+		func (point) method()
+
+		func main() {
+			withBody(1, 2)
+			multipleResults("")
+			badPath()
+			point{}.method()
+		}`
+
+	root := srctesting.ParseSources(t, []srctesting.Source{{Name: `main.go`, Contents: []byte(src)}}, nil)
+	srcs := &sources.Sources{
+		ImportPath: root.PkgPath,
+		Files:      root.Syntax,
+		FileSet:    root.Fset,
+	}
+	importer := func(path, srcDir string) (*sources.Sources, error) {
+		return nil, fmt.Errorf(`unexpected import of %q`, path)
+	}
+	tContext := types.NewContext()
+	if err := PrepareAllSources([]*sources.Sources{srcs}, importer, tContext); err != nil {
+		t.Fatal(`failed to prepare sources:`, err)
+	}
+	_, err := Compile(srcs, tContext, false)
+	if err == nil {
+		t.Fatal(`Got: Compile() succeeded. Want: errors for misused //gopherjs:import directives.`)
+	}
+	want := []string{
+		`5:3: function withBody with //gopherjs:import directive must not have a body`,
+		`8:3: function multipleResults imported with //gopherjs:import can not have more than one result`,
+		`11:3: invalid //gopherjs:import path "alert(\"hi\")", must be a property path of the global object like Math.max`,
+		`16:3: //gopherjs:import directive is not supported on methods`,
+	}
+	var got []string
+	for _, e := range err.(errlist.ErrorList) {
+		e := e.(types.Error)
+		pos := e.Fset.Position(e.Pos)
+		got = append(got, fmt.Sprintf(`%d:%d: %s`, pos.Line, pos.Column, e.Msg))
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Compile() returned different errors (-want,+got):\n%s", diff)
+	}
+}
+
+func TestJsTagWithoutJsObject(t *testing.T) {
+	src := `
+		package main
+
+		import "github.com/gopherjs/gopherjs/js"
+
+		type misplaced struct {
+			name string
+			*js.Object
+			Value int ` + "`js:\"value\"`" + `
+		}
+
+		type plain struct {
+			Value int ` + "`js:\"value\"`" + `
+		}
+
+		func main() {
+			m := &misplaced{}
+			println(m.Value)
+			p := plain{}
+			println(p.Value)
+		}`
+
+	root := srctesting.ParseSources(t, []srctesting.Source{{Name: `main.go`, Contents: []byte(src)}}, nil)
+	allSrcs := map[string]*sources.Sources{}
+	packages.Visit([]*packages.Package{root}, nil, func(pkg *packages.Package) {
+		allSrcs[pkg.PkgPath] = &sources.Sources{
+			ImportPath: pkg.PkgPath,
+			Files:      pkg.Syntax,
+			FileSet:    pkg.Fset,
+		}
+	})
+	importer := func(path, srcDir string) (*sources.Sources, error) {
+		if srcs, ok := allSrcs[path]; ok {
+			return srcs, nil
+		}
+		return nil, fmt.Errorf(`unexpected import of %q`, path)
+	}
+	tContext := types.NewContext()
+	srcs := allSrcs[root.PkgPath]
+	if err := PrepareAllSources([]*sources.Sources{srcs}, importer, tContext); err != nil {
+		t.Fatal(`failed to prepare sources:`, err)
+	}
+	_, err := Compile(srcs, tContext, false)
+	if err == nil {
+		t.Fatal(`Got: Compile() succeeded. Want: an error for the misplaced *js.Object field.`)
+	}
+	want := []string{
+		`18:12: could not find field with type *js.Object for 'js' tag of field 'Value'`,
+	}
+	var got []string
+	for _, e := range err.(errlist.ErrorList) {
+		e := e.(types.Error)
+		pos := e.Fset.Position(e.Pos)
+		got = append(got, fmt.Sprintf(`%d:%d: %s`, pos.Line, pos.Column, e.Msg))
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Compile() returned different errors (-want,+got):\n%s", diff)
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/gopherjs/gopherjs/compiler/internal/typeparams"
 	"github.com/gopherjs/gopherjs/compiler/sources"
 	"github.com/gopherjs/gopherjs/compiler/typesutil"
+	"github.com/gopherjs/gopherjs/internal/experiments"
 )
 
 // Decl represents a package-level symbol (e.g. a function, variable or type).
@@ -577,6 +578,9 @@ func (fc *funcContext) structConstructor(t *types.Struct) string {
 
 	fmt.Fprintf(constructor, "function(%s) {\n", strings.Join(ctrArgs, ", "))
 	fmt.Fprintf(constructor, "\t\tthis.$val = this;\n")
+	if experiments.Env.MemStats {
+		fmt.Fprintf(constructor, "\t\t$recordAlloc(%d);\n", sizes32.Sizeof(t))
+	}
 
 	// If no arguments were passed, zero-initialize all fields.
 	fmt.Fprintf(constructor, "\t\tif (arguments.length === 0) {\n")
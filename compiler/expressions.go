@@ -648,6 +648,12 @@ func (fc *funcContext) translateExpr(expr ast.Expr) *expression {
 						return fc.formatExpr("debugger")
 					case "InternalObject":
 						return fc.translateExpr(e.Args[0])
+					case "Async":
+						// Externalize functions directly instead of boxing them in an interface.
+						t := fc.typeOf(e.Args[0])
+						if _, ok := t.Underlying().(*types.Signature); ok {
+							return fc.formatExpr("$externalizeFunction(%e, %s, false, undefined, true)", e.Args[0], fc.typeName(t))
+						}
 					}
 				}
 				return fc.translateCall(e, sig, fc.translateExpr(f))
@@ -1390,6 +1396,8 @@ func (fc *funcContext) internalize(s *expression, t types.Type) *expression {
 	switch u := t.Underlying().(type) {
 	case *types.Basic:
 		switch {
+		case typesutil.IsJsUnmarshaler(t):
+			// Converted by the InternalizeJS method in $internalize.
 		case isBoolean(u):
 			return fc.formatExpr("!!(%s)", s)
 		case isInteger(u) && !is64Bit(u):
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"go/ast"
 	"go/types"
+	"regexp"
 	"sort"
 	"strings"
 
@@ -118,11 +119,15 @@ func (fc *funcContext) translateStandaloneFunction(fun *ast.FuncDecl) []byte {
 
 	lvalue := fc.instName(fc.instance)
 
-	if fun.Body == nil {
+	var body string
+	switch path, isImport := astutil.Import(fun); {
+	case isImport:
+		body = fc.importedFunction(fun, path)
+	case fun.Body == nil:
 		return []byte(fmt.Sprintf("\t\t%s = %s;\n", lvalue, fc.unimplementedFunction(o)))
+	default:
+		body = fc.translateFunctionBody(fun.Type, nil, fun.Body)
 	}
-
-	body := fc.translateFunctionBody(fun.Type, nil, fun.Body)
 	code := bytes.NewBuffer(nil)
 	fmt.Fprintf(code, "\t\t%s = %s;\n", lvalue, body)
 	if fun.Name.IsExported() && fc.instance.IsTrivial() {
@@ -139,6 +144,10 @@ func (fc *funcContext) translateMethod(fun *ast.FuncDecl) []byte {
 	o := fc.instance.Object.(*types.Func)
 	funName := fc.methodName(o)
 
+	if _, isImport := astutil.Import(fun); isImport {
+		fc.pkgCtx.errList = append(fc.pkgCtx.errList, types.Error{Fset: fc.pkgCtx.fileSet, Pos: fun.Pos(), Msg: "//gopherjs:import directive is not supported on methods"})
+	}
+
 	// primaryFunction generates a JS function equivalent of the current Go function
 	// and assigns it to the JS expression defined by lvalue.
 	primaryFunction := func(lvalue string) []byte {
@@ -210,6 +219,65 @@ func (fc *funcContext) unimplementedFunction(o *types.Func) string {
 	return fmt.Sprintf("function() {\n\t\t$throwRuntimeError(\"native function not implemented: %s\");\n\t}", o.FullName())
 }
 
+// importPathMatcher matches the property paths of JavaScript functions that
+// can be bound with a //gopherjs:import directive, like "Math.max".
+var importPathMatcher = regexp.MustCompile(`^[\pL_$][\pL\pN_$]*(?:\.[\pL_$][\pL\pN_$]*)*$`)
+
+// importedFunction returns a JS function expression for a Go function bound to
+// a JavaScript function by a //gopherjs:import directive with the given path.
+//
+// Unlike calls through *js.Object, the function converts arguments and the
+// result according to the static types of the Go signature, so that numbers
+// and JavaScript objects are passed through without $externalize() and
+// $internalize() calls.
+func (fc *funcContext) importedFunction(fun *ast.FuncDecl, path string) string {
+	o := fc.instance.Object.(*types.Func)
+	sig := fc.sig.Sig
+	errorf := func(format string, args ...any) string {
+		fc.pkgCtx.errList = append(fc.pkgCtx.errList, types.Error{Fset: fc.pkgCtx.fileSet, Pos: fun.Pos(), Msg: fmt.Sprintf(format, args...)})
+		return fc.unimplementedFunction(o)
+	}
+
+	callee := "$global." + path
+	if rest, ok := strings.CutPrefix(path, "new "); ok {
+		path = strings.TrimSpace(rest)
+		callee = "new $global." + path
+	}
+	switch {
+	case fun.Body != nil:
+		return errorf("function %s with //gopherjs:import directive must not have a body", o.Name())
+	case sig.Results().Len() > 1:
+		return errorf("function %s imported with //gopherjs:import can not have more than one result", o.Name())
+	case !importPathMatcher.MatchString(path):
+		return errorf("invalid //gopherjs:import path %q, must be a property path of the global object like Math.max", path)
+	}
+
+	var params, args []string
+	for i := 0; i < sig.Params().Len(); i++ {
+		p := sig.Params().At(i)
+		name := p.Name()
+		if name == "" || name == "_" {
+			name = "param"
+		}
+		name = fc.newLocalVariable(name)
+		params = append(params, name)
+		arg := fc.externalize(name, p.Type())
+		if sig.Variadic() && i == sig.Params().Len()-1 {
+			// Variadic arguments are spread, so that JavaScript receives them as
+			// separate arguments as well. Nil slices are externalized as null.
+			arg = fmt.Sprintf("...(%s || [])", arg)
+		}
+		args = append(args, arg)
+	}
+	call := fmt.Sprintf("%s(%s)", callee, strings.Join(args, ", "))
+
+	stmt := call
+	if sig.Results().Len() == 1 {
+		stmt = "return " + fc.internalize(fc.formatExpr("%s", call), sig.Results().At(0).Type()).String()
+	}
+	return fmt.Sprintf("%sfunction %s(%s) {\n%s%s;\n%s}", fc.funcRef.EncodeHint(), fc.funcRef, strings.Join(params, ", "), fc.Indentation(2), stmt, fc.Indentation(1))
+}
+
 // translateFunctionBody translates body of a top-level or literal function.
 //
 // It returns a JS function expression that represents the given Go function.
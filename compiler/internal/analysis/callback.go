@@ -0,0 +1,374 @@
+package analysis
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"github.com/gopherjs/gopherjs/compiler/astutil"
+	"github.com/gopherjs/gopherjs/compiler/internal/typeparams"
+	"github.com/gopherjs/gopherjs/compiler/typesutil"
+)
+
+// blockingCause describes why a function may block.
+type blockingCause struct {
+	// node is the blocking operation, call or statement.
+	node ast.Node
+	// callee is the blocking named function called by node, if any.
+	callee *typeparams.Instance
+	// lit is the blocking function literal called by node, if any.
+	lit *ast.FuncLit
+}
+
+func (c *blockingCause) String() string {
+	switch n := c.node.(type) {
+	case *ast.CallExpr:
+		switch {
+		case c.callee != nil:
+			return "calls " + c.callee.String()
+		case c.lit != nil:
+			return "calls a function literal"
+		}
+		return "calls " + types.ExprString(n.Fun)
+	case *ast.SendStmt:
+		return "sends to a channel"
+	case *ast.UnaryExpr:
+		return "receives from a channel"
+	case *ast.RangeStmt:
+		return "ranges over a channel"
+	case *ast.SelectStmt:
+		return "selects without a default case"
+	}
+	return "may block"
+}
+
+// isOperation returns true if the cause is a channel operation or a select
+// statement, rather than a call that blocks or is assumed to.
+func (c *blockingCause) isOperation() bool {
+	switch c.node.(type) {
+	case *ast.SendStmt, *ast.UnaryExpr, *ast.RangeStmt, *ast.SelectStmt:
+		return true
+	}
+	return false
+}
+
+// callback is a function value passed to JavaScript, which calls it outside
+// of any goroutine.
+type callback struct {
+	pos token.Pos
+	// inst is the named function or method of a function value.
+	inst *typeparams.Instance
+	// lit is the function literal of a function value, it's analyzed with the
+	// type arguments of the function it's declared in.
+	lit      *ast.FuncLit
+	typeArgs typesutil.TypeList
+	// via is the package-level function the value is passed to, if it's only
+	// passed to JavaScript when via is bound by a gopherjs:import directive.
+	via *types.Func
+}
+
+// BlockingCallback is a function value passed to JavaScript that may block.
+// Since JavaScript doesn't call it in a goroutine, blocking would fail at
+// runtime.
+type BlockingCallback struct {
+	// Pos is where the function value is passed to JavaScript.
+	Pos token.Position
+	// Chain lists the calls from the function to the operation that blocks,
+	// which is the last step.
+	Chain []BlockingStep
+}
+
+// BlockingStep is a call or an operation in the Chain of a BlockingCallback.
+type BlockingStep struct {
+	Pos  token.Position
+	Desc string
+}
+
+func (bc BlockingCallback) Error() string {
+	buf := &strings.Builder{}
+	fmt.Fprintf(buf, "%s: function passed to JavaScript may block outside of a goroutine:", bc.Pos)
+	for _, step := range bc.Chain {
+		fmt.Fprintf(buf, "\n\t%s: %s", step.Pos, step.Desc)
+	}
+	return buf.String()
+}
+
+// BlockingCallbacks returns the function values the package passes to
+// JavaScript that may block. It must be called after PropagateAnalysis.
+//
+// Only functions that lead to a channel operation or a select statement are
+// returned. Functions that are merely assumed to block, like calls of
+// interface methods, and sends to local buffered channels are usually fine in
+// practice.
+func (info *Info) BlockingCallbacks() []BlockingCallback {
+	var result []BlockingCallback
+	seen := map[token.Pos]bool{}
+	for _, cb := range info.callbacks {
+		if seen[cb.pos] {
+			// Same function literal in another instance of a generic function.
+			continue
+		}
+		if cb.via != nil && !info.isImport(cb.via) {
+			continue
+		}
+		var fi *FuncInfo
+		if cb.lit != nil {
+			fi = info.FuncLitInfo(cb.lit, cb.typeArgs)
+		} else {
+			fi = info.funcInfoOf(*cb.inst)
+		}
+		if fi == nil || !fi.IsBlocking() {
+			continue
+		}
+		chain, ok := fi.blockingChain()
+		if !ok {
+			continue
+		}
+		seen[cb.pos] = true
+		result = append(result, BlockingCallback{
+			Pos:   info.fileSet.Position(cb.pos),
+			Chain: chain,
+		})
+	}
+	return result
+}
+
+// funcInfoOf returns information about the given function instance, which
+// may be declared in another package, or nil if not found.
+func (info *Info) funcInfoOf(inst typeparams.Instance) *FuncInfo {
+	if pkg := inst.Object.Pkg(); pkg != nil && pkg != info.Pkg {
+		otherInfo, err := info.infoImporter(pkg.Path())
+		if err != nil {
+			return nil
+		}
+		return otherInfo.FuncInfo(inst)
+	}
+	return info.FuncInfo(inst)
+}
+
+// blockingChain follows the causes of blocking from the function to the
+// first operation that blocks. It returns false if no chain ends in a
+// blocking operation.
+func (fi *FuncInfo) blockingChain() ([]BlockingStep, bool) {
+	return fi.blockingChainFrom(map[*FuncInfo]bool{})
+}
+
+func (fi *FuncInfo) blockingChainFrom(visited map[*FuncInfo]bool) ([]BlockingStep, bool) {
+	if fi == nil || visited[fi] {
+		return nil, false
+	}
+	visited[fi] = true
+	for _, cause := range fi.causes {
+		step := BlockingStep{
+			Pos:  fi.pkgInfo.fileSet.Position(cause.node.Pos()),
+			Desc: cause.String(),
+		}
+		if cause.isOperation() {
+			if fi.pkgInfo.isBufferedSend(cause.node) {
+				// Sends to buffered channels rarely block in practice.
+				continue
+			}
+			return []BlockingStep{step}, true
+		}
+		var callee *FuncInfo
+		switch {
+		case cause.callee != nil:
+			callee = fi.pkgInfo.funcInfoOf(*cause.callee)
+		case cause.lit != nil:
+			callee = fi.pkgInfo.FuncLitInfo(cause.lit, fi.typeArgs)
+		}
+		if chain, ok := callee.blockingChainFrom(visited); ok {
+			return append([]BlockingStep{step}, chain...), true
+		}
+	}
+	return nil, false
+}
+
+// recordChanAssigns records the assignments of the values rhs to lhs, or of
+// unknown values if rhs doesn't match lhs, in bufferedChans. Channel variables
+// are buffered if they're local, declared with a make() of a constant positive
+// capacity and never assigned anything else.
+func (info *Info) recordChanAssigns(lhs, rhs []ast.Expr) {
+	for i, expr := range lhs {
+		id, ok := astutil.RemoveParens(expr).(*ast.Ident)
+		if !ok {
+			continue
+		}
+		v, ok := info.ObjectOf(id).(*types.Var)
+		if !ok || v.Parent() == info.Pkg.Scope() {
+			continue
+		}
+		if _, ok := v.Type().Underlying().(*types.Chan); !ok {
+			continue
+		}
+		_, assigned := info.bufferedChans[v]
+		info.bufferedChans[v] = !assigned && info.Defs[id] != nil && len(lhs) == len(rhs) && info.isBufferedMake(rhs[i])
+	}
+}
+
+// isBufferedMake returns true if expr makes a channel with a constant positive
+// capacity.
+func (info *Info) isBufferedMake(expr ast.Expr) bool {
+	call, ok := astutil.RemoveParens(expr).(*ast.CallExpr)
+	if !ok || len(call.Args) != 2 {
+		return false
+	}
+	id, ok := astutil.RemoveParens(call.Fun).(*ast.Ident)
+	if !ok {
+		return false
+	}
+	if b, ok := info.Uses[id].(*types.Builtin); !ok || b.Name() != "make" {
+		return false
+	}
+	capacity := info.Types[call.Args[1]].Value
+	return capacity != nil && constant.Sign(capacity) > 0
+}
+
+// isBufferedSend returns true if node sends to a channel variable known to be
+// buffered.
+func (info *Info) isBufferedSend(node ast.Node) bool {
+	send, ok := node.(*ast.SendStmt)
+	if !ok {
+		return false
+	}
+	id, ok := astutil.RemoveParens(send.Chan).(*ast.Ident)
+	if !ok {
+		return false
+	}
+	v, ok := info.Uses[id].(*types.Var)
+	return ok && info.bufferedChans[v] && !info.HasPointer[v]
+}
+
+// visitCallbacks records the function values passed to JavaScript by call to
+// be called synchronously: arguments of js.Object methods, js.MakeFunc,
+// syscall/js.FuncOf and functions bound by a gopherjs:import directive.
+func (fi *FuncInfo) visitCallbacks(call *ast.CallExpr) {
+	var via *types.Func
+	if !fi.pkgInfo.passesCallbacks(call) {
+		// Whether a function in another package is imported is only known
+		// once it's analyzed, so the arguments of all calls of package-level
+		// functions are checked in BlockingCallbacks.
+		if via = fi.pkgInfo.calledFunc(call); via == nil {
+			return
+		}
+	}
+	for _, arg := range call.Args {
+		if cb, ok := fi.callbackOf(arg); ok {
+			cb.via = via
+			fi.pkgInfo.callbacks = append(fi.pkgInfo.callbacks, cb)
+		}
+	}
+}
+
+// calledFunc returns the package-level function called by call, or nil if it
+// calls something else, like a method or a function value.
+func (info *Info) calledFunc(call *ast.CallExpr) *types.Func {
+	var id *ast.Ident
+	switch f := astutil.RemoveParens(call.Fun).(type) {
+	case *ast.Ident:
+		id = f
+	case *ast.SelectorExpr:
+		if info.Selections[f] != nil {
+			return nil
+		}
+		id = f.Sel
+	default:
+		return nil
+	}
+	fn, ok := info.Uses[id].(*types.Func)
+	if !ok || fn.Pkg() == nil || typesutil.IsMethod(fn) {
+		return nil
+	}
+	return fn
+}
+
+// isImport returns true if the package-level function fn, which may be
+// declared in another package, is bound by a gopherjs:import directive.
+func (info *Info) isImport(fn *types.Func) bool {
+	if pkg := fn.Pkg(); pkg != info.Pkg {
+		otherInfo, err := info.infoImporter(pkg.Path())
+		if err != nil {
+			return false
+		}
+		return otherInfo.imports[fn]
+	}
+	return info.imports[fn]
+}
+
+// passesCallbacks returns true if function arguments of call are converted
+// to JavaScript functions which are called outside of a goroutine.
+func (info *Info) passesCallbacks(call *ast.CallExpr) bool {
+	var id *ast.Ident
+	switch f := astutil.RemoveParens(call.Fun).(type) {
+	case *ast.Ident:
+		id = f
+	case *ast.SelectorExpr:
+		if sel := info.Selections[f]; sel != nil {
+			if !typesutil.IsJsObject(sel.Recv()) {
+				return false
+			}
+			switch f.Sel.Name {
+			case "Call", "Invoke", "New", "Set", "SetIndex":
+				return true
+			}
+			return false
+		}
+		id = f.Sel
+	default:
+		return false
+	}
+	obj, ok := info.Uses[id].(*types.Func)
+	if !ok || obj.Pkg() == nil {
+		return false
+	}
+	switch {
+	case typesutil.IsJsPackage(obj.Pkg()):
+		return obj.Name() == "MakeFunc"
+	case obj.Pkg().Path() == "syscall/js":
+		return obj.Name() == "FuncOf"
+	}
+	return false
+}
+
+// callbackOf returns the callback for a function literal, or a named function
+// or method value passed as arg. Other values, like function variables, are
+// unknown to the analysis.
+func (fi *FuncInfo) callbackOf(arg ast.Expr) (callback, bool) {
+	if _, ok := fi.pkgInfo.TypeOf(arg).Underlying().(*types.Signature); !ok {
+		return callback{}, false
+	}
+	cb := callback{pos: arg.Pos()}
+	switch a := astutil.RemoveParens(arg).(type) {
+	case *ast.FuncLit:
+		cb.lit, cb.typeArgs = a, fi.typeArgs
+		return cb, true
+	case *ast.Ident:
+		if _, ok := fi.pkgInfo.Uses[a].(*types.Func); ok {
+			inst := fi.instanceForIdent(a)
+			cb.inst = &inst
+			return cb, true
+		}
+	case *ast.SelectorExpr:
+		sel := fi.pkgInfo.Selections[a]
+		if sel == nil {
+			// Qualified identifier like `pkg.Foo`.
+			if _, ok := fi.pkgInfo.Uses[a.Sel].(*types.Func); ok {
+				inst := fi.instanceForIdent(a.Sel)
+				cb.inst = &inst
+				return cb, true
+			}
+			return callback{}, false
+		}
+		if sel.Kind() == types.FieldVal || types.IsInterface(sel.Recv()) {
+			// A function in a field, or an implementation of an interface method.
+			return callback{}, false
+		}
+		inst := fi.instanceForSelection(sel)
+		cb.inst = &inst
+		return cb, true
+	}
+	return callback{}, false
+}
@@ -0,0 +1,134 @@
+package analysis
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/gopherjs/gopherjs/compiler/internal/typeparams"
+	"github.com/gopherjs/gopherjs/internal/srctesting"
+)
+
+func TestBlockingCallbacks(t *testing.T) {
+	const jsSrc = `package js
+
+		type Object struct{ object *Object }
+
+		func (o *Object) Call(name string, args ...any) *Object { return nil }
+		func (o *Object) Get(key string) *Object { return nil }
+
+		var Global *Object
+
+		func MakeFunc(fn func(this *Object, arguments []*Object) any) *Object { return nil }
+		func Async(fn any) *Object { return nil }
+
+		//gopherjs:import queueMicrotask
+		func QueueMicrotask(fn func())`
+
+	const testSrc = `package test
+
+		import "github.com/gopherjs/gopherjs/js"
+
+		func wait(c chan bool) { <-c }
+
+		func notBlocking() { println("hi") }
+
+		type handler struct{ c chan bool }
+
+		func (h handler) handle() { wait(h.c) }
+
+		type waiter interface{ wait() }
+
+		func callbacks(c chan bool, w waiter, f func()) {
+			js.Global.Call("setTimeout", func() { wait(c) })
+			js.Global.Call("setTimeout", handler{c}.handle)
+			js.MakeFunc(func(this *js.Object, arguments []*js.Object) any {
+				c <- true
+				return nil
+			})
+
+			js.Global.Call("setTimeout", notBlocking)
+			js.Global.Call("setTimeout", func() { go wait(c) })
+			js.Global.Call("setTimeout", func() { w.wait() })
+			js.Global.Call("setTimeout", f)
+			js.Global.Get("onclick")
+			js.Async(func() { wait(c) })
+
+			setTimeout(func() { wait(c) }, 0)
+			js.QueueMicrotask(func() { c <- true })
+			setTimeout(notBlocking, 0)
+			run(func() { wait(c) })
+
+			buffered := make(chan bool, 1)
+			js.Global.Call("setTimeout", func() { buffered <- true })
+			reassigned := make(chan bool, 1)
+			reassigned = c
+			js.Global.Call("setTimeout", func() { reassigned <- true })
+			js.Global.Call("setTimeout", func() {
+				buffered <- true
+				<-c
+			})
+		}
+
+		//gopherjs:import setTimeout
+		func setTimeout(fn func(), ms int)
+
+		func run(fn func()) { fn() }`
+
+	f := srctesting.New(t)
+	tContext := types.NewContext()
+	tc := typeparams.Collector{
+		TContext:  tContext,
+		Instances: &typeparams.PackageInstanceSets{},
+	}
+
+	infos := map[string]*Info{}
+	getImportInfo := func(path string) (*Info, error) {
+		if info, ok := infos[path]; ok {
+			return info, nil
+		}
+		return nil, fmt.Errorf(`unexpected package in getImportInfo for %v`, path)
+	}
+
+	jsFile := f.Parse(`js.go`, jsSrc)
+	_, jsPkg := f.Check(`github.com/gopherjs/gopherjs/js`, jsFile)
+	tc.Scan(f.Info, jsPkg, jsFile)
+
+	testFile := f.Parse(`test.go`, testSrc)
+	_, testPkg := f.Check(`pkg/test`, testFile)
+	tc.Scan(f.Info, testPkg, testFile)
+	tc.Finish()
+
+	infos[jsPkg.Path()] = AnalyzePkg([]*ast.File{jsFile}, f.FileSet, f.Info, tContext, jsPkg, tc.Instances, getImportInfo)
+	infos[testPkg.Path()] = AnalyzePkg([]*ast.File{testFile}, f.FileSet, f.Info, tContext, testPkg, tc.Instances, getImportInfo)
+	PropagateAnalysis([]*Info{infos[jsPkg.Path()], infos[testPkg.Path()]})
+
+	var got []string
+	for _, cb := range infos[testPkg.Path()].BlockingCallbacks() {
+		got = append(got, cb.Error())
+	}
+	want := []string{
+		"test.go:16:33: function passed to JavaScript may block outside of a goroutine:\n" +
+			"\ttest.go:16:42: calls pkg/test.wait\n" +
+			"\ttest.go:5:28: receives from a channel",
+		"test.go:17:33: function passed to JavaScript may block outside of a goroutine:\n" +
+			"\ttest.go:11:31: calls pkg/test.wait\n" +
+			"\ttest.go:5:28: receives from a channel",
+		"test.go:18:16: function passed to JavaScript may block outside of a goroutine:\n" +
+			"\ttest.go:19:5: sends to a channel",
+		"test.go:30:15: function passed to JavaScript may block outside of a goroutine:\n" +
+			"\ttest.go:30:24: calls pkg/test.wait\n" +
+			"\ttest.go:5:28: receives from a channel",
+		"test.go:31:22: function passed to JavaScript may block outside of a goroutine:\n" +
+			"\ttest.go:31:31: sends to a channel",
+		"test.go:39:33: function passed to JavaScript may block outside of a goroutine:\n" +
+			"\ttest.go:39:42: sends to a channel",
+		"test.go:40:33: function passed to JavaScript may block outside of a goroutine:\n" +
+			"\ttest.go:42:5: receives from a channel",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Got blocking callbacks:\n%s\nWant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -60,6 +60,12 @@ type Info struct {
 
 	infoImporter InfoImporter // To get `Info` for other packages.
 	allInfos     []*FuncInfo
+	fileSet      *token.FileSet
+	callbacks    []callback           // Function values passed to JavaScript.
+	imports      map[*types.Func]bool // Functions bound by a gopherjs:import directive.
+	// bufferedChans maps local channel variables to whether they're only ever
+	// assigned a buffered channel, see recordChanAssigns.
+	bufferedChans map[*types.Var]bool
 }
 
 // InfoImporter is used to get the `Info` for another package.
@@ -82,14 +88,18 @@ func (info *Info) newFuncInfo(n ast.Node, obj types.Object, typeArgs typesutil.T
 	// Register the function in the appropriate map.
 	switch n := n.(type) {
 	case *ast.FuncDecl:
-		if n.Body == nil {
+		if _, isImport := astutil.Import(n); n.Body == nil && !isImport {
 			// Function body comes from elsewhere (for example, from a go:linkname
 			// directive), conservatively assume that it may be blocking.
 			// TODO(nevkontakte): It is possible to improve accuracy of this detection.
 			// Since GopherJS supports only "import-style" go:linkname, at this stage
 			// the compiler already determined whether the implementation function is
 			// blocking, and we could check that.
+			//
+			// Functions bound by a gopherjs:import directive call JavaScript
+			// synchronously, like methods of *js.Object, so they don't block.
 			funcInfo.Blocking[n] = true
+			funcInfo.causes = append(funcInfo.causes, &blockingCause{node: n})
 		}
 
 		if obj == nil {
@@ -194,9 +204,28 @@ func AnalyzePkg(files []*ast.File, fileSet *token.FileSet, typesInfo *types.Info
 		infoImporter:  infoImporter,
 		funcInstInfos: new(typeparams.InstanceMap[*FuncInfo]),
 		funcLitInfos:  make(map[*ast.FuncLit][]*FuncInfo),
+		fileSet:       fileSet,
+		imports:       make(map[*types.Func]bool),
+		bufferedChans: make(map[*types.Var]bool),
 	}
 	info.InitFuncInfo = info.newFuncInfo(nil, nil, nil, nil)
 
+	// Collect the functions bound to JavaScript, callbacks passed to them are
+	// checked by BlockingCallbacks.
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || fd.Recv != nil {
+				continue
+			}
+			if _, isImport := astutil.Import(fd); isImport {
+				if fn, ok := info.Defs[fd.Name].(*types.Func); ok {
+					info.imports[fn] = true
+				}
+			}
+		}
+	}
+
 	// Traverse the full AST of the package and collect information about existing
 	// functions.
 	for _, file := range files {
@@ -238,7 +267,7 @@ func (info *Info) propagateFunctionBlocking() bool {
 		caller.instCallees.Iterate(func(callee typeparams.Instance, callSites []astPath) {
 			if info.IsBlocking(callee) {
 				for _, callSite := range callSites {
-					caller.markBlocking(callSite)
+					caller.markBlockingCause(callSite, &blockingCause{callee: &callee})
 				}
 				caller.instCallees.Delete(callee)
 				done = false
@@ -248,7 +277,7 @@ func (info *Info) propagateFunctionBlocking() bool {
 		// Check direct calls to function literals.
 		for callee, callSite := range caller.literalFuncCallees {
 			if info.FuncLitInfo(callee, caller.typeArgs).IsBlocking() {
-				caller.markBlocking(callSite)
+				caller.markBlockingCause(callSite, &blockingCause{lit: callee})
 				delete(caller.literalFuncCallees, callee)
 				done = false
 			}
@@ -312,6 +341,10 @@ type FuncInfo struct {
 	// This may be nil if not an instance of a generic function.
 	resolver *typeparams.Resolver
 
+	// causes are the reasons the function was found to be blocking, in the
+	// order they were found.
+	causes []*blockingCause
+
 	pkgInfo      *Info // Function's parent package.
 	visitorStack astPath
 }
@@ -414,11 +447,22 @@ func (fi *FuncInfo) Visit(node ast.Node) ast.Visitor {
 		}
 		return fi
 	case *ast.CallExpr:
+		fi.visitCallbacks(n)
 		return fi.visitCallExpr(n, false)
 	case *ast.SendStmt:
 		// Sending into a channel is blocking.
 		fi.markBlocking(fi.visitorStack)
 		return fi
+	case *ast.AssignStmt:
+		fi.pkgInfo.recordChanAssigns(n.Lhs, n.Rhs)
+		return fi
+	case *ast.ValueSpec:
+		lhs := make([]ast.Expr, len(n.Names))
+		for i, name := range n.Names {
+			lhs[i] = name
+		}
+		fi.pkgInfo.recordChanAssigns(lhs, n.Values)
+		return fi
 	case *ast.UnaryExpr:
 		switch n.Op {
 		case token.AND:
@@ -431,6 +475,7 @@ func (fi *FuncInfo) Visit(node ast.Node) ast.Visitor {
 		}
 		return fi
 	case *ast.RangeStmt:
+		fi.pkgInfo.recordChanAssigns([]ast.Expr{n.Key, n.Value}, nil)
 		if _, ok := fi.pkgInfo.TypeOf(n.X).Underlying().(*types.Chan); ok {
 			// for-range loop over a channel is blocking.
 			fi.markBlocking(fi.visitorStack)
@@ -491,6 +536,7 @@ func (fi *FuncInfo) Visit(node ast.Node) ast.Visitor {
 		case *ast.ExprStmt:
 			ast.Walk(fi, comm.X.(*ast.UnaryExpr).X)
 		case *ast.AssignStmt:
+			fi.pkgInfo.recordChanAssigns(comm.Lhs, nil)
 			ast.Walk(fi, comm.Rhs[0].(*ast.UnaryExpr).X)
 		}
 		for _, s := range n.Body {
@@ -714,6 +760,17 @@ func (fi *FuncInfo) callToNamedFunc(callee typeparams.Instance, deferredCall boo
 }
 
 func (fi *FuncInfo) markBlocking(stack astPath) {
+	fi.markBlockingCause(stack, &blockingCause{})
+}
+
+// markBlockingCause marks the stack as blocking like markBlocking, recording
+// cause as a reason the function blocks. The blocking node of cause is set to
+// the last node in the stack.
+func (fi *FuncInfo) markBlockingCause(stack astPath, cause *blockingCause) {
+	if len(stack) > 0 {
+		cause.node = stack[len(stack)-1]
+		fi.causes = append(fi.causes, cause)
+	}
 	for _, n := range stack {
 		fi.Blocking[n] = true
 		fi.Flattened[n] = true
@@ -0,0 +1,239 @@
+//go:build js
+
+package aes
+
+import (
+	"crypto/cipher"
+	"crypto/internal/alias"
+	"crypto/internal/boring"
+	"errors"
+	"strconv"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// newCipher returns a cipher, which implements the CBC, CTR and GCM modes
+// with Node.js crypto if it is available. Single blocks are still encrypted
+// by the Go implementation, a call into Node.js would cost more than that.
+func newCipher(key []byte) (cipher.Block, error) {
+	b, err := newCipherGeneric(key)
+	if err != nil || boring.NodeCrypto() == nil {
+		return b, err
+	}
+	c := &aesCipherNode{
+		aesCipher: *b.(*aesCipher),
+		key:       append([]byte(nil), key...),
+		name:      "aes-" + strconv.Itoa(len(key)*8),
+	}
+	return c, nil
+}
+
+type aesCipherNode struct {
+	aesCipher
+	key  []byte
+	name string // algorithm name prefix, e.g. "aes-128"
+}
+
+// copyNode copies the contents of a Node.js Buffer to dst.
+func copyNode(dst []byte, buf *js.Object) {
+	js.InternalObject(dst).Get("$array").Call("set", buf, js.InternalObject(dst).Get("$offset"))
+}
+
+func (c *aesCipherNode) NewCBCEncrypter(iv []byte) cipher.BlockMode {
+	return newNodeBlockMode(c, "createCipheriv", iv)
+}
+
+func (c *aesCipherNode) NewCBCDecrypter(iv []byte) cipher.BlockMode {
+	return newNodeBlockMode(c, "createDecipheriv", iv)
+}
+
+// nodeBlockMode is a CBC encrypter or decrypter. Node.js keeps the chaining
+// state between calls to CryptBlocks.
+type nodeBlockMode struct {
+	c      *aesCipherNode
+	create string
+	cipher *js.Object
+}
+
+func newNodeBlockMode(c *aesCipherNode, create string, iv []byte) *nodeBlockMode {
+	x := &nodeBlockMode{c: c, create: create}
+	x.SetIV(iv)
+	return x
+}
+
+func (x *nodeBlockMode) BlockSize() int { return BlockSize }
+
+func (x *nodeBlockMode) CryptBlocks(dst, src []byte) {
+	if len(src)%BlockSize != 0 {
+		panic("crypto/cipher: input not full blocks")
+	}
+	if len(dst) < len(src) {
+		panic("crypto/cipher: output smaller than input")
+	}
+	if alias.InexactOverlap(dst[:len(src)], src) {
+		panic("crypto/cipher: invalid buffer overlap")
+	}
+	if len(src) == 0 {
+		return
+	}
+	copyNode(dst, x.cipher.Call("update", src))
+}
+
+func (x *nodeBlockMode) SetIV(iv []byte) {
+	if len(iv) != BlockSize {
+		panic("cipher: incorrect length IV")
+	}
+	x.cipher = boring.NodeCrypto().Call(x.create, x.c.name+"-cbc", x.c.key, iv)
+	x.cipher.Call("setAutoPadding", false)
+}
+
+func (c *aesCipherNode) NewCTR(iv []byte) cipher.Stream {
+	if len(iv) != BlockSize {
+		panic("cipher.NewCTR: IV length must equal block size")
+	}
+	return &nodeCTR{boring.NodeCrypto().Call("createCipheriv", c.name+"-ctr", c.key, iv)}
+}
+
+type nodeCTR struct {
+	cipher *js.Object
+}
+
+func (x *nodeCTR) XORKeyStream(dst, src []byte) {
+	if len(dst) < len(src) {
+		panic("crypto/cipher: output smaller than input")
+	}
+	if alias.InexactOverlap(dst[:len(src)], src) {
+		panic("crypto/cipher: invalid buffer overlap")
+	}
+	if len(src) == 0 {
+		return
+	}
+	copyNode(dst, x.cipher.Call("update", src))
+}
+
+const gcmMinimumTagSize = 12 // NIST SP 800-38D recommends tags with 12 or more bytes.
+
+var errOpen = errors.New("cipher: message authentication failed")
+
+func (c *aesCipherNode) NewGCM(nonceSize, tagSize int) (cipher.AEAD, error) {
+	return &nodeGCM{c: c, nonceSize: nonceSize, tagSize: tagSize}, nil
+}
+
+type nodeGCM struct {
+	c         *aesCipherNode
+	nonceSize int
+	tagSize   int
+}
+
+func (g *nodeGCM) NonceSize() int { return g.nonceSize }
+
+func (g *nodeGCM) Overhead() int { return g.tagSize }
+
+// options returns the options of a GCM cipher in Node.js crypto.
+func (g *nodeGCM) options() js.M {
+	return js.M{"authTagLength": g.tagSize}
+}
+
+func (g *nodeGCM) Seal(dst, nonce, plaintext, data []byte) []byte {
+	if len(nonce) != g.nonceSize {
+		panic("crypto/cipher: incorrect nonce length given to GCM")
+	}
+	if uint64(len(plaintext)) > ((1<<32)-2)*BlockSize {
+		panic("crypto/cipher: message too large for GCM")
+	}
+
+	ret, out := sliceForAppend(dst, len(plaintext)+g.tagSize)
+	if alias.InexactOverlap(out, plaintext) {
+		panic("crypto/cipher: invalid buffer overlap")
+	}
+
+	cipher := boring.NodeCrypto().Call("createCipheriv", g.c.name+"-gcm", g.c.key, nonce, g.options())
+	if len(data) > 0 {
+		cipher.Call("setAAD", data)
+	}
+	if len(plaintext) > 0 {
+		// Node.js returns a new buffer, so out may be overwritten even if
+		// it overlaps plaintext exactly.
+		copyNode(out, cipher.Call("update", plaintext))
+	}
+	cipher.Call("final")
+	copyNode(out[len(plaintext):], cipher.Call("getAuthTag"))
+
+	return ret
+}
+
+func (g *nodeGCM) Open(dst, nonce, ciphertext, data []byte) ([]byte, error) {
+	if len(nonce) != g.nonceSize {
+		panic("crypto/cipher: incorrect nonce length given to GCM")
+	}
+	// Sanity check to prevent the authentication from always succeeding if an implementation
+	// leaves tagSize uninitialized, for example.
+	if g.tagSize < gcmMinimumTagSize {
+		panic("crypto/cipher: incorrect GCM tag size")
+	}
+
+	if len(ciphertext) < g.tagSize {
+		return nil, errOpen
+	}
+	if uint64(len(ciphertext)) > ((1<<32)-2)*BlockSize+uint64(g.tagSize) {
+		return nil, errOpen
+	}
+
+	tag := ciphertext[len(ciphertext)-g.tagSize:]
+	ciphertext = ciphertext[:len(ciphertext)-g.tagSize]
+
+	ret, out := sliceForAppend(dst, len(ciphertext))
+	if alias.InexactOverlap(out, ciphertext) {
+		panic("crypto/cipher: invalid buffer overlap")
+	}
+
+	decipher := boring.NodeCrypto().Call("createDecipheriv", g.c.name+"-gcm", g.c.key, nonce, g.options())
+	if len(data) > 0 {
+		decipher.Call("setAAD", data)
+	}
+	decipher.Call("setAuthTag", tag)
+	var plaintext *js.Object
+	if len(ciphertext) > 0 {
+		plaintext = decipher.Call("update", ciphertext)
+	}
+	if !nodeFinal(decipher) {
+		// Mimic the other implementations, which overwrite dst in the event
+		// of a tag mismatch.
+		for i := range out {
+			out[i] = 0
+		}
+		return nil, errOpen
+	}
+	if plaintext != nil {
+		copyNode(out, plaintext)
+	}
+
+	return ret, nil
+}
+
+// nodeFinal finishes a decipher and reports whether the authentication tag
+// was correct.
+func nodeFinal(decipher *js.Object) (ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	decipher.Call("final")
+	return true
+}
+
+// sliceForAppend takes a slice and a requested number of bytes. It returns a
+// slice with the contents of the given slice followed by that many bytes and a
+// second slice that aliases into it and contains only the extra bytes. If the
+// original slice has sufficient capacity then no allocation is performed.
+func sliceForAppend(in []byte, n int) (head, tail []byte) {
+	if total := len(in) + n; cap(in) >= total {
+		head = in[:total]
+	} else {
+		head = make([]byte, total)
+		copy(head, in)
+	}
+	tail = head[len(in):]
+	return
+}
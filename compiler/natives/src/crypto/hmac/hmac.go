@@ -0,0 +1,18 @@
+//go:build js
+
+package hmac
+
+import "crypto/internal/boring"
+
+//gopherjs:keep-original
+func (h *hmac) Reset() {
+	// Restoring a marshaled state would switch hashes computed by Node.js
+	// crypto to the much slower Go implementation, so rewrite the padded key
+	// instead.
+	if inner, ok := h.inner.(boring.NodeHasher); ok && inner.UsesNodeCrypto() && !h.marshaled {
+		h.inner.Reset()
+		h.inner.Write(h.ipad)
+		return
+	}
+	h._gopherjs_original_Reset()
+}
@@ -0,0 +1,146 @@
+//go:build js
+
+package boring
+
+import (
+	"errors"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// Under Node.js, the hash and AES packages of the standard library use the
+// synchronous crypto module, which is much faster than the Go implementations
+// compiled to JavaScript. Browsers only provide the asynchronous WebCrypto
+// API, so the Go implementations are used there.
+
+var (
+	nodeCrypto     *js.Object
+	nodeAlgorithms = map[string]bool{}
+)
+
+func init() {
+	require := js.Global.Get("require")
+	if require == js.Undefined {
+		return
+	}
+	defer func() {
+		if recover() != nil {
+			nodeCrypto = nil
+		}
+	}()
+	nodeCrypto = require.Invoke("crypto")
+	if nodeCrypto.Get("createHash") == js.Undefined {
+		nodeCrypto = nil
+	}
+}
+
+// NodeCrypto returns the Node.js crypto module, or nil if it is unavailable.
+func NodeCrypto() *js.Object {
+	return nodeCrypto
+}
+
+// nodeSupports reports whether Node.js crypto implements the hash algorithm,
+// and can copy the hash to sum it in the middle of a stream.
+func nodeSupports(algorithm string) bool {
+	if nodeCrypto == nil {
+		return false
+	}
+	supported, ok := nodeAlgorithms[algorithm]
+	if !ok {
+		func() {
+			defer func() { recover() }()
+			supported = nodeCrypto.Call("createHash", algorithm).Get("copy") != js.Undefined
+		}()
+		nodeAlgorithms[algorithm] = supported
+	}
+	return supported
+}
+
+// nodeReplayLimit is the number of written bytes a NodeHash keeps.
+const nodeReplayLimit = 64 << 10
+
+// errNodeState is returned when marshaling the state of a hash, which can't be
+// replayed.
+var errNodeState = errors.New("crypto: the state of a hash of more than 64 KiB computed by Node.js crypto can't be marshaled")
+
+// NodeHash computes a hash with Node.js crypto. The state of a Node.js Hash
+// can't be read, so the first nodeReplayLimit bytes written are kept as well,
+// to be replayed by the Go implementations when marshaling the state.
+type NodeHash struct {
+	algorithm string
+	hash      *js.Object
+	written   []byte
+	replay    bool // written holds all bytes written since Reset
+}
+
+// NodeHasher is implemented by the digests of the hash packages.
+// UsesNodeCrypto reports whether the hash is computed by Node.js crypto.
+type NodeHasher interface {
+	UsesNodeCrypto() bool
+}
+
+// NewNodeHash returns a NodeHash for the algorithm in the naming of Node.js,
+// or nil if Node.js crypto isn't available or doesn't support it.
+func NewNodeHash(algorithm string) *NodeHash {
+	if !nodeSupports(algorithm) {
+		return nil
+	}
+	h := &NodeHash{algorithm: algorithm}
+	h.Reset()
+	return h
+}
+
+func (h *NodeHash) Write(p []byte) {
+	nodeUpdate(h.hash, p)
+	if !h.replay {
+		return
+	}
+	if len(h.written)+len(p) > nodeReplayLimit {
+		h.written, h.replay = nil, false
+		return
+	}
+	h.written = append(h.written, p...)
+}
+
+// Sum appends the hash of the bytes written so far to in.
+func (h *NodeHash) Sum(in []byte) []byte {
+	return append(in, nodeBytes(h.hash.Call("copy").Call("digest"))...)
+}
+
+// Written returns the bytes written so far, or an error if there were too many
+// of them to keep.
+func (h *NodeHash) Written() ([]byte, error) {
+	if !h.replay {
+		return nil, errNodeState
+	}
+	return h.written, nil
+}
+
+func (h *NodeHash) Reset() {
+	h.hash = nodeCrypto.Call("createHash", h.algorithm)
+	h.written, h.replay = h.written[:0], true
+}
+
+// NodeSum returns the hash of data computed by Node.js crypto. It reports
+// false if Node.js crypto isn't available or doesn't support the algorithm.
+func NodeSum(algorithm string, data []byte) ([]byte, bool) {
+	if !nodeSupports(algorithm) {
+		return nil, false
+	}
+	hash := nodeCrypto.Call("createHash", algorithm)
+	nodeUpdate(hash, data)
+	return nodeBytes(hash.Call("digest")), true
+}
+
+// nodeUpdate passes p to the update method of a Node.js Hash. Empty slices
+// are skipped, a nil slice would be passed as null.
+func nodeUpdate(hash *js.Object, p []byte) {
+	if len(p) > 0 {
+		hash.Call("update", p)
+	}
+}
+
+// nodeBytes copies the contents of a Node.js Buffer.
+func nodeBytes(buf *js.Object) []byte {
+	return js.Global.Get("Uint8Array").New(buf).Interface().([]byte)
+}
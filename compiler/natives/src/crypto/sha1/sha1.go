@@ -0,0 +1,201 @@
+//go:build js
+
+package sha1
+
+import (
+	"errors"
+	"hash"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// Natives may only import the packages the original package depends on, which
+// TestNativesDontImportExtraPackages checks, and crypto/sha1 only depends on
+// crypto/internal/boring when built with BoringCrypto. So it has its own copy
+// of the NodeHash of crypto/internal/boring.
+
+var nodeCrypto *js.Object
+
+func init() {
+	if require := js.Global.Get("require"); require != js.Undefined {
+		defer func() { recover() }()
+		nodeCrypto = require.Invoke("crypto")
+		if nodeCrypto.Get("createHash").Invoke("sha1").Get("copy") == js.Undefined {
+			nodeCrypto = nil
+		}
+	}
+}
+
+// nodeReplayLimit is the number of written bytes a nodeHash keeps.
+const nodeReplayLimit = 64 << 10
+
+var errNodeState = errors.New("crypto: the state of a hash of more than 64 KiB computed by Node.js crypto can't be marshaled")
+
+// nodeHash computes a hash with Node.js crypto. The state of a Node.js Hash
+// can't be read, so the first nodeReplayLimit bytes written are kept as well,
+// to be replayed by the Go implementation when marshaling the state.
+type nodeHash struct {
+	hash    *js.Object
+	written []byte
+	replay  bool // written holds all bytes written since Reset
+}
+
+func newNodeHash() *nodeHash {
+	if nodeCrypto == nil {
+		return nil
+	}
+	h := &nodeHash{}
+	h.Reset()
+	return h
+}
+
+func (h *nodeHash) Write(p []byte) {
+	nodeUpdate(h.hash, p)
+	if !h.replay {
+		return
+	}
+	if len(h.written)+len(p) > nodeReplayLimit {
+		h.written, h.replay = nil, false
+		return
+	}
+	h.written = append(h.written, p...)
+}
+
+func (h *nodeHash) Sum(in []byte) []byte {
+	return append(in, nodeBytes(h.hash.Call("copy").Call("digest"))...)
+}
+
+// Written returns the bytes written so far, or an error if there were too many
+// of them to keep.
+func (h *nodeHash) Written() ([]byte, error) {
+	if !h.replay {
+		return nil, errNodeState
+	}
+	return h.written, nil
+}
+
+func (h *nodeHash) Reset() {
+	h.hash = nodeCrypto.Call("createHash", "sha1")
+	h.written, h.replay = h.written[:0], true
+}
+
+// nodeSum returns the hash of p computed by Node.js crypto.
+func nodeSum(p []byte) []byte {
+	hash := nodeCrypto.Call("createHash", "sha1")
+	nodeUpdate(hash, p)
+	return nodeBytes(hash.Call("digest"))
+}
+
+// nodeUpdate passes p to the update method of a Node.js Hash. Empty slices
+// are skipped, a nil slice would be passed as null.
+func nodeUpdate(hash *js.Object, p []byte) {
+	if len(p) > 0 {
+		hash.Call("update", p)
+	}
+}
+
+// nodeBytes copies the contents of a Node.js Buffer.
+func nodeBytes(buf *js.Object) []byte {
+	return js.Global.Get("Uint8Array").New(buf).Interface().([]byte)
+}
+
+type digest struct {
+	h   [5]uint32
+	x   [chunk]byte
+	nx  int
+	len uint64
+
+	node *nodeHash // computes the hash with Node.js crypto if non-nil
+}
+
+func (d *digest) UsesNodeCrypto() bool { return d.node != nil }
+
+//gopherjs:keep-original
+func New() hash.Hash {
+	d := _gopherjs_original_New().(*digest)
+	d.node = newNodeHash()
+	return d
+}
+
+//gopherjs:keep-original
+func (d *digest) Reset() {
+	d._gopherjs_original_Reset()
+	if d.node != nil {
+		d.node.Reset()
+	} else {
+		d.node = newNodeHash()
+	}
+}
+
+//gopherjs:keep-original
+func (d *digest) Write(p []byte) (nn int, err error) {
+	if d.node != nil {
+		d.node.Write(p)
+		return len(p), nil
+	}
+	return d._gopherjs_original_Write(p)
+}
+
+//gopherjs:keep-original
+func (d *digest) Sum(in []byte) []byte {
+	if d.node != nil {
+		return d.node.Sum(in)
+	}
+	return d._gopherjs_original_Sum(in)
+}
+
+// goDigest returns a digest computed by the Go implementation with the same
+// state as d, or an error if the state of d can't be replayed.
+func (d *digest) goDigest() (*digest, error) {
+	if d.node == nil {
+		return d, nil
+	}
+	written, err := d.node.Written()
+	if err != nil {
+		return nil, err
+	}
+	g := new(digest)
+	g._gopherjs_original_Reset()
+	g._gopherjs_original_Write(written)
+	return g, nil
+}
+
+// ConstantTimeSum is used to compute the MACs of TLS records, which are short
+// enough to be replayed by the Go implementation. Longer hashes are summed by
+// Node.js crypto.
+//
+//gopherjs:keep-original
+func (d *digest) ConstantTimeSum(in []byte) []byte {
+	g, err := d.goDigest()
+	if err != nil {
+		return d.node.Sum(in)
+	}
+	return g._gopherjs_original_ConstantTimeSum(in)
+}
+
+//gopherjs:keep-original
+func (d *digest) MarshalBinary() ([]byte, error) {
+	g, err := d.goDigest()
+	if err != nil {
+		return nil, err
+	}
+	return g._gopherjs_original_MarshalBinary()
+}
+
+//gopherjs:keep-original
+func (d *digest) UnmarshalBinary(b []byte) error {
+	if err := d._gopherjs_original_UnmarshalBinary(b); err != nil {
+		return err
+	}
+	// The restored state can only be continued by the Go implementation.
+	d.node = nil
+	return nil
+}
+
+//gopherjs:keep-original
+func Sum(data []byte) [Size]byte {
+	if nodeCrypto != nil {
+		return *(*[Size]byte)(nodeSum(data))
+	}
+	return _gopherjs_original_Sum(data)
+}
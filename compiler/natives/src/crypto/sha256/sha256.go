@@ -0,0 +1,109 @@
+//go:build js
+
+package sha256
+
+import (
+	"crypto/internal/boring"
+	"hash"
+)
+
+type digest struct {
+	h     [8]uint32
+	x     [chunk]byte
+	nx    int
+	len   uint64
+	is224 bool // mark if this digest is SHA-224
+
+	node *boring.NodeHash // computes the hash with Node.js crypto if non-nil
+}
+
+func (d *digest) algorithm() string {
+	if d.is224 {
+		return "sha224"
+	}
+	return "sha256"
+}
+
+func (d *digest) UsesNodeCrypto() bool { return d.node != nil }
+
+//gopherjs:keep-original
+func New() hash.Hash {
+	d := _gopherjs_original_New().(*digest)
+	d.node = boring.NewNodeHash(d.algorithm())
+	return d
+}
+
+//gopherjs:keep-original
+func New224() hash.Hash {
+	d := _gopherjs_original_New224().(*digest)
+	d.node = boring.NewNodeHash(d.algorithm())
+	return d
+}
+
+//gopherjs:keep-original
+func (d *digest) Reset() {
+	d._gopherjs_original_Reset()
+	if d.node != nil {
+		d.node.Reset()
+	} else {
+		d.node = boring.NewNodeHash(d.algorithm())
+	}
+}
+
+//gopherjs:keep-original
+func (d *digest) Write(p []byte) (nn int, err error) {
+	if d.node != nil {
+		d.node.Write(p)
+		return len(p), nil
+	}
+	return d._gopherjs_original_Write(p)
+}
+
+//gopherjs:keep-original
+func (d *digest) Sum(in []byte) []byte {
+	if d.node != nil {
+		return d.node.Sum(in)
+	}
+	return d._gopherjs_original_Sum(in)
+}
+
+//gopherjs:keep-original
+func (d *digest) MarshalBinary() ([]byte, error) {
+	if d.node == nil {
+		return d._gopherjs_original_MarshalBinary()
+	}
+	written, err := d.node.Written()
+	if err != nil {
+		return nil, err
+	}
+	g := &digest{is224: d.is224}
+	g._gopherjs_original_Reset()
+	g._gopherjs_original_Write(written)
+	return g._gopherjs_original_MarshalBinary()
+}
+
+//gopherjs:keep-original
+func (d *digest) UnmarshalBinary(b []byte) error {
+	if err := d._gopherjs_original_UnmarshalBinary(b); err != nil {
+		return err
+	}
+	// The restored state can only be continued by the Go implementation.
+	d.node = nil
+	return nil
+}
+
+//gopherjs:keep-original
+func Sum256(data []byte) [Size]byte {
+	if sum, ok := boring.NodeSum("sha256", data); ok {
+		return *(*[Size]byte)(sum)
+	}
+	return _gopherjs_original_Sum256(data)
+}
+
+//gopherjs:keep-original
+func Sum224(data []byte) [Size224]byte {
+	if sum, ok := boring.NodeSum("sha224", data); ok {
+		return *(*[Size224]byte)(sum)
+	}
+	return _gopherjs_original_Sum224(data)
+}
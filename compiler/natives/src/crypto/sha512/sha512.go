@@ -0,0 +1,131 @@
+//go:build js
+
+package sha512
+
+import (
+	"crypto"
+	"crypto/internal/boring"
+	"hash"
+)
+
+type digest struct {
+	h        [8]uint64
+	x        [chunk]byte
+	nx       int
+	len      uint64
+	function crypto.Hash
+
+	node *boring.NodeHash // computes the hash with Node.js crypto if non-nil
+}
+
+// nodeAlgorithms maps the hash functions to their names in Node.js crypto.
+var nodeAlgorithms = map[crypto.Hash]string{
+	crypto.SHA384:     "sha384",
+	crypto.SHA512:     "sha512",
+	crypto.SHA512_224: "sha512-224",
+	crypto.SHA512_256: "sha512-256",
+}
+
+func (d *digest) UsesNodeCrypto() bool { return d.node != nil }
+
+func withNodeHash(h hash.Hash) hash.Hash {
+	d := h.(*digest)
+	d.node = boring.NewNodeHash(nodeAlgorithms[d.function])
+	return d
+}
+
+//gopherjs:keep-original
+func New() hash.Hash { return withNodeHash(_gopherjs_original_New()) }
+
+//gopherjs:keep-original
+func New512_224() hash.Hash { return withNodeHash(_gopherjs_original_New512_224()) }
+
+//gopherjs:keep-original
+func New512_256() hash.Hash { return withNodeHash(_gopherjs_original_New512_256()) }
+
+//gopherjs:keep-original
+func New384() hash.Hash { return withNodeHash(_gopherjs_original_New384()) }
+
+//gopherjs:keep-original
+func (d *digest) Reset() {
+	d._gopherjs_original_Reset()
+	if d.node != nil {
+		d.node.Reset()
+	} else {
+		d.node = boring.NewNodeHash(nodeAlgorithms[d.function])
+	}
+}
+
+//gopherjs:keep-original
+func (d *digest) Write(p []byte) (nn int, err error) {
+	if d.node != nil {
+		d.node.Write(p)
+		return len(p), nil
+	}
+	return d._gopherjs_original_Write(p)
+}
+
+//gopherjs:keep-original
+func (d *digest) Sum(in []byte) []byte {
+	if d.node != nil {
+		return d.node.Sum(in)
+	}
+	return d._gopherjs_original_Sum(in)
+}
+
+//gopherjs:keep-original
+func (d *digest) MarshalBinary() ([]byte, error) {
+	if d.node == nil {
+		return d._gopherjs_original_MarshalBinary()
+	}
+	written, err := d.node.Written()
+	if err != nil {
+		return nil, err
+	}
+	g := &digest{function: d.function}
+	g._gopherjs_original_Reset()
+	g._gopherjs_original_Write(written)
+	return g._gopherjs_original_MarshalBinary()
+}
+
+//gopherjs:keep-original
+func (d *digest) UnmarshalBinary(b []byte) error {
+	if err := d._gopherjs_original_UnmarshalBinary(b); err != nil {
+		return err
+	}
+	// The restored state can only be continued by the Go implementation.
+	d.node = nil
+	return nil
+}
+
+//gopherjs:keep-original
+func Sum512(data []byte) [Size]byte {
+	if sum, ok := boring.NodeSum("sha512", data); ok {
+		return *(*[Size]byte)(sum)
+	}
+	return _gopherjs_original_Sum512(data)
+}
+
+//gopherjs:keep-original
+func Sum384(data []byte) [Size384]byte {
+	if sum, ok := boring.NodeSum("sha384", data); ok {
+		return *(*[Size384]byte)(sum)
+	}
+	return _gopherjs_original_Sum384(data)
+}
+
+//gopherjs:keep-original
+func Sum512_224(data []byte) [Size224]byte {
+	if sum, ok := boring.NodeSum("sha512-224", data); ok {
+		return *(*[Size224]byte)(sum)
+	}
+	return _gopherjs_original_Sum512_224(data)
+}
+
+//gopherjs:keep-original
+func Sum512_256(data []byte) [Size256]byte {
+	if sum, ok := boring.NodeSum("sha512-256", data); ok {
+		return *(*[Size256]byte)(sum)
+	}
+	return _gopherjs_original_Sum512_256(data)
+}
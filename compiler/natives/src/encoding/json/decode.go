@@ -0,0 +1,387 @@
+//go:build js
+
+package json
+
+import (
+	"encoding/base64"
+	"math"
+	"reflect"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// jsonNative holds the helpers of json.inc.js, or undefined if the
+// JavaScript engine lacks what they need.
+var jsonNative = js.Global.Get("$jsonNative")
+
+// Kinds of JavaScript values returned by jsonNative.kind.
+const (
+	jsonNull = iota
+	jsonBool
+	jsonString
+	jsonNumber
+	jsonArray
+	jsonObject
+)
+
+// Unmarshal and Decoder.Decode build the value with JSON.parse and store it
+// with nativeValue, which mirrors decodeState.value. Whenever the Go decoder
+// could produce an error or call an Unmarshaler, nativeValue gives up and the
+// value is decoded again by the Go decoder. This is harmless, since decoding
+// the same JSON twice has the same result as decoding it once.
+
+//gopherjs:keep-original
+func Unmarshal(data []byte, v any) error {
+	var d decodeState
+	if x, ok := parseNative(data); ok {
+		// JSON.parse accepted data, so checkValid can be skipped.
+		d.init(data)
+		if err, ok := d.unmarshalNative(x, v); ok {
+			return err
+		}
+	} else if err := checkValid(data, &d.scan); err != nil {
+		return err
+	}
+	d.init(data)
+	return d._gopherjs_original_unmarshal(v)
+}
+
+//gopherjs:keep-original
+func (d *decodeState) unmarshal(v any) error {
+	if x, ok := parseNative(d.data); ok {
+		if err, ok := d.unmarshalNative(x, v); ok {
+			return err
+		}
+		d.init(d.data)
+	}
+	return d._gopherjs_original_unmarshal(v)
+}
+
+// parseNative returns the value of the JSON text in data. It reports false
+// if data isn't valid JSON or JSON.parse can't decode it exactly.
+func parseNative(data []byte) (*js.Object, bool) {
+	if jsonNative == js.Undefined {
+		return nil, false
+	}
+	x := jsonNative.Call("parse", data)
+	return x, x != js.Undefined
+}
+
+// unmarshalNative stores the value x returned by jsonNative.parse in v. It
+// reports false if the Go decoder must be used.
+func (d *decodeState) unmarshalNative(x *js.Object, v any) (error, bool) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(v)}, true
+	}
+	if !d.nativeValue(x, rv) {
+		return nil, false
+	}
+	return nil, true
+}
+
+// nativeValue stores x in v like value does, it reports false if that would
+// cause an error or call an Unmarshaler.
+func (d *decodeState) nativeValue(x *js.Object, v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+	kind := jsonNative.Call("kind", x).Int()
+	u, ut, pv := indirect(v, kind == jsonNull)
+	if u != nil || ut != nil {
+		return false
+	}
+	v = pv
+
+	switch kind {
+	case jsonNull:
+		switch v.Kind() {
+		case reflect.Interface, reflect.Pointer, reflect.Map, reflect.Slice:
+			v.Set(reflect.Zero(v.Type()))
+			// otherwise, ignore null for primitives/string
+		}
+	case jsonBool:
+		switch v.Kind() {
+		case reflect.Bool:
+			v.SetBool(x.Bool())
+		case reflect.Interface:
+			if v.NumMethod() != 0 {
+				return false
+			}
+			v.Set(reflect.ValueOf(x.Bool()))
+		default:
+			return false
+		}
+	case jsonString:
+		switch v.Kind() {
+		case reflect.Slice:
+			if v.Type().Elem().Kind() != reflect.Uint8 {
+				return false
+			}
+			s := []byte(x.String())
+			b := make([]byte, base64.StdEncoding.DecodedLen(len(s)))
+			n, err := base64.StdEncoding.Decode(b, s)
+			if err != nil {
+				return false
+			}
+			v.SetBytes(b[:n])
+		case reflect.String:
+			if v.Type() == numberType {
+				return false
+			}
+			v.SetString(x.String())
+		case reflect.Interface:
+			if v.NumMethod() != 0 {
+				return false
+			}
+			v.Set(reflect.ValueOf(x.String()))
+		default:
+			return false
+		}
+	case jsonNumber:
+		return d.nativeNumber(x, v)
+	case jsonArray:
+		return d.nativeArray(x, v)
+	case jsonObject:
+		return d.nativeObject(x, v)
+	}
+	return true
+}
+
+// nativeNumber stores the number literal x in v like literalStore does.
+func (d *decodeState) nativeNumber(x *js.Object, v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Float64:
+		// JavaScript numbers are float64, rounded like strconv.ParseFloat.
+		f := jsonNative.Call("float", x).Float()
+		if math.IsInf(f, 0) {
+			return false
+		}
+		v.SetFloat(f)
+		return true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if f := jsonNative.Call("integer", x).Float(); f == f {
+			if v.OverflowInt(int64(f)) {
+				return false
+			}
+			v.SetInt(int64(f))
+			return true
+		}
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return false
+		}
+		n, ok := d.nativeInterface(x)
+		if !ok {
+			return false
+		}
+		v.Set(reflect.ValueOf(n))
+		return true
+	}
+	d.literalStore([]byte(jsonNative.Call("literal", x).String()), v, false)
+	return d.savedError == nil
+}
+
+// nativeArray mirrors array.
+func (d *decodeState) nativeArray(x *js.Object, v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return false
+		}
+		ai, ok := d.nativeInterface(x)
+		if !ok {
+			return false
+		}
+		v.Set(reflect.ValueOf(ai))
+		return true
+	default:
+		return false
+	case reflect.Array, reflect.Slice:
+		break
+	}
+
+	length := x.Length()
+	i := 0
+	for ; i < length; i++ {
+		// Get element of array, growing if necessary.
+		if v.Kind() == reflect.Slice {
+			// Grow slice if necessary
+			if i >= v.Cap() {
+				newcap := v.Cap() + v.Cap()/2
+				if newcap < 4 {
+					newcap = 4
+				}
+				newv := reflect.MakeSlice(v.Type(), v.Len(), newcap)
+				reflect.Copy(newv, v)
+				v.Set(newv)
+			}
+			if i >= v.Len() {
+				v.SetLen(i + 1)
+			}
+		}
+
+		if i >= v.Len() {
+			// Ran out of fixed array: skip the rest.
+			i = length
+			break
+		}
+		if !d.nativeValue(x.Index(i), v.Index(i)) {
+			return false
+		}
+	}
+
+	if i < v.Len() {
+		if v.Kind() == reflect.Array {
+			// Array. Zero the rest.
+			z := reflect.Zero(v.Type().Elem())
+			for ; i < v.Len(); i++ {
+				v.Index(i).Set(z)
+			}
+		} else {
+			v.SetLen(i)
+		}
+	}
+	if i == 0 && v.Kind() == reflect.Slice {
+		v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+	}
+	return true
+}
+
+// nativeObject mirrors object. Only maps with keys of string kind are
+// supported, because integer keys could be duplicates of each other.
+func (d *decodeState) nativeObject(x *js.Object, v reflect.Value) bool {
+	t := v.Type()
+
+	if v.Kind() == reflect.Interface && v.NumMethod() == 0 {
+		oi, ok := d.nativeInterface(x)
+		if !ok {
+			return false
+		}
+		v.Set(reflect.ValueOf(oi))
+		return true
+	}
+
+	var fields structFields
+
+	switch v.Kind() {
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String || reflect.PointerTo(t.Key()).Implements(textUnmarshalerType) {
+			return false
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(t))
+		}
+	case reflect.Struct:
+		fields = cachedTypeFields(t)
+	default:
+		return false
+	}
+
+	var mapElem reflect.Value
+	keys := jsonNative.Call("keys", x)
+	for i, n := 0, keys.Length(); i < n; i++ {
+		key := keys.Index(i).String()
+
+		var subv reflect.Value
+		if v.Kind() == reflect.Map {
+			elemType := t.Elem()
+			if !mapElem.IsValid() {
+				mapElem = reflect.New(elemType).Elem()
+			} else {
+				mapElem.Set(reflect.Zero(elemType))
+			}
+			subv = mapElem
+		} else {
+			var f *field
+			if i, ok := fields.nameIndex[key]; ok {
+				// Found an exact name match.
+				f = &fields.list[i]
+			} else {
+				// Fall back to the expensive case-insensitive
+				// linear search.
+				keyBytes := []byte(key)
+				for i := range fields.list {
+					ff := &fields.list[i]
+					if ff.equalFold(ff.nameBytes, keyBytes) {
+						f = ff
+						break
+					}
+				}
+			}
+			if f != nil {
+				if f.quoted {
+					return false
+				}
+				subv = v
+				for _, i := range f.index {
+					if subv.Kind() == reflect.Pointer {
+						if subv.IsNil() {
+							if !subv.CanSet() {
+								return false
+							}
+							subv.Set(reflect.New(subv.Type().Elem()))
+						}
+						subv = subv.Elem()
+					}
+					subv = subv.Field(i)
+				}
+			} else if d.disallowUnknownFields {
+				return false
+			}
+		}
+
+		if !d.nativeValue(x.Get(key), subv) {
+			return false
+		}
+
+		// Write value back to map;
+		// if using struct, subv points into struct already.
+		if v.Kind() == reflect.Map {
+			v.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), subv)
+		}
+	}
+	return true
+}
+
+// nativeInterface mirrors valueInterface.
+func (d *decodeState) nativeInterface(x *js.Object) (any, bool) {
+	switch jsonNative.Call("kind", x).Int() {
+	case jsonBool:
+		return x.Bool(), true
+	case jsonString:
+		return x.String(), true
+	case jsonNumber:
+		if d.useNumber {
+			return Number(jsonNative.Call("literal", x).String()), true
+		}
+		f := jsonNative.Call("float", x).Float()
+		if math.IsInf(f, 0) {
+			return nil, false
+		}
+		return f, true
+	case jsonArray:
+		a := make([]any, x.Length())
+		for i := range a {
+			v, ok := d.nativeInterface(x.Index(i))
+			if !ok {
+				return nil, false
+			}
+			a[i] = v
+		}
+		return a, true
+	case jsonObject:
+		m := make(map[string]any)
+		keys := jsonNative.Call("keys", x)
+		for i, n := 0, keys.Length(); i < n; i++ {
+			key := keys.Index(i).String()
+			v, ok := d.nativeInterface(x.Get(key))
+			if !ok {
+				return nil, false
+			}
+			m[key] = v
+		}
+		return m, true
+	}
+	return nil, true
+}
@@ -0,0 +1,82 @@
+//go:build js
+
+package json
+
+import (
+	"math"
+	"sort"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+//gopherjs:keep-original
+func Marshal(v any) ([]byte, error) {
+	switch v.(type) {
+	case map[string]any, []any:
+		if jsonNative != js.Undefined {
+			if x, ok := marshalNative(v, 0); ok {
+				return jsonNative.Call("stringify", x).Interface().([]byte), nil
+			}
+		}
+	}
+	return _gopherjs_original_Marshal(v)
+}
+
+// marshalNative converts v to a JavaScript value JSON.stringify encodes like
+// Marshal. It reports false if v holds a value of another type than the ones
+// of Unmarshal into an interface, or one JSON.stringify encodes differently:
+// floats that aren't finite, negative zero and invalid UTF-8. Possible cycles
+// are left for Marshal to report.
+func marshalNative(v any, depth int) (*js.Object, bool) {
+	if depth > startDetectingCyclesAfter {
+		return nil, false
+	}
+	switch v := v.(type) {
+	case nil:
+		return nil, true
+	case bool:
+		return js.InternalObject(v), true
+	case string:
+		s := jsonNative.Call("string", js.InternalObject(v))
+		return s, s != js.Undefined
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) || v == 0 && math.Signbit(v) {
+			return nil, false
+		}
+		return js.InternalObject(v), true
+	case int:
+		// Integers of 32 bits are formatted by JavaScript like Go does.
+		return js.InternalObject(v), true
+	case map[string]any:
+		if v == nil {
+			return nil, true
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		o := jsonNative.Call("object")
+		for _, k := range keys {
+			x, ok := marshalNative(v[k], depth+1)
+			if !ok || !jsonNative.Call("set", o, js.InternalObject(k), x).Bool() {
+				return nil, false
+			}
+		}
+		return o, true
+	case []any:
+		if v == nil {
+			return nil, true
+		}
+		a := js.Global.Get("Array").New(len(v))
+		for i, e := range v {
+			x, ok := marshalNative(e, depth+1)
+			if !ok {
+				return nil, false
+			}
+			a.SetIndex(i, x)
+		}
+		return a, true
+	}
+	return nil, false
+}
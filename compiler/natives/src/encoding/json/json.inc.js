@@ -0,0 +1,170 @@
+// Helpers for the encoding/json natives, which decode and encode JSON with
+// JSON.parse and JSON.stringify. Number literals are passed to Go as strings
+// prefixed with "\0", so that they are converted exactly like the Go decoder
+// does. Engines without TextDecoder leave $jsonNative undefined and
+// encoding/json uses the Go implementation.
+$global.$jsonNative = undefined; // encoding/json reads it as a global variable
+if (typeof TextDecoder === "function" && typeof TextEncoder === "function") {
+    const utf8Decoder = new TextDecoder("utf-8", { fatal: true, ignoreBOM: true });
+    const utf8Encoder = new TextEncoder();
+
+    // Strings and numbers of a JSON text, with the colon following a key.
+    const tokens = /"[^"\\]*(?:\\.[^"\\]*)*"(\s*:)?|(-?(?:0|[1-9]\d*)(?:\.\d+)?(?:[eE][+-]?\d+)?)(\s*:)?/g;
+    const surrogateEscape = /\\u[dD][89a-fA-F]/;
+    const loneSurrogate = /[\ud800-\udbff](?![\udc00-\udfff])|(?<![\ud800-\udbff])[\udc00-\udfff]/;
+    const exactInteger = /^\0-?\d{1,15}$/;
+    const arrayIndex = /^(?:0|[1-9]\d{0,8})$/;
+    const htmlEscapes = /\\[\\bf]|[<>&\u2028\u2029]/g;
+    const maxDepth = 10000; // nesting limit of the scanner of encoding/json
+
+    // check counts the keys of the objects in v. It returns -1 if v nests
+    // deeper than maxDepth or contains a lone surrogate, which the Go decoder
+    // replaces by U+FFFD.
+    const check = (v, surrogates, depth) => {
+        if (depth >= maxDepth) {
+            return -1;
+        }
+        if (typeof v === "string") {
+            return surrogates && loneSurrogate.test(v) ? -1 : 0;
+        }
+        if (v === null || typeof v !== "object") {
+            return 0;
+        }
+        let keys = 0;
+        if (Array.isArray(v)) {
+            for (let i = 0; i < v.length; i++) {
+                const n = check(v[i], surrogates, depth + 1);
+                if (n < 0) {
+                    return -1;
+                }
+                keys += n;
+            }
+            return keys;
+        }
+        for (const key of Object.keys(v)) {
+            const n = check(v[key], surrogates, depth + 1);
+            if (n < 0 || surrogates && loneSurrogate.test(key)) {
+                return -1;
+            }
+            keys += n + 1;
+        }
+        return keys;
+    };
+
+    $global.$jsonNative = {
+        // parse returns the value of the JSON text in data, or undefined if
+        // data isn't valid UTF-8, isn't valid JSON or uses something
+        // JSON.parse decodes differently, like duplicate keys.
+        parse(data) {
+            let text;
+            try {
+                text = utf8Decoder.decode(data);
+            } catch (e) {
+                return undefined;
+            }
+            if (text.includes("\\u0000")) {
+                return undefined; // can't be told apart from numbers
+            }
+            let keys = 0, numberKey = false;
+            text = text.replace(tokens, (token, keyColon, number, numberColon) => {
+                if (number === undefined) {
+                    if (keyColon !== undefined) {
+                        keys++;
+                    }
+                    return token;
+                }
+                if (numberColon !== undefined) {
+                    numberKey = true;
+                }
+                return "\"\\u0000" + number + "\"";
+            });
+            if (numberKey) {
+                return undefined;
+            }
+            try {
+                const value = JSON.parse(text);
+                if (check(value, surrogateEscape.test(text), 0) !== keys) {
+                    return undefined;
+                }
+                return value;
+            } catch (e) {
+                return undefined;
+            }
+        },
+
+        kind(v) {
+            switch (typeof v) {
+                case "boolean":
+                    return 1;
+                case "string":
+                    return v.charCodeAt(0) === 0 ? 3 : 2;
+                case "object":
+                    return v === null ? 0 : Array.isArray(v) ? 4 : 5;
+            }
+        },
+
+        keys(v) {
+            return Object.keys(v);
+        },
+
+        literal(v) {
+            return v.slice(1);
+        },
+
+        float(v) {
+            return Number(v.slice(1));
+        },
+
+        // integer returns the value of an integer literal of up to 15
+        // digits, which is exact as a float64, or NaN.
+        integer(v) {
+            return exactInteger.test(v) ? Number(v.slice(1)) : NaN;
+        },
+
+        // string converts a Go string to JavaScript, it returns undefined
+        // if s isn't valid UTF-8.
+        string(s) {
+            if (!/[^\x00-\x7f]/.test(s)) {
+                return s;
+            }
+            try {
+                return decodeURIComponent(escape(s));
+            } catch (e) {
+                return undefined;
+            }
+        },
+
+        object() {
+            return Object.create(null);
+        },
+
+        // set sets the Go string key of an object. It reports false if key
+        // isn't valid UTF-8 or JavaScript would enumerate it before the
+        // others.
+        set(o, key, value) {
+            key = $jsonNative.string(key);
+            if (key === undefined || arrayIndex.test(key)) {
+                return false;
+            }
+            o[key] = value;
+            return true;
+        },
+
+        // stringify encodes v like encoding/json.Marshal, which escapes HTML
+        // characters and U+2028 and U+2029, and doesn't use \b and \f.
+        stringify(v) {
+            const text = JSON.stringify(v).replace(htmlEscapes, (s) => {
+                switch (s) {
+                    case "\\\\":
+                        return s;
+                    case "\\b":
+                        return "\\u0008";
+                    case "\\f":
+                        return "\\u000c";
+                }
+                return "\\u" + s.charCodeAt(0).toString(16).padStart(4, "0");
+            });
+            return utf8Encoder.encode(text);
+        },
+    };
+}
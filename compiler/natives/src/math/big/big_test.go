@@ -2,8 +2,91 @@
 
 package big
 
-import "testing"
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/gopherjs/gopherjs/js"
+)
 
 func TestLinkerGC(t *testing.T) {
 	t.Skip("The test is specific to GC's linker.")
 }
+
+// TestBigIntFallback compares the arithmetic on BigInt with the Go
+// implementation, which is used when the engine doesn't support BigInt.
+func TestBigIntFallback(t *testing.T) {
+	if bigNat == js.Undefined {
+		t.Skip("BigInt is unsupported.")
+	}
+	withoutBigInt := func(f func()) {
+		saved := bigNat
+		bigNat = js.Undefined
+		defer func() { bigNat = saved }()
+		f()
+	}
+	rnd := rand.New(rand.NewSource(1))
+	randNat := func(n int) nat {
+		x := make(nat, n)
+		for i := range x {
+			x[i] = Word(rnd.Uint32())
+		}
+		x[n-1] |= 1
+		return x
+	}
+	check := func(op string, got, want nat) {
+		t.Helper()
+		if got.cmp(want) != 0 {
+			t.Errorf("%s gave %s on BigInt. Want: %s.", op, got.utoa(16), want.utoa(16))
+		}
+	}
+
+	for _, n := range []int{1, 8, 20, 50, 150} {
+		x, y, m := randNat(2*n+3), randNat(n), randNat(n)
+
+		var want nat
+		withoutBigInt(func() { want = nat(nil).mul(x, y) })
+		check("mul", nat(nil).mul(x, y), want)
+
+		withoutBigInt(func() { want = nat(nil).sqr(x) })
+		check("sqr", nat(nil).sqr(x), want)
+
+		var wantR nat
+		withoutBigInt(func() { want, wantR = nat(nil).div(nil, x, y) })
+		q, r := nat(nil).div(nil, x, y)
+		check("div quotient", q, want)
+		check("div remainder", r, wantR)
+
+		e := randNat(3)
+		withoutBigInt(func() { want = nat(nil).expNN(y, e, m, false) })
+		check("expNN", nat(nil).expNN(y, e, m, false), want)
+
+		for _, base := range []int{2, 7, 10, 16, 36} {
+			var wantS string
+			withoutBigInt(func() { wantS = string(x.itoa(true, base)) })
+			if got := string(x.itoa(true, base)); got != wantS {
+				t.Errorf("itoa(%d) gave %s on BigInt. Want: %s.", base, got, wantS)
+			}
+		}
+
+		for _, s := range []string{string(x.utoa(10)), "0x" + string(x.utoa(16)), "0b" + string(x.utoa(2)), "0o" + string(x.utoa(8)), string(x.utoa(10)) + "_1"} {
+			var wantErr error
+			var wantCount int
+			withoutBigInt(func() { want, _, wantCount, wantErr = nat(nil).scan(strings.NewReader(s), 0, false) })
+			got, _, count, err := nat(nil).scan(strings.NewReader(s), 0, false)
+			check("scan", got, want)
+			if count != wantCount || err != wantErr {
+				t.Errorf("scan gave count %d and error %v on BigInt. Want: %d and %v.", count, err, wantCount, wantErr)
+			}
+		}
+		s := string(x.utoa(10)) + "." + string(y.utoa(10))
+		var wantCount int
+		withoutBigInt(func() { want, _, wantCount, _ = nat(nil).scan(strings.NewReader(s), 0, true) })
+		got, _, count, _ := nat(nil).scan(strings.NewReader(s), 0, true)
+		check("scan with fraction", got, want)
+		if count != wantCount {
+			t.Errorf("scan with fraction gave count %d on BigInt. Want: %d.", count, wantCount)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+//go:build js
+
+package big
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// bigNat holds the helpers of nat.inc.js, or undefined if the JavaScript
+// engine doesn't support BigInt.
+var bigNat = js.Global.Get("$bigNat")
+
+// Below these lengths in Words, converting the operands to BigInt costs more
+// than the arithmetic of the Go implementation.
+const (
+	bigIntMulThreshold  = 20
+	bigIntDivThreshold  = 8
+	bigIntExpThreshold  = 2 // length of the exponent
+	bigIntItoaThreshold = 8
+	bigIntScanThreshold = 8 // length of the result
+)
+
+func useBigInt(n, threshold int) bool {
+	return n >= threshold && bigNat != js.Undefined
+}
+
+// bigInt returns x as a BigInt.
+func (x nat) bigInt() *js.Object {
+	s := js.InternalObject(x)
+	return bigNat.Call("fromWords", s.Get("$array"), s.Get("$offset"), len(x))
+}
+
+// setBigInt sets z to the value of the non-negative BigInt b.
+func (z nat) setBigInt(b *js.Object) nat {
+	words := bigNat.Call("toWords", b)
+	z = z.make(words.Length())
+	s := js.InternalObject(z)
+	s.Get("$array").Call("set", words, s.Get("$offset"))
+	return z.norm()
+}
+
+//gopherjs:keep-original
+func (z nat) mul(x, y nat) nat {
+	if !useBigInt(min(len(x), len(y)), bigIntMulThreshold) {
+		return z._gopherjs_original_mul(x, y)
+	}
+	if alias(z, x) || alias(z, y) {
+		z = nil // z is an alias for x or y - cannot reuse
+	}
+	return z.setBigInt(bigNat.Call("mul", x.bigInt(), y.bigInt()))
+}
+
+//gopherjs:keep-original
+func (z nat) sqr(x nat) nat {
+	if !useBigInt(len(x), bigIntMulThreshold) {
+		return z._gopherjs_original_sqr(x)
+	}
+	if alias(z, x) {
+		z = nil // z is an alias for x - cannot reuse
+	}
+	b := x.bigInt()
+	return z.setBigInt(bigNat.Call("mul", b, b))
+}
+
+//gopherjs:keep-original
+func (z nat) div(z2, u, v nat) (q, r nat) {
+	if !useBigInt(len(v), bigIntDivThreshold) || u.cmp(v) < 0 {
+		return z._gopherjs_original_div(z2, u, v)
+	}
+	if alias(z, z2) {
+		z = nil // q and r are different outputs
+	}
+	qr := bigNat.Call("divMod", u.bigInt(), v.bigInt())
+	return z.setBigInt(qr.Index(0)), z2.setBigInt(qr.Index(1))
+}
+
+//gopherjs:keep-original
+func (z nat) expNN(x, y, m nat, slow bool) nat {
+	// The slow path is kept as the reference for the tests.
+	if slow || len(m) == 0 || !useBigInt(len(y), bigIntExpThreshold) {
+		return z._gopherjs_original_expNN(x, y, m, slow)
+	}
+	if alias(z, x) || alias(z, y) || alias(z, m) {
+		// We cannot allow in-place modification of x or y.
+		z = nil
+	}
+	return z.setBigInt(bigNat.Call("expMod", x.bigInt(), y.bigInt(), m.bigInt()))
+}
+
+//gopherjs:keep-original
+func (x nat) itoa(neg bool, base int) []byte {
+	// BigInt uses the same digits as math/big up to base 36.
+	if base < 2 || base > 36 || !useBigInt(len(x), bigIntItoaThreshold) {
+		return x._gopherjs_original_itoa(neg, base)
+	}
+	s := bigNat.Call("toString", x.bigInt(), base).String()
+	if neg {
+		s = "-" + s
+	}
+	return []byte(s)
+}
+
+// scan is the upstream implementation, except that it collects the digits
+// before adding them to the result, so that long numbers in the bases BigInt
+// can parse are converted at once instead of one Word of digits at a time.
+func (z nat) scan(r io.ByteScanner, base int, fracOk bool) (res nat, b, count int, err error) {
+	// reject invalid bases
+	baseOk := base == 0 ||
+		!fracOk && 2 <= base && base <= MaxBase ||
+		fracOk && (base == 2 || base == 8 || base == 10 || base == 16)
+	if !baseOk {
+		panic(fmt.Sprintf("invalid number base %d", base))
+	}
+
+	// prev encodes the previously seen char: it is one
+	// of '_', '0' (a digit), or '.' (anything else). A
+	// valid separator '_' may only occur after a digit
+	// and if base == 0.
+	prev := '.'
+	invalSep := false
+
+	// one char look-ahead
+	ch, err := r.ReadByte()
+
+	// determine actual base
+	b, prefix := base, 0
+	if base == 0 {
+		// actual base is 10 unless there's a base prefix
+		b = 10
+		if err == nil && ch == '0' {
+			prev = '0'
+			count = 1
+			ch, err = r.ReadByte()
+			if err == nil {
+				// possibly one of 0b, 0B, 0o, 0O, 0x, 0X
+				switch ch {
+				case 'b', 'B':
+					b, prefix = 2, 'b'
+				case 'o', 'O':
+					b, prefix = 8, 'o'
+				case 'x', 'X':
+					b, prefix = 16, 'x'
+				default:
+					if !fracOk {
+						b, prefix = 8, '0'
+					}
+				}
+				if prefix != 0 {
+					count = 0 // prefix is not counted
+					if prefix != '0' {
+						ch, err = r.ReadByte()
+					}
+				}
+			}
+		}
+	}
+
+	// convert string
+	b1 := Word(b)
+	var digits []byte // values of the digits
+	dp := -1          // position of decimal point
+	for err == nil {
+		if ch == '.' && fracOk {
+			fracOk = false
+			if prev == '_' {
+				invalSep = true
+			}
+			prev = '.'
+			dp = count
+		} else if ch == '_' && base == 0 {
+			if prev != '0' {
+				invalSep = true
+			}
+			prev = '_'
+		} else {
+			// convert rune into digit value d1
+			var d1 Word
+			switch {
+			case '0' <= ch && ch <= '9':
+				d1 = Word(ch - '0')
+			case 'a' <= ch && ch <= 'z':
+				d1 = Word(ch - 'a' + 10)
+			case 'A' <= ch && ch <= 'Z':
+				if b <= maxBaseSmall {
+					d1 = Word(ch - 'A' + 10)
+				} else {
+					d1 = Word(ch - 'A' + maxBaseSmall)
+				}
+			default:
+				d1 = MaxBase + 1
+			}
+			if d1 >= b1 {
+				r.UnreadByte() // ch does not belong to number anymore
+				break
+			}
+			prev = '0'
+			count++
+			digits = append(digits, byte(d1))
+		}
+
+		ch, err = r.ReadByte()
+	}
+
+	if err == io.EOF {
+		err = nil
+	}
+
+	// other errors take precedence over invalid separators
+	if err == nil && (invalSep || prev == '_') {
+		err = errInvalSep
+	}
+
+	if count == 0 {
+		// no digits found
+		if prefix == '0' {
+			// there was only the octal prefix 0 (possibly followed by separators and digits > 7);
+			// interpret as decimal 0
+			return z[:0], 10, 1, err
+		}
+		err = errNoDigits // fall through; result will be 0
+	}
+
+	res = z.setDigits(digits, b1)
+
+	// adjust count for fraction, if any
+	if dp >= 0 {
+		// 0 <= dp <= count
+		count = dp - count
+	}
+
+	return
+}
+
+// setDigits sets z to the value of the digits in base b, which are given most
+// significant first.
+func (z nat) setDigits(digits []byte, b Word) nat {
+	bn, n := maxPow(b) // at most n digits in base b fit into Word
+	if (b == 2 || b == 8 || b == 10 || b == 16) && useBigInt(len(digits)/n, bigIntScanThreshold) {
+		return z.setBigInt(bigNat.Call("parse", digits, int(b)))
+	}
+
+	// Collect digits in groups of at most n digits in di and then use
+	// mulAddWW for every such group to add them to the result.
+	z = z[:0]
+	di := Word(0) // 0 <= di < b**i < bn
+	i := 0        // 0 <= i < n
+	for _, d := range digits {
+		di = di*b + Word(d)
+		i++
+		// if di is "full", add it to the result
+		if i == n {
+			z = z.mulAddWW(z, bn, di)
+			di = 0
+			i = 0
+		}
+	}
+	// add remaining digits to result
+	if i > 0 {
+		z = z.mulAddWW(z, pow(b, i), di)
+	}
+	return z.norm()
+}
@@ -0,0 +1,70 @@
+// Helpers for the math/big natives, which implement the arithmetic of large
+// nats on BigInt. Nats are passed as the backing Uint32Array of the slice,
+// the offset and the length. Engines without BigInt leave $bigNat undefined
+// and math/big uses the Go implementation.
+$global.$bigNat = undefined; // math/big reads it as a global variable
+if (typeof BigInt === "function") {
+    const wordDigits = 8; // hexadecimal digits per 32-bit Word
+
+    $global.$bigNat = {
+        fromWords(array, offset, length) {
+            if (length === 0) {
+                return 0n;
+            }
+            const parts = new Array(length);
+            for (let i = 0; i < length; i++) {
+                parts[i] = array[offset + length - 1 - i].toString(16).padStart(wordDigits, "0");
+            }
+            return BigInt("0x" + parts.join(""));
+        },
+
+        toWords(x) {
+            const hex = x.toString(16);
+            const words = new Uint32Array(Math.ceil(hex.length / wordDigits));
+            for (let i = 0, end = hex.length; end > 0; i++, end -= wordDigits) {
+                words[i] = parseInt(hex.slice(Math.max(0, end - wordDigits), end), 16);
+            }
+            return words;
+        },
+
+        mul(x, y) {
+            return x * y;
+        },
+
+        divMod(u, v) {
+            return [u / v, u % v];
+        },
+
+        // expMod returns x**y mod m using a fixed window of 4 bits.
+        expMod(x, y, m) {
+            const table = [1n % m, x % m];
+            for (let i = 2; i < 16; i++) {
+                table[i] = table[i - 1] * table[1] % m;
+            }
+            let z = table[0];
+            for (const digit of y.toString(16)) {
+                z = z * z % m;
+                z = z * z % m;
+                z = z * z % m;
+                z = z * z % m;
+                z = z * table[parseInt(digit, 16)] % m;
+            }
+            return z;
+        },
+
+        toString(x, base) {
+            return x.toString(base);
+        },
+
+        // parse returns the value of the digits in base 2, 8, 10 or 16, given
+        // as a Uint8Array of their values.
+        parse(digits, base) {
+            const chars = new Array(digits.length);
+            for (let i = 0; i < digits.length; i++) {
+                chars[i] = digits[i].toString(16);
+            }
+            const prefix = { 2: "0b", 8: "0o", 10: "", 16: "0x" }[base];
+            return BigInt(prefix + chars.join(""));
+        },
+    };
+}
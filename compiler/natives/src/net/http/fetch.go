@@ -0,0 +1,304 @@
+//go:build js
+
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// RoundTrip implements the RoundTripper interface using the WHATWG Fetch API.
+//
+// Unlike upstream, the request is aborted whenever its context is done, even
+// while the response body is being read. Request bodies of unknown length are
+// streamed where fetch() supports it, while the others are read in full before
+// sending, because Chromium only streams request bodies over HTTP/2.
+func (t *Transport) RoundTrip(req *Request) (*Response, error) {
+	// The Transport has a documented contract that states that if the DialContext or
+	// DialTLSContext functions are set, they will be used to set up the connections.
+	// If they aren't set then the documented contract is to use Dial or DialTLS, even
+	// though they are deprecated. Therefore, if any of these are set, we should obey
+	// the contract and dial using the regular round-trip instead. Otherwise, we'll try
+	// to fall back on the Fetch API, unless it's not available.
+	if t.Dial != nil || t.DialContext != nil || t.DialTLS != nil || t.DialTLSContext != nil || jsFetchMissing {
+		return t.roundTrip(req)
+	}
+
+	opt := js.Global.Get("Object").New()
+	// See https://developer.mozilla.org/en-US/docs/Web/API/WindowOrWorkerGlobalScope/fetch
+	// for options available.
+	opt.Set("method", req.Method)
+	opt.Set("credentials", "same-origin")
+	if h := req.Header.Get(jsFetchCreds); h != "" {
+		opt.Set("credentials", h)
+		req.Header.Del(jsFetchCreds)
+	}
+	if h := req.Header.Get(jsFetchMode); h != "" {
+		opt.Set("mode", h)
+		req.Header.Del(jsFetchMode)
+	}
+	if h := req.Header.Get(jsFetchRedirect); h != "" {
+		opt.Set("redirect", h)
+		req.Header.Del(jsFetchRedirect)
+	}
+	headers := js.Global.Get("Headers").New()
+	for key, values := range req.Header {
+		for _, value := range values {
+			headers.Call("append", key, value)
+		}
+	}
+	opt.Set("headers", headers)
+
+	// Some browsers don't support AbortController, in which case the request
+	// keeps going after being canceled, but its result is ignored.
+	abort := func() {}
+	if ac := js.Global.Get("AbortController"); ac != js.Undefined {
+		ac = ac.New()
+		opt.Set("signal", ac.Get("signal"))
+		abort = func() { ac.Call("abort") }
+	}
+
+	closeBody := func() {}
+	if req.Body != nil && req.Body != NoBody {
+		if req.ContentLength <= 0 && req.Method != "GET" && req.Method != "HEAD" && fetchStreamsRequests() {
+			body := &fetchRequestBody{body: req.Body}
+			opt.Set("body", body.stream())
+			opt.Set("duplex", "half")
+			closeBody = body.close
+		} else {
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				req.Body.Close() // RoundTrip must always close the body, including on errors.
+				return nil, err
+			}
+			req.Body.Close()
+			if len(body) != 0 {
+				opt.Set("body", body)
+			}
+		}
+	}
+
+	cancel := watchCancel(req, abort)
+	respCh := make(chan *js.Object, 1)
+	errCh := make(chan error, 1)
+	js.Global.Call("fetch", req.URL.String(), opt).Call("then",
+		func(result *js.Object) { respCh <- result },
+		func(err *js.Object) { errCh <- fmt.Errorf("net/http: fetch() failed: %s", err.Get("message").String()) },
+	)
+	var result *js.Object
+	select {
+	case result = <-respCh:
+	case err := <-errCh:
+		cancel.finish()
+		closeBody()
+		return nil, err
+	case <-cancel.aborted:
+		closeBody()
+		return nil, cancel.err
+	}
+
+	header := Header{}
+	// https://developer.mozilla.org/en-US/docs/Web/API/Headers/entries
+	headersIt := result.Get("headers").Call("entries")
+	for {
+		n := headersIt.Call("next")
+		if n.Get("done").Bool() {
+			break
+		}
+		pair := n.Get("value")
+		key, value := pair.Index(0).String(), pair.Index(1).String()
+		ck := CanonicalHeaderKey(key)
+		header[ck] = append(header[ck], value)
+	}
+
+	contentLength := int64(-1) // If the response length is not declared, set it to -1.
+	if clHeader := header.Get("Content-Length"); clHeader != "" {
+		cl, err := strconv.ParseInt(clHeader, 10, 64)
+		if err != nil {
+			abort()
+			cancel.finish()
+			return nil, fmt.Errorf("net/http: ill-formed Content-Length header: %v", err)
+		}
+		if cl < 0 {
+			// Content-Length values less than 0 are invalid.
+			// See: https://datatracker.ietf.org/doc/html/rfc2616/#section-14.13
+			abort()
+			cancel.finish()
+			return nil, fmt.Errorf("net/http: invalid Content-Length header: %q", clHeader)
+		}
+		contentLength = cl
+	}
+
+	body := &fetchBody{cancel: cancel}
+	// The body is undefined when the browser does not support streaming response bodies (Firefox),
+	// and null in certain error cases, i.e. when the request is blocked because of CORS settings.
+	if b := result.Get("body"); b != js.Undefined && b != nil {
+		body.reader = b.Call("getReader")
+	} else {
+		// Fall back to using ArrayBuffer
+		// https://developer.mozilla.org/en-US/docs/Web/API/Body/arrayBuffer
+		body.array = result.Call("arrayBuffer")
+	}
+
+	code := result.Get("status").Int()
+	return &Response{
+		Status:        fmt.Sprintf("%d %s", code, StatusText(code)),
+		StatusCode:    code,
+		Header:        header,
+		ContentLength: contentLength,
+		Body:          body,
+		Request:       req,
+	}, nil
+}
+
+var (
+	fetchStreamsOnce sync.Once
+	fetchStreams     bool
+)
+
+// fetchStreamsRequests reports whether fetch() accepts a ReadableStream as
+// a request body. Only implementations supporting streams read the duplex
+// option, while the others turn the stream into a string.
+func fetchStreamsRequests() bool {
+	fetchStreamsOnce.Do(func() {
+		defer func() {
+			if recover() != nil {
+				fetchStreams = false
+			}
+		}()
+		if js.Global.Get("ReadableStream") == js.Undefined || js.Global.Get("Request") == js.Undefined {
+			return
+		}
+		duplexRead := false
+		init := js.Global.Get("Object").New()
+		init.Set("method", "POST")
+		init.Set("body", js.Global.Get("ReadableStream").New())
+		js.Global.Get("Object").Call("defineProperty", init, "duplex", js.M{
+			"get": func() string {
+				duplexRead = true
+				return "half"
+			},
+		})
+		hasContentType := js.Global.Get("Request").New("http://localhost/", init).Get("headers").Call("has", "Content-Type").Bool()
+		fetchStreams = duplexRead && !hasContentType
+	})
+	return fetchStreams
+}
+
+// fetchRequestBody sends a request body to fetch() as a ReadableStream.
+type fetchRequestBody struct {
+	body   io.ReadCloser
+	closed bool
+}
+
+// stream returns a ReadableStream, which reads the body whenever fetch() asks
+// for more data.
+func (b *fetchRequestBody) stream() *js.Object {
+	return js.Global.Get("ReadableStream").New(js.M{
+		"pull": func(controller *js.Object) *js.Object {
+			return js.Global.Get("Promise").New(func(resolve *js.Object) {
+				go func() {
+					buf := make([]byte, 32<<10)
+					n, err := b.body.Read(buf)
+					if n > 0 && !b.closed {
+						controller.Call("enqueue", buf[:n])
+					}
+					switch {
+					case b.closed:
+					case err == io.EOF:
+						b.close()
+						controller.Call("close")
+					case err != nil:
+						b.close()
+						controller.Call("error", js.Global.Get("Error").New(err.Error()))
+					}
+					resolve.Invoke()
+				}()
+			})
+		},
+		"cancel": func() {
+			b.close()
+		},
+	})
+}
+
+// close closes the body unless it is already closed.
+func (b *fetchRequestBody) close() {
+	if !b.closed {
+		b.closed = true
+		b.body.Close()
+	}
+}
+
+// fetchBody is the body of a response returned by fetch(), which is read as it
+// arrives.
+type fetchBody struct {
+	reader  *js.Object // ReadableStreamDefaultReader of the body, if supported
+	array   *js.Object // promise of the whole body otherwise
+	cancel  *jsCanceler
+	pending []byte
+	err     error // sticky read error
+}
+
+func (b *fetchBody) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	if len(b.pending) == 0 {
+		valueCh := make(chan *js.Object, 1)
+		errCh := make(chan error, 1)
+		onError := func(err *js.Object) { errCh <- errors.New(err.Get("message").String()) }
+		switch {
+		case b.reader != nil:
+			b.reader.Call("read").Call("then", func(result *js.Object) {
+				if result.Get("done").Bool() {
+					errCh <- io.EOF
+					return
+				}
+				valueCh <- result.Get("value")
+			}, onError)
+		case b.array != nil:
+			b.array.Call("then", func(buf *js.Object) { valueCh <- buf }, onError)
+			b.array = nil
+		default:
+			errCh <- io.EOF
+		}
+		select {
+		case value := <-valueCh:
+			b.pending = js.Global.Get("Uint8Array").New(value).Interface().([]byte)
+		case err := <-errCh:
+			b.fail(err)
+			return 0, b.err
+		case <-b.cancel.aborted:
+			b.fail(b.cancel.err)
+			return 0, b.err
+		}
+	}
+	n := copy(p, b.pending)
+	b.pending = b.pending[n:]
+	return n, nil
+}
+
+// fail makes err the sticky read error, unless there already is one.
+func (b *fetchBody) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+	b.cancel.finish()
+}
+
+func (b *fetchBody) Close() error {
+	if b.err == nil && b.reader != nil {
+		// This ignores any error returned from cancel method, as most users
+		// ignore errors from resp.Body.Close() anyway.
+		b.reader.Call("cancel")
+	}
+	b.fail(errClosed)
+	b.pending = nil
+	return nil
+}
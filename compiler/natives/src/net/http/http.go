@@ -32,6 +32,44 @@ func (noTransport) RoundTrip(req *Request) (*Response, error) {
 	return nil, errors.New("net/http: neither of Fetch nor XMLHttpRequest APIs is available")
 }
 
+// jsCanceler aborts a request made with a browser API once the request
+// context is done or the deprecated Request.Cancel channel is closed.
+type jsCanceler struct {
+	err     error         // why the request was aborted
+	aborted chan struct{} // closed once the request is aborted
+	done    chan struct{} // closed once the request no longer needs watching
+}
+
+// watchCancel starts watching req, calling abort if it gets canceled before
+// finish is called.
+func watchCancel(req *Request, abort func()) *jsCanceler {
+	c := &jsCanceler{aborted: make(chan struct{}), done: make(chan struct{})}
+	go func() {
+		select {
+		case <-req.Context().Done():
+			c.err = req.Context().Err()
+		case <-req.Cancel:
+			c.err = errRequestCanceled
+		case <-c.done:
+			return
+		}
+		close(c.aborted)
+		abort()
+	}()
+	return c
+}
+
+// finish stops watching the request.
+func (c *jsCanceler) finish() {
+	if done := c.done; done != nil {
+		c.done = nil
+		close(done)
+	}
+}
+
+// XHRTransport is a RoundTripper, which uses the XMLHttpRequest API. Response
+// bodies are streamed as they arrive, while request bodies are read in full
+// before sending, because XMLHttpRequest can't send streams.
 type XHRTransport struct {
 	inflight map[*Request]*js.Object
 }
@@ -43,45 +81,45 @@ func (t *XHRTransport) RoundTrip(req *Request) (*Response, error) {
 		t.inflight = map[*Request]*js.Object{}
 	}
 	t.inflight[req] = xhr
-	defer delete(t.inflight, req)
-
-	respCh := make(chan *Response)
-	errCh := make(chan error)
 
-	xhr.Set("onload", func() {
-		header, _ := textproto.NewReader(bufio.NewReader(bytes.NewReader([]byte(xhr.Call("getAllResponseHeaders").String() + "\n")))).ReadMIMEHeader()
-		body := js.Global.Get("Uint8Array").New(xhr.Get("response")).Interface().([]byte)
-
-		contentLength := int64(-1)
-		switch req.Method {
-		case "HEAD":
-			if l, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64); err == nil {
-				contentLength = l
-			}
-		default:
-			contentLength = int64(len(body))
-		}
+	b := &xhrBody{xhr: xhr, changed: make(chan struct{})}
+	b.cancel = watchCancel(req, func() { xhr.Call("abort") })
+	b.release = func() {
+		delete(t.inflight, req)
+		b.cancel.finish()
+	}
 
-		respCh <- &Response{
-			Status:        xhr.Get("status").String() + " " + xhr.Get("statusText").String(),
-			StatusCode:    xhr.Get("status").Int(),
-			Header:        Header(header),
-			ContentLength: contentLength,
-			Body:          io.NopCloser(bytes.NewReader(body)),
-			Request:       req,
+	xhr.Set("onreadystatechange", func() {
+		if xhr.Get("readyState").Int() == 2 { // HEADERS_RECEIVED
+			b.headers = true
+			b.notify()
 		}
 	})
-
+	xhr.Set("onprogress", func() {
+		b.receive()
+	})
+	xhr.Set("onload", func() {
+		b.headers = true
+		b.receive()
+		b.eof = true
+		b.release()
+		b.notify()
+	})
 	xhr.Set("onerror", func(e *js.Object) {
-		errCh <- errors.New("net/http: XMLHttpRequest failed")
+		b.fail(errors.New("net/http: XMLHttpRequest failed"))
 	})
-
 	xhr.Set("onabort", func(e *js.Object) {
-		errCh <- errors.New("net/http: request canceled")
+		err := b.cancel.err
+		if err == nil {
+			err = errRequestCanceled
+		}
+		b.fail(err)
 	})
 
 	xhr.Call("open", req.Method, req.URL.String())
-	xhr.Set("responseType", "arraybuffer") // has to be after "open" until https://bugzilla.mozilla.org/show_bug.cgi?id=1110761 is resolved
+	// Receiving the response as text with each byte mapped to a single
+	// character is the only way to read it before the request is complete.
+	xhr.Call("overrideMimeType", "text/plain; charset=x-user-defined")
 	for key, values := range req.Header {
 		for _, value := range values {
 			xhr.Call("setRequestHeader", key, value)
@@ -93,18 +131,38 @@ func (t *XHRTransport) RoundTrip(req *Request) (*Response, error) {
 		body, err := io.ReadAll(req.Body)
 		if err != nil {
 			req.Body.Close() // RoundTrip must always close the body, including on errors.
+			b.release()
 			return nil, err
 		}
 		req.Body.Close()
 		xhr.Call("send", body)
 	}
 
-	select {
-	case resp := <-respCh:
-		return resp, nil
-	case err := <-errCh:
-		return nil, err
+	for !b.headers {
+		if b.err != nil {
+			return nil, b.err
+		}
+		<-b.changed
 	}
+
+	header, _ := textproto.NewReader(bufio.NewReader(bytes.NewReader([]byte(xhr.Call("getAllResponseHeaders").String() + "\n")))).ReadMIMEHeader()
+	contentLength := int64(-1)
+	// Browsers decode the content encoding, so Content-Length no longer
+	// matches the body unless the response is uncompressed.
+	if req.Method == "HEAD" || header.Get("Content-Encoding") == "" {
+		if l, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64); err == nil {
+			contentLength = l
+		}
+	}
+
+	return &Response{
+		Status:        xhr.Get("status").String() + " " + xhr.Get("statusText").String(),
+		StatusCode:    xhr.Get("status").Int(),
+		Header:        Header(header),
+		ContentLength: contentLength,
+		Body:          b,
+		Request:       req,
+	}, nil
 }
 
 func (t *XHRTransport) CancelRequest(req *Request) {
@@ -112,3 +170,72 @@ func (t *XHRTransport) CancelRequest(req *Request) {
 		xhr.Call("abort")
 	}
 }
+
+// xhrBody is the body of a response received by XMLHttpRequest, which is read
+// as it arrives.
+type xhrBody struct {
+	xhr     *js.Object
+	cancel  *jsCanceler
+	release func() // called once the request is complete or has failed
+
+	// changed is closed and replaced every time the state below changes.
+	changed chan struct{}
+	headers bool   // response headers have been received
+	buf     []byte // received data, which hasn't been read yet
+	offset  int    // number of bytes received so far
+	eof     bool
+	err     error
+}
+
+func (b *xhrBody) notify() {
+	changed := b.changed
+	b.changed = make(chan struct{})
+	close(changed)
+}
+
+// receive appends the response data received since the previous call to buf.
+func (b *xhrBody) receive() {
+	text := b.xhr.Get("responseText")
+	if text.Length() > b.offset {
+		// Each byte arrives as a character in either U+0000-U+007F or
+		// U+F780-U+F7FF range, whose low byte is the original one.
+		for _, r := range text.Call("substring", b.offset).String() {
+			b.buf = append(b.buf, byte(r))
+		}
+		b.offset = text.Length()
+	}
+	b.notify()
+}
+
+func (b *xhrBody) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+	b.release()
+	b.notify()
+}
+
+func (b *xhrBody) Read(p []byte) (int, error) {
+	for len(b.buf) == 0 {
+		switch {
+		case b.err != nil:
+			return 0, b.err
+		case b.eof:
+			return 0, io.EOF
+		}
+		<-b.changed
+	}
+	n := copy(p, b.buf)
+	b.buf = b.buf[n:]
+	return n, nil
+}
+
+func (b *xhrBody) Close() error {
+	pending := b.err == nil && !b.eof
+	b.fail(errClosed)
+	if pending {
+		b.xhr.Call("abort")
+	}
+	b.buf = nil
+	return nil
+}
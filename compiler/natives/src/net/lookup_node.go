@@ -0,0 +1,213 @@
+//go:build js
+
+package net
+
+import (
+	"context"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// Under Node.js name resolution is performed by the dns module. dns.lookup()
+// uses the system resolver, same as the cgo-based upstream resolver, while the
+// other lookups query DNS servers directly. Other environments keep the
+// upstream behavior of failing all lookups.
+
+// nodeDNS is the Node.js dns module, or nil if it is unavailable.
+var nodeDNS = requireNode("dns")
+
+// dnsCall invokes the Node.js dns function fn with args and waits for its
+// result. The name is used to report errors.
+func dnsCall(ctx context.Context, name string, fn string, args ...any) (*js.Object, error) {
+	type result struct{ value, err *js.Object }
+	done := make(chan result, 1)
+	args = append(args, js.InternalObject(func(err, value *js.Object) {
+		done <- result{value: value, err: err}
+	}))
+	pendingIO(1)
+	defer pendingIO(-1)
+	nodeDNS.Call(fn, args...)
+	select {
+	case r := <-done:
+		if !isNullish(r.err) {
+			return nil, nodeDNSError(name, r.err)
+		}
+		return r.value, nil
+	case <-ctx.Done():
+		return nil, &DNSError{Err: mapErr(ctx.Err()).Error(), Name: name, IsTimeout: ctx.Err() == context.DeadlineExceeded}
+	}
+}
+
+// nodeDNSError converts a Node.js dns error into *DNSError.
+func nodeDNSError(name string, err *js.Object) error {
+	switch err.Get("code").String() {
+	case "ENOTFOUND", "ENODATA":
+		return &DNSError{Err: errNoSuchHost.Error(), Name: name, IsNotFound: true}
+	case "ETIMEOUT":
+		return &DNSError{Err: "i/o timeout", Name: name, IsTimeout: true, IsTemporary: true}
+	}
+	return &DNSError{Err: err.Get("message").String(), Name: name}
+}
+
+// stringsOf converts a JavaScript array of strings to a slice.
+func stringsOf(a *js.Object) []string {
+	s := make([]string, a.Length())
+	for i := range s {
+		s[i] = a.Index(i).String()
+	}
+	return s
+}
+
+//gopherjs:keep-original
+func (r *Resolver) lookupHost(ctx context.Context, host string) (addrs []string, err error) {
+	if nodeDNS == nil {
+		return r._gopherjs_original_lookupHost(ctx, host)
+	}
+	ips, err := r.lookupIP(ctx, "ip", host)
+	if err != nil {
+		return nil, err
+	}
+	for _, ip := range ips {
+		addrs = append(addrs, ip.String())
+	}
+	return addrs, nil
+}
+
+//gopherjs:keep-original
+func (r *Resolver) lookupIP(ctx context.Context, network, host string) (addrs []IPAddr, err error) {
+	if nodeDNS == nil {
+		return r._gopherjs_original_lookupIP(ctx, network, host)
+	}
+	family := 0
+	switch network {
+	case "ip4":
+		family = 4
+	case "ip6":
+		family = 6
+	}
+	res, err := dnsCall(ctx, host, "lookup", host, js.M{"all": true, "family": family})
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < res.Length(); i++ {
+		ip, zone := splitHostZone(res.Index(i).Get("address").String())
+		addrs = append(addrs, IPAddr{IP: ParseIP(ip), Zone: zone})
+	}
+	return addrs, nil
+}
+
+//gopherjs:keep-original
+func (r *Resolver) lookupCNAME(ctx context.Context, name string) (cname string, err error) {
+	if nodeDNS == nil {
+		return r._gopherjs_original_lookupCNAME(ctx, name)
+	}
+	res, err := dnsCall(ctx, name, "resolveCname", name)
+	if dnsErr, ok := err.(*DNSError); ok && dnsErr.IsNotFound {
+		// The name has no CNAME record, so it is canonical itself, as long as
+		// it exists.
+		if _, err := r.lookupIP(ctx, "ip", name); err != nil {
+			return "", err
+		}
+		return absDomainName(name), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return absDomainName(res.Index(0).String()), nil
+}
+
+//gopherjs:keep-original
+func (r *Resolver) lookupSRV(ctx context.Context, service, proto, name string) (cname string, srvs []*SRV, err error) {
+	if nodeDNS == nil {
+		return r._gopherjs_original_lookupSRV(ctx, service, proto, name)
+	}
+	target := name
+	if service != "" || proto != "" {
+		target = "_" + service + "._" + proto + "." + name
+	}
+	res, err := dnsCall(ctx, target, "resolveSrv", target)
+	if err != nil {
+		return "", nil, err
+	}
+	for i := 0; i < res.Length(); i++ {
+		rec := res.Index(i)
+		srvs = append(srvs, &SRV{
+			Target:   absDomainName(rec.Get("name").String()),
+			Port:     uint16(rec.Get("port").Int()),
+			Priority: uint16(rec.Get("priority").Int()),
+			Weight:   uint16(rec.Get("weight").Int()),
+		})
+	}
+	byPriorityWeight(srvs).sort()
+	return absDomainName(target), srvs, nil
+}
+
+//gopherjs:keep-original
+func (r *Resolver) lookupMX(ctx context.Context, name string) (mxs []*MX, err error) {
+	if nodeDNS == nil {
+		return r._gopherjs_original_lookupMX(ctx, name)
+	}
+	res, err := dnsCall(ctx, name, "resolveMx", name)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < res.Length(); i++ {
+		rec := res.Index(i)
+		mxs = append(mxs, &MX{
+			Host: absDomainName(rec.Get("exchange").String()),
+			Pref: uint16(rec.Get("priority").Int()),
+		})
+	}
+	byPref(mxs).sort()
+	return mxs, nil
+}
+
+//gopherjs:keep-original
+func (r *Resolver) lookupNS(ctx context.Context, name string) (nss []*NS, err error) {
+	if nodeDNS == nil {
+		return r._gopherjs_original_lookupNS(ctx, name)
+	}
+	res, err := dnsCall(ctx, name, "resolveNs", name)
+	if err != nil {
+		return nil, err
+	}
+	for _, host := range stringsOf(res) {
+		nss = append(nss, &NS{Host: absDomainName(host)})
+	}
+	return nss, nil
+}
+
+//gopherjs:keep-original
+func (r *Resolver) lookupTXT(ctx context.Context, name string) (txts []string, err error) {
+	if nodeDNS == nil {
+		return r._gopherjs_original_lookupTXT(ctx, name)
+	}
+	res, err := dnsCall(ctx, name, "resolveTxt", name)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < res.Length(); i++ {
+		// Long TXT records are split into multiple strings.
+		txt := ""
+		for _, s := range stringsOf(res.Index(i)) {
+			txt += s
+		}
+		txts = append(txts, txt)
+	}
+	return txts, nil
+}
+
+//gopherjs:keep-original
+func (r *Resolver) lookupAddr(ctx context.Context, addr string) (ptrs []string, err error) {
+	if nodeDNS == nil {
+		return r._gopherjs_original_lookupAddr(ctx, addr)
+	}
+	res, err := dnsCall(ctx, addr, "reverse", addr)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range stringsOf(res) {
+		ptrs = append(ptrs, absDomainName(name))
+	}
+	return ptrs, nil
+}
@@ -0,0 +1,624 @@
+//go:build js
+
+package net
+
+import (
+	"context"
+	"errors"
+	"internal/poll"
+	"io"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// Under Node.js stream sockets are backed by the net module. In other
+// environments the package falls back to the upstream in-memory fake
+// networking, which only allows a program to talk to itself.
+
+// nodeNet is the Node.js net module, or nil if it is unavailable.
+var nodeNet = requireNode("net")
+
+// requireNode loads a Node.js module, returning nil if it is unavailable.
+func requireNode(name string) (m *js.Object) {
+	require := js.Global.Get("require")
+	if require == js.Undefined {
+		return nil
+	}
+	defer func() {
+		if recover() != nil {
+			m = nil
+		}
+	}()
+	return require.Invoke(name)
+}
+
+// pendingIO adjusts the number of open Node.js handles and outstanding requests,
+// which may awaken goroutines blocked on them. While there are any, the deadlock
+// detector must not report the program as stuck.
+func pendingIO(delta int) {
+	js.Global.Set("$exportedFunctions", js.Global.Get("$exportedFunctions").Int()+delta)
+}
+
+// isNullish returns true if o is JavaScript null or undefined.
+func isNullish(o *js.Object) bool {
+	return o == nil || o == js.Undefined
+}
+
+// Network file descriptor.
+type netFD struct {
+	r        *bufferedPipe
+	w        *bufferedPipe
+	incoming chan *netFD
+
+	closedMu sync.Mutex
+	closed   bool
+
+	// immutable until Close
+	listener bool
+	family   int
+	sotype   int
+	net      string
+	laddr    Addr
+	raddr    Addr
+
+	// node is the Node.js socket or server backing this descriptor, nil if the
+	// in-memory fake networking is used.
+	node *nodeSocket
+
+	// unused
+	pfd         poll.FD
+	isConnected bool // handshake completed or use of association with peer
+}
+
+//gopherjs:keep-original
+func socket(ctx context.Context, net string, family, sotype, proto int, ipv6only bool, laddr, raddr sockaddr, ctrlCtxFn func(context.Context, string, string, syscall.RawConn) error) (*netFD, error) {
+	if nodeNet == nil {
+		return _gopherjs_original_socket(ctx, net, family, sotype, proto, ipv6only, laddr, raddr, ctrlCtxFn)
+	}
+	if sotype != syscall.SOCK_STREAM {
+		return nil, syscall.EPROTONOSUPPORT
+	}
+	fd := &netFD{family: family, sotype: sotype, net: net}
+	var err error
+	if laddr != nil && raddr == nil {
+		err = fd.listenNode(laddr)
+	} else {
+		err = fd.dialNode(ctx, laddr, raddr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return fd, nil
+}
+
+// dialNode connects fd to raddr with net.createConnection().
+func (fd *netFD) dialNode(ctx context.Context, laddr, raddr sockaddr) error {
+	opts := js.M{"allowHalfOpen": true}
+	switch ra := raddr.(type) {
+	case *TCPAddr:
+		opts["host"] = nodeHost(ra.IP, ra.Zone, "localhost")
+		opts["port"] = ra.Port
+		if la, ok := laddr.(*TCPAddr); ok && la != nil {
+			if la.IP != nil {
+				opts["localAddress"] = nodeHost(la.IP, la.Zone, "")
+			}
+			if la.Port != 0 {
+				opts["localPort"] = la.Port
+			}
+		}
+	case *UnixAddr:
+		opts["path"] = ra.Name
+	default:
+		return syscall.EAFNOSUPPORT
+	}
+
+	s := newNodeSocket(nodeNet.Call("createConnection", opts))
+	s.hold()
+	defer s.unhold()
+	for !s.connected {
+		if s.err != nil {
+			err := s.err
+			s.close()
+			return os.NewSyscallError("connect", err)
+		}
+		changed := s.changed
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			s.close()
+			return mapErr(ctx.Err())
+		}
+	}
+
+	fd.node = s
+	fd.isConnected = true
+	if ra, ok := raddr.(*UnixAddr); ok {
+		fd.laddr = &UnixAddr{Net: fd.net}
+		fd.raddr = ra
+	} else {
+		fd.laddr = nodeTCPAddr(s.sock.Get("localAddress"), s.sock.Get("localPort"))
+		fd.raddr = nodeTCPAddr(s.sock.Get("remoteAddress"), s.sock.Get("remotePort"))
+	}
+	return nil
+}
+
+// listenNode starts a net.Server listening on laddr.
+func (fd *netFD) listenNode(laddr sockaddr) error {
+	opts := js.M{}
+	switch la := laddr.(type) {
+	case *TCPAddr:
+		opts["port"] = la.Port
+		if host := nodeHost(la.IP, la.Zone, ""); host != "" {
+			opts["host"] = host
+		} else if fd.net == "tcp4" {
+			opts["host"] = "0.0.0.0"
+		}
+	case *UnixAddr:
+		opts["path"] = la.Name
+	default:
+		return syscall.EAFNOSUPPORT
+	}
+
+	s := newNodeServer(nodeNet.Call("createServer", js.M{"allowHalfOpen": true}))
+	s.server.Call("listen", opts)
+	for !s.connected {
+		if s.err != nil {
+			err := s.err
+			s.close()
+			return os.NewSyscallError("bind", err)
+		}
+		s.wait(time.Time{})
+	}
+
+	fd.node = s
+	fd.listener = true
+	if la, ok := laddr.(*UnixAddr); ok {
+		fd.laddr = &UnixAddr{Name: la.Name, Net: fd.net}
+	} else {
+		addr := s.server.Call("address")
+		fd.laddr = nodeTCPAddr(addr.Get("address"), addr.Get("port"))
+	}
+	return nil
+}
+
+// nodeHost formats ip as a host name for Node.js. A nil ip is replaced by def.
+func nodeHost(ip IP, zone string, def string) string {
+	if ip == nil {
+		return def
+	}
+	if zone != "" {
+		return ip.String() + "%" + zone
+	}
+	return ip.String()
+}
+
+// nodeTCPAddr converts a Node.js socket address into *TCPAddr.
+func nodeTCPAddr(addr, port *js.Object) Addr {
+	if isNullish(addr) {
+		return nil
+	}
+	host, zone := splitHostZone(addr.String())
+	return &TCPAddr{IP: ParseIP(host), Port: port.Int(), Zone: zone}
+}
+
+//gopherjs:keep-original
+func (fd *netFD) Read(p []byte) (n int, err error) {
+	if fd.node == nil {
+		return fd._gopherjs_original_Read(p)
+	}
+	return fd.node.read(p)
+}
+
+//gopherjs:keep-original
+func (fd *netFD) Write(p []byte) (nn int, err error) {
+	if fd.node == nil {
+		return fd._gopherjs_original_Write(p)
+	}
+	return fd.node.write(p)
+}
+
+//gopherjs:keep-original
+func (fd *netFD) Close() error {
+	if fd.node == nil {
+		return fd._gopherjs_original_Close()
+	}
+	return fd.node.close()
+}
+
+//gopherjs:keep-original
+func (fd *netFD) closeRead() error {
+	if fd.node == nil {
+		return fd._gopherjs_original_closeRead()
+	}
+	fd.node.closeRead()
+	return nil
+}
+
+//gopherjs:keep-original
+func (fd *netFD) closeWrite() error {
+	if fd.node == nil {
+		return fd._gopherjs_original_closeWrite()
+	}
+	fd.node.sock.Call("end")
+	return nil
+}
+
+//gopherjs:keep-original
+func (fd *netFD) accept() (*netFD, error) {
+	if fd.node == nil {
+		return fd._gopherjs_original_accept()
+	}
+	s, err := fd.node.accept()
+	if err != nil {
+		return nil, err
+	}
+	c := &netFD{family: fd.family, sotype: fd.sotype, net: fd.net, node: s, isConnected: true}
+	if _, ok := fd.laddr.(*UnixAddr); ok {
+		c.laddr = fd.laddr
+		c.raddr = &UnixAddr{Net: fd.net}
+	} else {
+		c.laddr = nodeTCPAddr(s.sock.Get("localAddress"), s.sock.Get("localPort"))
+		c.raddr = nodeTCPAddr(s.sock.Get("remoteAddress"), s.sock.Get("remotePort"))
+	}
+	return c, nil
+}
+
+//gopherjs:keep-original
+func (fd *netFD) SetDeadline(t time.Time) error {
+	if fd.node == nil {
+		return fd._gopherjs_original_SetDeadline(t)
+	}
+	fd.node.readDeadline = t
+	fd.node.writeDeadline = t
+	fd.node.notify()
+	return nil
+}
+
+//gopherjs:keep-original
+func (fd *netFD) SetReadDeadline(t time.Time) error {
+	if fd.node == nil {
+		return fd._gopherjs_original_SetReadDeadline(t)
+	}
+	fd.node.readDeadline = t
+	fd.node.notify()
+	return nil
+}
+
+//gopherjs:keep-original
+func (fd *netFD) SetWriteDeadline(t time.Time) error {
+	if fd.node == nil {
+		return fd._gopherjs_original_SetWriteDeadline(t)
+	}
+	fd.node.writeDeadline = t
+	fd.node.notify()
+	return nil
+}
+
+func setNoDelay(fd *netFD, noDelay bool) error {
+	if fd.node == nil || fd.node.sock == nil {
+		return syscall.ENOPROTOOPT
+	}
+	fd.node.sock.Call("setNoDelay", noDelay)
+	return nil
+}
+
+func setKeepAlive(fd *netFD, keepalive bool) error {
+	if fd.node == nil || fd.node.sock == nil {
+		return syscall.ENOPROTOOPT
+	}
+	fd.node.sock.Call("setKeepAlive", keepalive)
+	return nil
+}
+
+func setKeepAlivePeriod(fd *netFD, d time.Duration) error {
+	if fd.node == nil || fd.node.sock == nil {
+		return syscall.ENOPROTOOPT
+	}
+	fd.node.sock.Call("setKeepAlive", true, d.Milliseconds())
+	return nil
+}
+
+// maxBuffered is the amount of received data a socket buffers before it stops
+// reading from the network until the program catches up.
+const maxBuffered = 64 << 10
+
+// nodeSocket adapts the event-based Node.js net.Socket and net.Server APIs to
+// the blocking netFD interface.
+//
+// Event listeners run outside of any goroutine and must not block, so they
+// only update the state and wake up the goroutines waiting for it.
+type nodeSocket struct {
+	sock   *js.Object // net.Socket, nil for listeners.
+	server *js.Object // net.Server, nil for connections.
+
+	// changed is closed and replaced every time the state below changes.
+	changed chan struct{}
+
+	connected bool          // Connection established, or server listening.
+	buf       []byte        // Data received, but not read yet.
+	paused    bool          // Reading is paused, because buf is full.
+	full      bool          // Written data fills the Node.js buffer until "drain".
+	eof       bool          // The peer has finished sending data.
+	incoming  []*nodeSocket // Connections not accepted yet.
+	err       error         // Error reported by Node.js.
+	closed    bool          // Close() has been called.
+	waiters   int           // Number of goroutines blocked on the socket.
+
+	readDeadline  time.Time
+	writeDeadline time.Time
+}
+
+func newNodeSocket(sock *js.Object) *nodeSocket {
+	s := &nodeSocket{sock: sock, changed: make(chan struct{})}
+	sock.Call("unref")
+	sock.Call("on", "connect", js.InternalObject(func() {
+		s.connected = true
+		s.notify()
+	}))
+	sock.Call("on", "data", js.InternalObject(func(chunk *js.Object) {
+		s.buf = append(s.buf, js.Global.Get("Uint8Array").New(chunk).Interface().([]byte)...)
+		if len(s.buf) >= maxBuffered && !s.paused {
+			s.paused = true
+			sock.Call("pause")
+		}
+		s.notify()
+	}))
+	sock.Call("on", "drain", js.InternalObject(func() {
+		s.full = false
+		s.notify()
+	}))
+	sock.Call("on", "end", js.InternalObject(func() {
+		s.eof = true
+		s.notify()
+	}))
+	sock.Call("on", "error", js.InternalObject(func(err *js.Object) {
+		s.err = nodeError(err)
+		s.notify()
+	}))
+	sock.Call("on", "close", js.InternalObject(func() {
+		s.eof = true
+		s.notify()
+	}))
+	return s
+}
+
+func newNodeServer(server *js.Object) *nodeSocket {
+	s := &nodeSocket{server: server, changed: make(chan struct{})}
+	server.Call("unref")
+	server.Call("on", "listening", js.InternalObject(func() {
+		s.connected = true
+		s.notify()
+	}))
+	server.Call("on", "connection", js.InternalObject(func(sock *js.Object) {
+		c := newNodeSocket(sock)
+		c.connected = true
+		s.incoming = append(s.incoming, c)
+		s.notify()
+	}))
+	server.Call("on", "error", js.InternalObject(func(err *js.Object) {
+		s.err = nodeError(err)
+		s.notify()
+	}))
+	return s
+}
+
+// notify wakes up all goroutines waiting for the socket state to change.
+//
+// When called from a Node.js event listener, the woken goroutines run before
+// close() returns, so the channel must be replaced first.
+func (s *nodeSocket) notify() {
+	changed := s.changed
+	s.changed = make(chan struct{})
+	close(changed)
+}
+
+// hold marks a goroutine as blocked on the socket. Sockets don't keep the
+// Node.js process running on their own, same as open connections don't prevent
+// a Go program from exiting. But while a goroutine waits for the socket, its
+// events may wake it up, so the process must keep running and deadlock
+// detection must be suppressed.
+func (s *nodeSocket) hold() {
+	s.waiters++
+	if s.waiters == 1 {
+		s.handle().Call("ref")
+		pendingIO(1)
+	}
+}
+
+// unhold reverts the effect of hold.
+func (s *nodeSocket) unhold() {
+	s.waiters--
+	if s.waiters == 0 {
+		s.handle().Call("unref")
+		pendingIO(-1)
+	}
+}
+
+// handle returns the underlying Node.js object.
+func (s *nodeSocket) handle() *js.Object {
+	if s.sock != nil {
+		return s.sock
+	}
+	return s.server
+}
+
+// wait blocks until the socket state changes or the deadline expires.
+func (s *nodeSocket) wait(deadline time.Time) error {
+	s.hold()
+	defer s.unhold()
+	changed := s.changed
+	if deadline.IsZero() {
+		<-changed
+		return nil
+	}
+	d := time.Until(deadline)
+	if d <= 0 {
+		return os.ErrDeadlineExceeded
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-changed:
+		return nil
+	case <-t.C:
+		return os.ErrDeadlineExceeded
+	}
+}
+
+// expired returns true if deadline is set and has passed.
+func expired(deadline time.Time) bool {
+	return !deadline.IsZero() && !time.Now().Before(deadline)
+}
+
+func (s *nodeSocket) read(p []byte) (int, error) {
+	for {
+		switch {
+		case s.closed:
+			return 0, ErrClosed
+		case expired(s.readDeadline):
+			return 0, os.ErrDeadlineExceeded
+		case len(p) == 0:
+			return 0, nil
+		case len(s.buf) > 0:
+			n := copy(p, s.buf)
+			s.buf = s.buf[n:]
+			if s.paused && len(s.buf) < maxBuffered {
+				s.paused = false
+				s.sock.Call("resume")
+			}
+			return n, nil
+		case s.err != nil:
+			return 0, os.NewSyscallError("read", s.err)
+		case s.eof:
+			return 0, io.EOF
+		}
+		if err := s.wait(s.readDeadline); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// write queues p to be sent by Node.js and returns without waiting for it to
+// be written, so that writers holding locks aren't preempted. If the Node.js
+// buffer is full, it first waits for it to be drained. Errors of earlier
+// writes are reported by the following calls.
+func (s *nodeSocket) write(p []byte) (int, error) {
+	var timeout <-chan time.Time
+	if s.full && !s.writeDeadline.IsZero() {
+		t := time.NewTimer(time.Until(s.writeDeadline))
+		defer t.Stop()
+		timeout = t.C
+	}
+	for {
+		switch {
+		case s.closed:
+			return 0, ErrClosed
+		case expired(s.writeDeadline):
+			return 0, os.ErrDeadlineExceeded
+		case s.err != nil:
+			return 0, os.NewSyscallError("write", s.err)
+		case len(p) == 0:
+			return 0, nil
+		}
+		if !s.full {
+			break
+		}
+		changed := s.changed
+		s.hold()
+		select {
+		case <-changed:
+		case <-timeout:
+		}
+		s.unhold()
+	}
+
+	flushed := s.sock.Call("write", js.Global.Get("Buffer").Call("from", p), js.InternalObject(func(err *js.Object) {
+		if !isNullish(err) && s.err == nil {
+			s.err = nodeError(err)
+			s.notify()
+		}
+	})).Bool()
+	s.full = !flushed
+	return len(p), nil
+}
+
+func (s *nodeSocket) accept() (*nodeSocket, error) {
+	for {
+		switch {
+		case s.closed:
+			return nil, ErrClosed
+		case len(s.incoming) > 0:
+			c := s.incoming[0]
+			s.incoming = s.incoming[1:]
+			return c, nil
+		case s.err != nil:
+			return nil, os.NewSyscallError("accept", s.err)
+		}
+		s.wait(time.Time{})
+	}
+}
+
+// closeRead discards any data received from now on.
+func (s *nodeSocket) closeRead() {
+	s.buf = nil
+	s.eof = true
+	s.sock.Call("removeAllListeners", "data")
+	s.sock.Call("resume")
+	s.notify()
+}
+
+func (s *nodeSocket) close() error {
+	if s.closed {
+		return ErrClosed
+	}
+	s.closed = true
+	if s.sock != nil {
+		// Writes return before Node.js sends the data, which must not be
+		// discarded, like it wouldn't be from the kernel's buffers.
+		if s.sock.Get("writableLength").Int() > 0 {
+			s.sock.Call("destroySoon")
+		} else {
+			s.sock.Call("destroy")
+		}
+	}
+	if s.server != nil {
+		s.server.Call("close")
+		for _, c := range s.incoming {
+			c.close()
+		}
+		s.incoming = nil
+	}
+	s.notify()
+	return nil
+}
+
+// nodeErrnos maps Node.js error codes to the corresponding system errors.
+var nodeErrnos = map[string]syscall.Errno{
+	"EACCES":        syscall.EACCES,
+	"EADDRINUSE":    syscall.EADDRINUSE,
+	"EADDRNOTAVAIL": syscall.EADDRNOTAVAIL,
+	"EAFNOSUPPORT":  syscall.EAFNOSUPPORT,
+	"ECONNABORTED":  syscall.ECONNABORTED,
+	"ECONNREFUSED":  syscall.ECONNREFUSED,
+	"ECONNRESET":    syscall.ECONNRESET,
+	"EHOSTUNREACH":  syscall.EHOSTUNREACH,
+	"EINVAL":        syscall.EINVAL,
+	"ENETDOWN":      syscall.ENETDOWN,
+	"ENETUNREACH":   syscall.ENETUNREACH,
+	"ENOENT":        syscall.ENOENT,
+	"ENOTCONN":      syscall.ENOTCONN,
+	"EPIPE":         syscall.EPIPE,
+	"ETIMEDOUT":     syscall.ETIMEDOUT,
+}
+
+// nodeError converts a Node.js system error into a Go error.
+func nodeError(err *js.Object) error {
+	if errno, ok := nodeErrnos[err.Get("code").String()]; ok {
+		return errno
+	}
+	return errors.New(err.Get("message").String())
+}
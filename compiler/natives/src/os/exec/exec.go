@@ -0,0 +1,55 @@
+//go:build js
+
+package exec
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// Processes can be started under Node.js, so executables are looked up the
+// same way as on Unix systems, except that only the permission bits are
+// checked.
+
+func findExecutable(file string) error {
+	d, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+	m := d.Mode()
+	if m.IsDir() {
+		return syscall.EISDIR
+	}
+	if m&0o111 != 0 {
+		return nil
+	}
+	return fs.ErrPermission
+}
+
+func LookPath(file string) (string, error) {
+	if strings.Contains(file, "/") {
+		err := findExecutable(file)
+		if err == nil {
+			return file, nil
+		}
+		return "", &Error{file, err}
+	}
+	path := os.Getenv("PATH")
+	for _, dir := range filepath.SplitList(path) {
+		if dir == "" {
+			// Unix shell semantics: path element "" means "."
+			dir = "."
+		}
+		path := filepath.Join(dir, file)
+		if err := findExecutable(path); err == nil {
+			if !filepath.IsAbs(path) && execerrdot.Value() != "0" {
+				return path, &Error{file, ErrDot}
+			}
+			return path, nil
+		}
+	}
+	return "", &Error{file, ErrNotFound}
+}
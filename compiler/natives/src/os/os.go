@@ -31,7 +31,15 @@ func init() {
 
 func runtime_beforeExit(exitCode int) {}
 
+// executable returns process.execPath, the path of the Node.js executable.
+// The path of the script it runs is os.Args[0], so the program runs itself
+// with exec.Command(exe, os.Args[0], args...).
 func executable() (string, error) {
+	if process := js.Global.Get("process"); process != js.Undefined {
+		if execPath := process.Get("execPath"); execPath != js.Undefined {
+			return execPath.String(), nil
+		}
+	}
 	return "", errors.New("Executable not implemented for GOARCH=js")
 }
 
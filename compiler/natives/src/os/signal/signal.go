@@ -2,11 +2,171 @@
 
 package signal
 
-// Package signal is not implemented for GOARCH=js.
+import (
+	"runtime"
+	"syscall"
 
-func signal_disable(uint32) {}
-func signal_enable(uint32)  {}
-func signal_ignore(uint32)  {}
-func signal_recv() uint32   { return 0 }
+	"github.com/gopherjs/gopherjs/js"
+)
 
-func loop() {}
+// Under Node.js signals are delivered by listeners installed with process.on().
+// Other environments have no notion of signals, so the functions below do
+// nothing and no signals are ever delivered.
+
+// signalNames maps the signals defined by the syscall package to their Node.js
+// names. SIGKILL can't be caught, same as in the upstream Go.
+var signalNames = map[uint32]string{
+	uint32(syscall.SIGCHLD): "SIGCHLD",
+	uint32(syscall.SIGINT):  "SIGINT",
+	uint32(syscall.SIGTRAP): "SIGTRAP",
+	uint32(syscall.SIGQUIT): "SIGQUIT",
+	uint32(syscall.SIGTERM): "SIGTERM",
+}
+
+var (
+	// listeners are the functions installed with process.on() for each signal.
+	listeners = map[uint32]*js.Object{}
+	// enabled signals are delivered to signal_recv().
+	enabled = map[uint32]bool{}
+	// ignored signals have a no-op listener, which prevents Node.js from
+	// terminating the process.
+	ignored = map[uint32]bool{}
+	// pending signals have been received, but not returned by signal_recv() yet.
+	pending [numSig]bool
+	// wake is used to unblock signal_recv() when a signal is received.
+	wake = make(chan struct{}, 1)
+	// idle is true while signal_recv() waits for a signal.
+	idle bool
+	// keepAlive is a Node.js timer, which keeps the process running while any
+	// signals are enabled, since listeners alone don't.
+	keepAlive *js.Object
+)
+
+// nodeProcess returns the Node.js process object, or nil if not running under
+// Node.js.
+func nodeProcess() *js.Object {
+	p := js.Global.Get("process")
+	if p == js.Undefined || p.Get("on") == js.Undefined {
+		return nil
+	}
+	return p
+}
+
+// setListener replaces the listener for signal sig with fn. A nil fn restores
+// the default Node.js behavior. It returns false if the signal can't be caught.
+func setListener(sig uint32, fn *js.Object) (ok bool) {
+	p := nodeProcess()
+	name, known := signalNames[sig]
+	if p == nil || !known {
+		return false
+	}
+	if old := listeners[sig]; old != nil {
+		p.Call("removeListener", name, old)
+		delete(listeners, sig)
+	}
+	if fn == nil {
+		return true
+	}
+	defer func() {
+		if recover() != nil {
+			ok = false // Signal is not supported on this platform.
+		}
+	}()
+	p.Call("on", name, fn)
+	listeners[sig] = fn
+	return true
+}
+
+// updateEnabled marks sig as enabled or disabled for delivery. While any signal
+// is enabled, the process is kept alive and deadlock detection is suppressed,
+// since the signal may awaken the program.
+func updateEnabled(sig uint32, on bool) {
+	if enabled[sig] == on {
+		return
+	}
+	if on {
+		enabled[sig] = true
+	} else {
+		delete(enabled, sig)
+	}
+	switch {
+	case on && len(enabled) == 1:
+		js.Global.Set("$exportedFunctions", js.Global.Get("$exportedFunctions").Int()+1)
+		keepAlive = js.Global.Call("setInterval", js.InternalObject(func() {}), 1<<30)
+	case !on && len(enabled) == 0:
+		js.Global.Set("$exportedFunctions", js.Global.Get("$exportedFunctions").Int()-1)
+		js.Global.Call("clearInterval", keepAlive)
+		keepAlive = nil
+	}
+}
+
+func signal_enable(sig uint32) {
+	if enabled[sig] {
+		return
+	}
+	delete(ignored, sig)
+	if setListener(sig, js.InternalObject(func() { deliver(sig) })) {
+		updateEnabled(sig, true)
+	}
+}
+
+func signal_disable(sig uint32) {
+	delete(ignored, sig)
+	setListener(sig, nil)
+	updateEnabled(sig, false)
+}
+
+func signal_ignore(sig uint32) {
+	if setListener(sig, js.InternalObject(func() {})) {
+		ignored[sig] = true
+	}
+	updateEnabled(sig, false)
+}
+
+func signal_ignored(sig uint32) bool {
+	return ignored[sig]
+}
+
+// deliver is called by a process.on() listener outside of any goroutine, so it
+// must not block.
+func deliver(sig uint32) {
+	pending[sig] = true
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
+
+func signal_recv() uint32 {
+	for {
+		for sig, ok := range pending {
+			if ok {
+				pending[sig] = false
+				return uint32(sig)
+			}
+		}
+		idle = true
+		<-wake
+		idle = false
+	}
+}
+
+// signalWaitUntilIdle waits until the signal delivery loop has processed all
+// pending signals.
+func signalWaitUntilIdle() {
+	if nodeProcess() == nil {
+		return
+	}
+	for !idle || len(wake) > 0 {
+		runtime.Gosched()
+	}
+}
+
+func loop() {
+	if nodeProcess() == nil {
+		return // Signals are never delivered outside of Node.js.
+	}
+	for {
+		process(syscall.Signal(signal_recv()))
+	}
+}
@@ -1266,17 +1266,17 @@ func (v Value) Field(i int) Value {
 
 	if tag := tt.fields[i].name.tag(); tag != "" && i != 0 {
 		if jsTag := getJsTag(tag); jsTag != "" {
-			for {
-				v = v.Field(0)
-				if v.typ == jsObjectPtr {
-					o := v.object().Get("object")
+			for f := v; f.Kind() == Struct && f.NumField() > 0; {
+				f = f.Field(0)
+				if f.typ == jsObjectPtr {
+					o := f.object().Get("object")
 					return Value{typ, unsafe.Pointer(jsType(PtrTo(typ)).New(
 						js.InternalObject(func() *js.Object { return js.Global.Call("$internalize", o.Get(jsTag), jsType(typ)) }),
 						js.InternalObject(func(x *js.Object) { o.Set(jsTag, js.Global.Call("$externalize", x, jsType(typ))) }),
 					).Unsafe()), fl}
 				}
-				if v.typ.Kind() == Ptr {
-					v = v.Elem()
+				if f.Kind() == Ptr {
+					f = f.Elem()
 				}
 			}
 		}
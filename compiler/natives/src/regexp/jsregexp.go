@@ -0,0 +1,374 @@
+//go:build js && jsregexp
+
+package regexp
+
+import (
+	"io"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// With the jsregexp build tag, regular expressions are matched by the RegExp
+// implementation of the JavaScript engine when their semantics are identical
+// to RE2's. Expressions using leftmost-longest matching or a construct that
+// JavaScript treats differently, and matching a io.RuneReader, fall back to
+// the Go implementation. Unlike RE2, backtracking engines may take exponential
+// time on some expressions, which is why the backend is opt-in.
+
+type Regexp struct {
+	expr           string       // as passed to Compile
+	prog           *syntax.Prog // compiled program
+	onepass        *onePassProg // onepass program or nil
+	numSubexp      int
+	maxBitStateLen int
+	subexpNames    []string
+	prefix         string         // required prefix in unanchored matches
+	prefixBytes    []byte         // prefix, as a []byte
+	prefixRune     rune           // first rune in prefix
+	prefixEnd      uint32         // pc for last rune in prefix
+	mpool          int            // pool for machines
+	matchcap       int            // size of recorded match lengths
+	prefixComplete bool           // prefix is the entire regexp
+	cond           syntax.EmptyOp // empty-width conditions required at start of match
+	minInputLen    int            // minimum length of the input in bytes
+
+	// This field can be modified by the Longest method,
+	// but it is otherwise read-only.
+	longest bool // whether regexp prefers leftmost-longest match
+
+	js *jsRegexp // JavaScript equivalent of the expression or nil
+}
+
+// jsRegexp holds the JavaScript RegExp objects of an expression. The one
+// reporting the indices of submatches is only created when needed, because
+// it is slower.
+type jsRegexp struct {
+	source  string
+	match   *js.Object
+	indices *js.Object
+}
+
+func (r *jsRegexp) regexp(submatches bool) *js.Object {
+	if !submatches {
+		return r.match
+	}
+	if r.indices == nil {
+		r.indices = js.Global.Get("RegExp").New(r.source, "dgu")
+	}
+	return r.indices
+}
+
+//gopherjs:keep-original
+func compile(expr string, mode syntax.Flags, longest bool) (*Regexp, error) {
+	re, err := _gopherjs_original_compile(expr, mode, longest)
+	if err != nil || longest {
+		return re, err
+	}
+	re.js = newJSRegexp(expr, mode)
+	return re, nil
+}
+
+// jsRegexps shares the RegExp objects between compilations of the same
+// expression, so that they stay comparable with reflect.DeepEqual. It is
+// cleared when it reaches maxJSRegexps entries.
+var jsRegexps = map[string]*jsRegexp{}
+
+const maxJSRegexps = 1000
+
+func newJSRegexp(expr string, mode syntax.Flags) (r *jsRegexp) {
+	re, err := syntax.Parse(expr, mode)
+	if err != nil {
+		return nil
+	}
+	var b strings.Builder
+	if !translate(&b, re, false) {
+		return nil
+	}
+	source := b.String()
+	if r, ok := jsRegexps[source]; ok {
+		return r
+	}
+	if len(jsRegexps) >= maxJSRegexps {
+		jsRegexps = map[string]*jsRegexp{}
+	}
+	defer func() {
+		// Engines without support for some of the flags or syntax.
+		if recover() != nil {
+			r = nil
+		}
+		jsRegexps[source] = r
+	}()
+	return &jsRegexp{source: source, match: js.Global.Get("RegExp").New(source, "gu")}
+}
+
+// translate writes the JavaScript equivalent of re to b. It reports false if
+// re uses a construct JavaScript matches differently. Captures inside
+// repetitions are rejected, because JavaScript resets them on each iteration
+// where RE2 keeps the last match, and so are optional repetitions of
+// expressions which can match the empty string.
+func translate(b *strings.Builder, re *syntax.Regexp, repeated bool) bool {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		b.WriteString("[]")
+	case syntax.OpEmptyMatch:
+		b.WriteString("(?:)")
+	case syntax.OpLiteral:
+		b.WriteString("(?:")
+		for _, r := range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 {
+				b.WriteByte('[')
+				for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+					writeRune(b, f)
+				}
+				writeRune(b, r)
+				b.WriteByte(']')
+			} else {
+				writeRune(b, r)
+			}
+		}
+		b.WriteString(")")
+	case syntax.OpCharClass:
+		b.WriteByte('[')
+		for i := 0; i < len(re.Rune); i += 2 {
+			writeRune(b, re.Rune[i])
+			if re.Rune[i+1] != re.Rune[i] {
+				b.WriteByte('-')
+				writeRune(b, re.Rune[i+1])
+			}
+		}
+		b.WriteByte(']')
+	case syntax.OpAnyCharNotNL:
+		b.WriteString(`[^\n]`)
+	case syntax.OpAnyChar:
+		b.WriteString("[^]")
+	case syntax.OpBeginLine:
+		b.WriteString(`(?<![^\n])`)
+	case syntax.OpEndLine:
+		b.WriteString(`(?![^\n])`)
+	case syntax.OpBeginText:
+		b.WriteString("^")
+	case syntax.OpEndText:
+		b.WriteString("$")
+	case syntax.OpWordBoundary:
+		b.WriteString(`\b`)
+	case syntax.OpNoWordBoundary:
+		b.WriteString(`\B`)
+	case syntax.OpCapture:
+		if repeated {
+			return false
+		}
+		b.WriteByte('(')
+		if !translate(b, re.Sub[0], repeated) {
+			return false
+		}
+		b.WriteByte(')')
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		// JavaScript rejects empty iterations beyond the minimum count.
+		exact := re.Op == syntax.OpRepeat && re.Min == re.Max
+		if !exact && minLength(re.Sub[0]) == 0 {
+			return false
+		}
+		loop := re.Op != syntax.OpQuest && (re.Op != syntax.OpRepeat || re.Max != 0 && re.Max != 1)
+		b.WriteString("(?:")
+		if !translate(b, re.Sub[0], repeated || loop) {
+			return false
+		}
+		b.WriteByte(')')
+		switch re.Op {
+		case syntax.OpStar:
+			b.WriteByte('*')
+		case syntax.OpPlus:
+			b.WriteByte('+')
+		case syntax.OpQuest:
+			b.WriteByte('?')
+		case syntax.OpRepeat:
+			b.WriteByte('{')
+			b.WriteString(strconv.Itoa(re.Min))
+			if re.Max != re.Min {
+				b.WriteByte(',')
+				if re.Max >= 0 {
+					b.WriteString(strconv.Itoa(re.Max))
+				}
+			}
+			b.WriteByte('}')
+		}
+		if re.Flags&syntax.NonGreedy != 0 {
+			b.WriteByte('?')
+		}
+	case syntax.OpConcat, syntax.OpAlternate:
+		b.WriteString("(?:")
+		for i, sub := range re.Sub {
+			if i > 0 && re.Op == syntax.OpAlternate {
+				b.WriteByte('|')
+			}
+			if !translate(b, sub, repeated) {
+				return false
+			}
+		}
+		b.WriteByte(')')
+	default:
+		return false
+	}
+	return true
+}
+
+// writeRune writes r escaped for a JavaScript RegExp with the u flag.
+func writeRune(b *strings.Builder, r rune) {
+	if '0' <= r && r <= '9' || 'A' <= r && r <= 'Z' || 'a' <= r && r <= 'z' {
+		b.WriteRune(r)
+		return
+	}
+	b.WriteString(`\u{`)
+	b.WriteString(strconv.FormatInt(int64(r), 16))
+	b.WriteByte('}')
+}
+
+// minLength returns the minimum number of runes matched by re.
+func minLength(re *syntax.Regexp) int {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return len(re.Rune)
+	case syntax.OpCharClass, syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return 1
+	case syntax.OpCapture, syntax.OpPlus:
+		return minLength(re.Sub[0])
+	case syntax.OpRepeat:
+		return re.Min * minLength(re.Sub[0])
+	case syntax.OpConcat:
+		n := 0
+		for _, sub := range re.Sub {
+			n += minLength(sub)
+		}
+		return n
+	case syntax.OpAlternate:
+		n := -1
+		for _, sub := range re.Sub {
+			if m := minLength(sub); n < 0 || m < n {
+				n = m
+			}
+		}
+		return n
+	}
+	return 0
+}
+
+// jsInput is a string converted for JavaScript. offsets maps the UTF-16
+// indices of str to byte offsets in s, it is nil for ASCII strings where
+// they are the same.
+type jsInput struct {
+	s       string
+	str     *js.Object
+	offsets []int
+}
+
+// lastInput caches the conversion for the successive searches of
+// allMatches.
+var lastInput *jsInput
+
+var asciiRegexp = js.Global.Get("RegExp").New(`^[\x00-\x7f]*$`)
+
+func newJSInput(s string) *jsInput {
+	if lastInput != nil && lastInput.s == s {
+		return lastInput
+	}
+	in := &jsInput{s: s}
+	if raw := js.InternalObject(s); asciiRegexp.Call("test", raw).Bool() {
+		in.str = raw
+	} else {
+		in.str = js.Global.Call("String", s)
+		in.offsets = make([]int, 0, len(s)+1)
+		for i, r := range s {
+			in.offsets = append(in.offsets, i)
+			if r >= 0x10000 {
+				in.offsets = append(in.offsets, i) // low surrogate
+			}
+		}
+		in.offsets = append(in.offsets, len(s))
+	}
+	lastInput = in
+	return in
+}
+
+// index returns the UTF-16 index of the byte offset pos.
+func (in *jsInput) index(pos int) int {
+	if in.offsets == nil {
+		return pos
+	}
+	lo, hi := 0, len(in.offsets)-1
+	for lo < hi {
+		m := int(uint(lo+hi) >> 1)
+		if in.offsets[m] < pos {
+			lo = m + 1
+		} else {
+			hi = m
+		}
+	}
+	return lo
+}
+
+// offset returns the byte offset of the UTF-16 index i.
+func (in *jsInput) offset(i int) int {
+	if in.offsets == nil {
+		return i
+	}
+	return in.offsets[i]
+}
+
+//gopherjs:keep-original
+func (re *Regexp) doExecute(r io.RuneReader, b []byte, s string, pos int, ncap int, dstCap []int) []int {
+	if re.js == nil || re.longest || r != nil {
+		return re._gopherjs_original_doExecute(r, b, s, pos, ncap, dstCap)
+	}
+	if dstCap == nil {
+		// Make sure 'return dstCap' is non-nil.
+		dstCap = arrayNoInts[:0:0]
+	}
+	if b != nil {
+		s = string(b)
+	}
+
+	in := newJSInput(s)
+	regexp := re.js.regexp(ncap > 2)
+	regexp.Set("lastIndex", in.index(pos))
+	m := regexp.Call("exec", in.str)
+	if m == nil {
+		return nil
+	}
+	if ncap > 2 {
+		indices := m.Get("indices")
+		for i := 0; i < ncap/2; i++ {
+			if loc := indices.Index(i); loc == js.Undefined {
+				dstCap = append(dstCap, -1, -1)
+			} else {
+				dstCap = append(dstCap, in.offset(loc.Index(0).Int()), in.offset(loc.Index(1).Int()))
+			}
+		}
+	} else if ncap == 2 {
+		start := m.Get("index").Int()
+		dstCap = append(dstCap, in.offset(start), in.offset(start+m.Index(0).Length()))
+	}
+	return dstCap
+}
+
+// allMatches and replaceAll search the same input repeatedly, so they
+// convert a []byte to a string once instead of in every doExecute.
+
+//gopherjs:keep-original
+func (re *Regexp) allMatches(s string, b []byte, n int, deliver func([]int)) {
+	if re.js != nil && b != nil {
+		s, b = string(b), nil
+	}
+	re._gopherjs_original_allMatches(s, b, n, deliver)
+}
+
+//gopherjs:keep-original
+func (re *Regexp) replaceAll(bsrc []byte, src string, nmatch int, repl func(dst []byte, m []int) []byte) []byte {
+	if re.js != nil && bsrc != nil {
+		src, bsrc = string(bsrc), nil
+	}
+	return re._gopherjs_original_replaceAll(bsrc, src, nmatch, repl)
+}
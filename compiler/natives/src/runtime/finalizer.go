@@ -0,0 +1,179 @@
+//go:build js
+
+package runtime
+
+import "github.com/gopherjs/gopherjs/js"
+
+var (
+	// finalizerRegistry is a FinalizationRegistry instance, which schedules
+	// finalizers once the objects they are attached to are garbage-collected by
+	// the JS engine. It is nil if the engine doesn't support weak references.
+	finalizerRegistry *js.Object
+	// finalizerStandIns maps objects with finalizers to their stand-ins (see
+	// finalizerStandIn() for details). This is a WeakMap, so it doesn't prevent
+	// the objects from being collected.
+	finalizerStandIns *js.Object
+	// finalizerWarned is set to true once we've warned the user that finalizers
+	// are not supported by the JS engine.
+	finalizerWarned bool
+)
+
+func init() {
+	registry := js.Global.Get("FinalizationRegistry")
+	if registry == js.Undefined || js.Global.Get("WeakMap") == js.Undefined {
+		return
+	}
+	finalizerRegistry = registry.New(js.InternalObject(runFinalizer))
+	finalizerStandIns = js.Global.Get("WeakMap").New()
+}
+
+// runFinalizer is invoked by the FinalizationRegistry after the object the
+// finalizer has been attached to was garbage-collected. Similar to the upstream
+// Go, the finalizer is executed in a separate goroutine.
+func runFinalizer(held *js.Object) {
+	js.Global.Call("$go", held.Get("fn"), held.Get("args"))
+}
+
+// SetFinalizer sets the finalizer associated with obj to the provided
+// finalizer function. See the upstream documentation for the full contract.
+//
+// GopherJS implements finalizers on top of the JavaScript FinalizationRegistry
+// API, which has a few notable limitations compared to the upstream Go:
+//
+//   - The JS engine can't resurrect an object once it has been collected, so
+//     the finalizer receives a stand-in pointer that shares the state of the
+//     original object, but has a different identity.
+//   - The engine decides when (and whether) to collect an object and run its
+//     cleanup callback, which may take much longer than in the upstream Go.
+//   - If the engine doesn't support FinalizationRegistry, a warning is printed
+//     and finalizers never run, which is permitted by the Go specification.
+func SetFinalizer(obj any, finalizer any) {
+	if obj == nil {
+		throw("runtime.SetFinalizer: first argument is nil")
+	}
+	o := js.InternalObject(obj)
+	etyp := o.Get("constructor")
+	if etyp.Get("kind").Int() != js.Global.Get("$kindPtr").Int() {
+		throw("runtime.SetFinalizer: first argument is " + etyp.Get("string").String() + ", not pointer")
+	}
+	if o == etyp.Get("nil") {
+		throw("nil pointer")
+	}
+
+	// Pointers to arrays are represented by the array itself, and the interface
+	// value merely wraps it.
+	target := o
+	if etyp.Get("wrapped").Bool() {
+		target = o.Get("$val")
+	}
+
+	if finalizer == nil {
+		if finalizerRegistry != nil && finalizerRegistry.Call("unregister", target).Bool() {
+			finalizerStandIns.Call("get", target).Set("registered", false)
+		}
+		return
+	}
+	f := js.InternalObject(finalizer)
+	ftyp := f.Get("constructor")
+	if ftyp.Get("kind").Int() != js.Global.Get("$kindFunc").Int() {
+		throw("runtime.SetFinalizer: second argument is " + ftyp.Get("string").String() + ", not a function")
+	}
+	if ftyp.Get("variadic").Bool() {
+		throw("runtime.SetFinalizer: cannot pass " + etyp.Get("string").String() + " to finalizer " + ftyp.Get("string").String() + " because dotdotdot")
+	}
+	if ftyp.Get("params").Length() != 1 || !finalizerAcceptsArg(ftyp.Get("params").Index(0), o) {
+		throw("runtime.SetFinalizer: cannot pass " + etyp.Get("string").String() + " to finalizer " + ftyp.Get("string").String())
+	}
+
+	if finalizerRegistry == nil {
+		if !finalizerWarned {
+			finalizerWarned = true
+			js.Global.Get("console").Call("warn", "warning: runtime.SetFinalizer is not supported by this JavaScript engine, finalizers will never run")
+		}
+		return
+	}
+
+	standIn := finalizerStandIn(etyp, target)
+	if standIn.Get("registered").Bool() {
+		throw("runtime.SetFinalizer: finalizer already set")
+	}
+	arg := standIn.Get("ptr")
+	if etyp.Get("wrapped").Bool() && ftyp.Get("params").Index(0).Get("kind").Int() == js.Global.Get("$kindInterface").Int() {
+		arg = etyp.New(arg)
+	}
+	held := js.Global.Get("Object").New()
+	held.Set("fn", f.Get("$val"))
+	held.Set("args", js.Global.Get("Array").Call("of", arg))
+	standIn.Set("registered", true)
+	finalizerRegistry.Call("register", target, held, target)
+}
+
+// finalizerAcceptsArg returns true if the pointer o can be passed to a
+// finalizer function, which takes a parameter of type fint.
+func finalizerAcceptsArg(fint, o *js.Object) bool {
+	etyp := o.Get("constructor")
+	switch {
+	case fint == etyp:
+		return true
+	case fint.Get("kind").Int() == js.Global.Get("$kindPtr").Int():
+		return (!fint.Get("named").Bool() || !etyp.Get("named").Bool()) && fint.Get("elem") == etyp.Get("elem")
+	case fint.Get("kind").Int() == js.Global.Get("$kindInterface").Int():
+		return js.Global.Call("$assertType", o, fint, true).Index(1).Bool()
+	}
+	return false
+}
+
+// finalizerStandIn returns a stand-in record for the pointer target of type
+// etyp, which will be passed to the finalizer instead of the original object.
+//
+// Unlike the upstream Go, the JS engine can't resurrect an object after it was
+// collected, and holding a reference to the object in the finalizer state would
+// prevent it from being collected at all. To work around that, we create a
+// stand-in pointer, which shares the state with the original, but doesn't
+// reference the original object itself:
+//
+//   - For struct pointers, the struct fields are moved into a new struct
+//     instance, and the original object gets accessor properties that redirect
+//     to it.
+//   - For pointers to arrays of numeric types, the stand-in is a new typed
+//     array view over the same ArrayBuffer. Other arrays can't share their
+//     storage, so they are not supported.
+//   - For other pointers, the new pointer reuses the getter and setter closures
+//     of the original one, which don't reference the pointer object.
+//
+// The record is cached, so that setting a finalizer repeatedly reuses the
+// existing stand-in.
+func finalizerStandIn(etyp, target *js.Object) *js.Object {
+	if standIn := finalizerStandIns.Call("get", target); standIn != js.Undefined {
+		return standIn
+	}
+
+	var ptr *js.Object
+	switch etyp.Get("elem").Get("kind").Int() {
+	case js.Global.Get("$kindStruct").Int():
+		ptr = etyp.New()
+		fields := etyp.Get("elem").Get("fields")
+		for i := 0; i < fields.Length(); i++ {
+			prop := fields.Index(i).Get("prop").String()
+			ptr.Set(prop, target.Get(prop))
+			js.Global.Get("Object").Call("defineProperty", target, prop, js.M{
+				"get":          js.InternalObject(func() *js.Object { return ptr.Get(prop) }),
+				"set":          js.InternalObject(func(v *js.Object) { ptr.Set(prop, v) }),
+				"enumerable":   true,
+				"configurable": true,
+			})
+		}
+	case js.Global.Get("$kindArray").Int():
+		if target.Get("buffer") == js.Undefined {
+			throw("runtime.SetFinalizer: pointer to " + etyp.Get("elem").Get("string").String() + " is not supported by GopherJS")
+		}
+		ptr = target.Get("constructor").New(target.Get("buffer"), target.Get("byteOffset"), target.Get("length"))
+	default:
+		ptr = etyp.New(target.Get("$get"), target.Get("$set"), target.Get("$target"))
+	}
+
+	standIn := js.Global.Get("Object").New()
+	standIn.Set("ptr", ptr)
+	finalizerStandIns.Call("set", target, standIn)
+	return standIn
+}
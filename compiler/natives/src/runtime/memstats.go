@@ -0,0 +1,116 @@
+//go:build js
+
+package runtime
+
+import "github.com/gopherjs/gopherjs/js"
+
+// gcStats accumulates garbage collection statistics reported by the JS engine.
+//
+// Under Node.js they are collected with a perf_hooks.PerformanceObserver,
+// which is started the first time ReadMemStats() is called. Other environments
+// don't report garbage collection events, so the stats remain zero.
+var gcStats struct {
+	observing    bool
+	observer     *js.Object
+	numGC        uint32
+	lastGC       uint64
+	pauseTotalNs uint64
+	pauseNs      [256]uint64
+	pauseEnd     [256]uint64
+}
+
+// observeGC starts collecting garbage collection events, if supported by the
+// JS engine.
+func observeGC() {
+	if gcStats.observing {
+		return
+	}
+	gcStats.observing = true
+
+	require := js.Global.Get("require")
+	if require == js.Undefined {
+		return
+	}
+	defer func() {
+		recover() // perf_hooks module is unavailable.
+	}()
+	perfHooks := require.Invoke("perf_hooks")
+	timeOrigin := perfHooks.Get("performance").Get("timeOrigin").Float()
+	recordGCEntries = func(entries *js.Object) {
+		for i := 0; i < entries.Length(); i++ {
+			const millisecond = 1_000_000
+			e := entries.Index(i)
+			pause := uint64(e.Get("duration").Float() * millisecond)
+			end := uint64((timeOrigin + e.Get("startTime").Float() + e.Get("duration").Float()) * millisecond)
+			gcStats.pauseNs[gcStats.numGC%256] = pause
+			gcStats.pauseEnd[gcStats.numGC%256] = end
+			gcStats.pauseTotalNs += pause
+			gcStats.lastGC = end
+			gcStats.numGC++
+		}
+	}
+	observer := perfHooks.Get("PerformanceObserver").New(js.InternalObject(func(list *js.Object) {
+		recordGCEntries(list.Call("getEntries"))
+	}))
+	observer.Call("observe", js.M{"entryTypes": []string{"gc"}})
+	gcStats.observer = observer
+}
+
+// recordGCEntries adds garbage collection performance entries to gcStats.
+var recordGCEntries func(entries *js.Object)
+
+// flushGCStats processes garbage collection events the observer has buffered,
+// but not delivered yet.
+func flushGCStats() {
+	if gcStats.observer != nil {
+		recordGCEntries(gcStats.observer.Call("takeRecords"))
+	}
+}
+
+// ReadMemStats populates m with memory allocator statistics.
+//
+// The statistics are derived from the information the JS engine provides:
+// process.memoryUsage() under Node.js, or the non-standard performance.memory
+// in Chromium-based browsers. Since the engine manages its own heap, the
+// values are approximate and many of the fields upstream Go reports remain
+// zero.
+//
+// Allocation counters (Mallocs and TotalAlloc) are only collected when the
+// program is built with GOPHERJS_EXPERIMENT=memstats, and Frees are never
+// tracked, since the engine doesn't report individual deallocations. Garbage
+// collection events are delivered by the engine asynchronously, so NumGC may
+// lag behind a preceding GC() call.
+func ReadMemStats(m *MemStats) {
+	observeGC()
+	flushGCStats()
+	*m = MemStats{EnableGC: true}
+
+	if process := js.Global.Get("process"); process != js.Undefined && process.Get("memoryUsage") != js.Undefined {
+		usage := process.Call("memoryUsage")
+		m.HeapSys = uint64(usage.Get("heapTotal").Int64())
+		m.HeapAlloc = uint64(usage.Get("heapUsed").Int64())
+		if rss := uint64(usage.Get("rss").Int64()); rss > m.HeapSys {
+			m.OtherSys = rss - m.HeapSys
+		}
+	} else if perf := js.Global.Get("performance"); perf != js.Undefined && perf.Get("memory") != js.Undefined {
+		memory := perf.Get("memory")
+		m.HeapSys = uint64(memory.Get("totalJSHeapSize").Int64())
+		m.HeapAlloc = uint64(memory.Get("usedJSHeapSize").Int64())
+	}
+	m.Alloc = m.HeapAlloc
+	m.HeapInuse = m.HeapAlloc
+	if m.HeapSys > m.HeapInuse {
+		m.HeapIdle = m.HeapSys - m.HeapInuse
+	}
+	m.Sys = m.HeapSys + m.OtherSys
+
+	memStats := js.Global.Get("$memStats")
+	m.Mallocs = uint64(memStats.Get("mallocs").Int64())
+	m.TotalAlloc = uint64(memStats.Get("totalAlloc").Int64())
+
+	m.NumGC = gcStats.numGC
+	m.LastGC = gcStats.lastGC
+	m.PauseTotalNs = gcStats.pauseTotalNs
+	m.PauseNs = gcStats.pauseNs
+	m.PauseEnd = gcStats.pauseEnd
+}
@@ -0,0 +1,84 @@
+//go:build js
+
+package metrics
+
+import (
+	"math"
+	"runtime"
+	"unsafe"
+)
+
+//gopherjs:purge The upstream implementation relies on unsafe pointers.
+func runtime_readMetrics()
+
+// Read populates each Value field in the given slice of metric samples.
+//
+// GopherJS derives the metric values from runtime.ReadMemStats() and other
+// runtime APIs, so only a subset of the metrics listed by All() is supported.
+// Unsupported metrics have their Value populated as KindBad.
+func Read(m []Sample) {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	for i := range m {
+		v := &m[i].Value
+		switch m[i].Name {
+		case "/gc/cycles/total:gc-cycles":
+			v.setUint64(uint64(ms.NumGC))
+		case "/gc/heap/allocs:bytes":
+			v.setUint64(ms.TotalAlloc)
+		case "/gc/heap/allocs:objects":
+			v.setUint64(ms.Mallocs)
+		case "/memory/classes/heap/objects:bytes":
+			v.setUint64(ms.HeapAlloc)
+		case "/memory/classes/heap/unused:bytes":
+			v.setUint64(ms.HeapIdle)
+		case "/memory/classes/other:bytes":
+			v.setUint64(ms.OtherSys)
+		case "/memory/classes/total:bytes":
+			v.setUint64(ms.Sys)
+		case "/sched/gomaxprocs:threads":
+			v.setUint64(uint64(runtime.GOMAXPROCS(0)))
+		case "/sched/goroutines:goroutines":
+			v.setUint64(uint64(runtime.NumGoroutine()))
+		case "/cgo/go-to-c-calls:calls":
+			v.setUint64(uint64(runtime.NumCgoCall()))
+		case "/gc/pauses:seconds":
+			v.setFloat64Histogram(gcPauses(&ms))
+		default:
+			*v = Value{}
+		}
+	}
+}
+
+func (v *Value) setUint64(x uint64) {
+	*v = Value{kind: KindUint64, scalar: x}
+}
+
+func (v *Value) setFloat64Histogram(h *Float64Histogram) {
+	*v = Value{kind: KindFloat64Histogram, pointer: unsafe.Pointer(h)}
+}
+
+// gcPauses builds a histogram of the recent garbage collection pauses reported
+// in ms.
+func gcPauses(ms *runtime.MemStats) *Float64Histogram {
+	// Use power-of-ten buckets from 1µs to 10s.
+	h := &Float64Histogram{
+		Buckets: []float64{math.Inf(-1), 1e-6, 1e-5, 1e-4, 1e-3, 1e-2, 1e-1, 1, 10, math.Inf(1)},
+	}
+	h.Counts = make([]uint64, len(h.Buckets)-1)
+	n := int(ms.NumGC)
+	if n > len(ms.PauseNs) {
+		n = len(ms.PauseNs)
+	}
+	for i := 0; i < n; i++ {
+		pause := float64(ms.PauseNs[i]) / 1e9
+		for b := 1; b < len(h.Buckets); b++ {
+			if pause < h.Buckets[b] {
+				h.Counts[b-1]++
+				break
+			}
+		}
+	}
+	return h
+}
@@ -0,0 +1,252 @@
+//go:build js
+
+package pprof
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// cpuProfileHz is the CPU profile sampling rate, same as upstream's default.
+const cpuProfileHz = 100
+
+var cpu struct {
+	sync.Mutex
+	profiling bool
+	session   *js.Object // inspector.Session
+	start     time.Time
+	w         io.Writer
+}
+
+// StartCPUProfile enables CPU profiling for the current process.
+// While profiling, the profile will be buffered and written to w.
+// StartCPUProfile returns an error if profiling is already enabled.
+//
+// GopherJS takes CPU profiles with the V8 sampling profiler through the
+// Node.js inspector module, and returns an error in other environments.
+// The profile is written to w when StopCPUProfile is called. JavaScript
+// frames are mapped to the Go functions they were compiled from using source
+// maps, which Node.js loads when started with the --enable-source-maps flag
+// (the default for `gopherjs run` and `gopherjs test`). Since V8 doesn't
+// report call sites, each frame points to the first line of the function.
+func StartCPUProfile(w io.Writer) error {
+	cpu.Lock()
+	defer cpu.Unlock()
+	if cpu.profiling {
+		return errors.New("cpu profiling already in use")
+	}
+
+	session, err := newInspectorSession()
+	if err != nil {
+		return err
+	}
+	if _, err := inspectorPost(session, "Profiler.enable", nil); err != nil {
+		session.Call("disconnect")
+		return err
+	}
+	if _, err := inspectorPost(session, "Profiler.setSamplingInterval", js.M{"interval": 1e6 / cpuProfileHz}); err != nil {
+		session.Call("disconnect")
+		return err
+	}
+	if _, err := inspectorPost(session, "Profiler.start", nil); err != nil {
+		session.Call("disconnect")
+		return err
+	}
+
+	cpu.profiling = true
+	cpu.session = session
+	cpu.start = time.Now()
+	cpu.w = w
+	return nil
+}
+
+// StopCPUProfile stops the current CPU profile, if any, and writes it to the
+// writer passed to StartCPUProfile.
+func StopCPUProfile() {
+	cpu.Lock()
+	defer cpu.Unlock()
+	if !cpu.profiling {
+		return
+	}
+	cpu.profiling = false
+	defer cpu.session.Call("disconnect")
+
+	result, err := inspectorPost(cpu.session, "Profiler.stop", nil)
+	if err != nil {
+		return
+	}
+	p := convertCPUProfile(result.Get("profile"), cpu.start)
+	writeProfile(cpu.w, p)
+}
+
+// newInspectorSession connects to the V8 inspector of the current thread.
+func newInspectorSession() (session *js.Object, err error) {
+	require := js.Global.Get("require")
+	if require == js.Undefined {
+		return nil, errors.New("cpu profiling requires the Node.js inspector module")
+	}
+	defer func() {
+		if e := recover(); e != nil {
+			session, err = nil, fmt.Errorf("cpu profiling requires the Node.js inspector module: %v", e)
+		}
+	}()
+	session = require.Invoke("inspector").Get("Session").New()
+	session.Call("connect")
+	return session, nil
+}
+
+// inspectorPost sends an inspector protocol message and returns the result.
+// Inspector sessions connected to the same thread dispatch messages
+// synchronously, so the callback has been invoked by the time post() returns.
+func inspectorPost(session *js.Object, method string, params js.M) (*js.Object, error) {
+	var result *js.Object
+	var err error
+	session.Call("post", method, params, func(e, r *js.Object) {
+		if e != nil {
+			err = errors.New(method + ": " + e.Get("message").String())
+		}
+		result = r
+	})
+	return result, err
+}
+
+// convertCPUProfile converts a V8 CPU profile into pprof profile data. See
+// https://chromedevtools.github.io/devtools-protocol/tot/Profiler/#type-Profile
+// for the V8 profile format.
+func convertCPUProfile(profile *js.Object, start time.Time) *profileData {
+	const period = int64(time.Second / cpuProfileHz)
+	p := &profileData{
+		sampleTypes: []valueType{{"samples", "count"}, {"cpu", "nanoseconds"}},
+		periodType:  valueType{"cpu", "nanoseconds"},
+		period:      period,
+		timeNanos:   start.UnixNano(),
+		// Start and end times are in microseconds.
+		durationNanos: int64(profile.Get("endTime").Float()-profile.Get("startTime").Float()) * 1000,
+	}
+
+	// V8 represents the profile as a call tree, where each sample refers to
+	// the leaf node of the call stack.
+	nodes := profile.Get("nodes")
+	parents := map[int]int{}
+	callFrames := map[int]*js.Object{}
+	for i := 0; i < nodes.Length(); i++ {
+		n := nodes.Index(i)
+		id := n.Get("id").Int()
+		callFrames[id] = n.Get("callFrame")
+		if children := n.Get("children"); children != js.Undefined {
+			for j := 0; j < children.Length(); j++ {
+				parents[children.Index(j).Int()] = id
+			}
+		}
+	}
+
+	var order []int
+	counts := map[int]int64{}
+	durations := map[int]int64{}
+	samples := profile.Get("samples")
+	timeDeltas := profile.Get("timeDeltas")
+	for i := 0; i < samples.Length(); i++ {
+		id := samples.Index(i).Int()
+		if callFrames[id].Get("functionName").String() == "(idle)" {
+			continue
+		}
+		if counts[id] == 0 {
+			order = append(order, id)
+		}
+		counts[id]++
+		// Each sample accounts for the time elapsed until the next one.
+		if i+1 < timeDeltas.Length() {
+			durations[id] += int64(timeDeltas.Index(i+1).Float() * 1000)
+		} else {
+			durations[id] += period
+		}
+	}
+
+	r := sourceResolver{maps: map[string]*js.Object{}, frames: map[int]frame{}}
+	for _, id := range order {
+		var stack []frame
+		for n, ok := id, true; ok; n, ok = parents[n] {
+			if callFrames[n].Get("functionName").String() == "(root)" {
+				break
+			}
+			stack = append(stack, r.frame(n, callFrames[n]))
+		}
+		p.samples = append(p.samples, sample{stack: stack, values: []int64{counts[id], durations[id]}})
+	}
+	return p
+}
+
+// sourceResolver maps V8 call frames back to Go functions.
+type sourceResolver struct {
+	maps   map[string]*js.Object // Script URL to source map, nil if unavailable.
+	frames map[int]frame         // Node ID to resolved frame.
+}
+
+// frame returns the frame for the V8 call frame cf of the call tree node id.
+//
+// The call frame position points at the start of the compiled function, which
+// GopherJS maps to the Go function declaration, along with its fully-qualified
+// name. Frames without a source mapping, such as the ones from the GopherJS
+// prelude, are reported with their JavaScript names and positions.
+func (r *sourceResolver) frame(id int, cf *js.Object) frame {
+	if f, ok := r.frames[id]; ok {
+		return f
+	}
+	url := cf.Get("url").String()
+	line := cf.Get("lineNumber").Int()
+	f := frame{
+		function: jsFunctionName(cf.Get("functionName").String()),
+		file:     url,
+		line:     int64(line + 1),
+	}
+	if sm := r.sourceMap(url); sm != nil && line >= 0 {
+		e := sm.Call("findEntry", line, cf.Get("columnNumber"))
+		// findEntry returns the closest preceding mapping, which may belong to
+		// some other code if this line has no mappings.
+		if e.Get("originalSource") != js.Undefined && e.Get("generatedLine").Int() == line {
+			f.file = strings.TrimPrefix(e.Get("originalSource").String(), "file://")
+			f.line = int64(e.Get("originalLine").Int() + 1)
+			if name := e.Get("name"); name != js.Undefined {
+				f.function = name.String()
+			}
+		}
+	}
+	r.frames[id] = f
+	return f
+}
+
+// sourceMap returns the source map Node.js has loaded for the script at url,
+// or nil if there is none.
+func (r *sourceResolver) sourceMap(url string) *js.Object {
+	if sm, ok := r.maps[url]; ok {
+		return sm
+	}
+	sm := findSourceMap(url)
+	r.maps[url] = sm
+	return sm
+}
+
+// findSourceMap looks up the source map for the script at url with the
+// Node.js module.findSourceMap() API.
+func findSourceMap(url string) (sm *js.Object) {
+	require := js.Global.Get("require")
+	if url == "" || require == js.Undefined {
+		return nil
+	}
+	defer func() {
+		if recover() != nil {
+			sm = nil // module.findSourceMap() is unavailable.
+		}
+	}()
+	sm = require.Invoke("module").Call("findSourceMap", url)
+	if sm == js.Undefined {
+		return nil
+	}
+	return sm
+}
@@ -3,10 +3,26 @@
 package pprof
 
 import (
+	"fmt"
 	"io"
+	"runtime"
+	"sort"
+	"strings"
 	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gopherjs/gopherjs/js"
 )
 
+// Profile is a collection of stack traces showing the call sequences that led
+// to instances of a particular event. See the upstream documentation for the
+// full description.
+//
+// GopherJS populates the goroutine profile and custom profiles created with
+// NewProfile. The heap, allocs, threadcreate, block and mutex profiles are
+// valid, but always empty, since the JS engine doesn't expose the necessary
+// information.
 type Profile struct {
 	name  string
 	mu    sync.Mutex
@@ -15,35 +31,381 @@ type Profile struct {
 	write func(io.Writer, int) error
 }
 
-func (p *Profile) WriteTo(w io.Writer, debug int) error {
-	return nil
+// profiles records all registered profiles.
+var profiles struct {
+	mu sync.Mutex
+	m  map[string]*Profile
 }
 
-func (p *Profile) Count() int {
-	return 0
+var goroutineProfile = &Profile{
+	name:  "goroutine",
+	count: countGoroutine,
+	write: writeGoroutine,
+}
+
+var threadcreateProfile = &Profile{
+	name:  "threadcreate",
+	count: countZero,
+	write: writeThreadCreate,
+}
+
+var heapProfile = &Profile{
+	name:  "heap",
+	count: countZero,
+	write: writeHeap,
+}
+
+var allocsProfile = &Profile{
+	name:  "allocs",
+	count: countZero,
+	write: writeHeap, // identical to heap profile
+}
+
+var blockProfile = &Profile{
+	name:  "block",
+	count: countZero,
+	write: writeContention("block"),
+}
+
+var mutexProfile = &Profile{
+	name:  "mutex",
+	count: countZero,
+	write: writeContention("mutex"),
+}
+
+func lockProfiles() {
+	profiles.mu.Lock()
+	if profiles.m == nil {
+		// Initial built-in profiles.
+		profiles.m = map[string]*Profile{
+			"goroutine":    goroutineProfile,
+			"threadcreate": threadcreateProfile,
+			"heap":         heapProfile,
+			"allocs":       allocsProfile,
+			"block":        blockProfile,
+			"mutex":        mutexProfile,
+		}
+	}
+}
+
+func unlockProfiles() {
+	profiles.mu.Unlock()
+}
+
+// NewProfile creates a new profile with the given name.
+// If a profile with that name already exists, NewProfile panics.
+func NewProfile(name string) *Profile {
+	lockProfiles()
+	defer unlockProfiles()
+	if name == "" {
+		panic("pprof: NewProfile with empty name")
+	}
+	if profiles.m[name] != nil {
+		panic("pprof: NewProfile name already in use: " + name)
+	}
+	p := &Profile{
+		name: name,
+		m:    map[any][]uintptr{},
+	}
+	profiles.m[name] = p
+	return p
+}
+
+// Lookup returns the profile with the given name, or nil if no such profile exists.
+func Lookup(name string) *Profile {
+	lockProfiles()
+	defer unlockProfiles()
+	return profiles.m[name]
 }
 
+// Profiles returns a slice of all the known profiles, sorted by name.
+func Profiles() []*Profile {
+	lockProfiles()
+	defer unlockProfiles()
+
+	all := make([]*Profile, 0, len(profiles.m))
+	for _, p := range profiles.m {
+		all = append(all, p)
+	}
+
+	sort.Slice(all, func(i, j int) bool { return all[i].name < all[j].name })
+	return all
+}
+
+// Name returns this profile's name, which can be passed to Lookup to reobtain the profile.
 func (p *Profile) Name() string {
-	return ""
+	return p.name
+}
+
+// Count returns the number of execution stacks currently in the profile.
+func (p *Profile) Count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.count != nil {
+		return p.count()
+	}
+	return len(p.m)
 }
 
+// Add adds the current execution stack to the profile, associated with value.
+// Add panics if the profile already contains a stack for value.
 func (p *Profile) Add(value any, skip int) {
+	if p.name == "" {
+		panic("pprof: use of uninitialized Profile")
+	}
+	if p.write != nil {
+		panic("pprof: Add called on built-in Profile " + p.name)
+	}
+
+	stk := make([]uintptr, 32)
+	n := runtime.Callers(skip+1, stk[:])
+	stk = stk[:n]
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.m[value] != nil {
+		panic("pprof: Profile.Add of duplicate value")
+	}
+	p.m[value] = stk
 }
 
+// Remove removes the execution stack associated with value from the profile.
+// It is a no-op if the value is not in the profile.
 func (p *Profile) Remove(value any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.m, value)
+}
+
+// WriteTo writes a pprof-formatted snapshot of the profile to w.
+//
+// Passing debug=0 writes the gzip-compressed protocol buffer, which can be
+// read with `go tool pprof`. Passing debug=1 writes a human-readable text
+// format. For the goroutine profile debug=2 lists each goroutine separately,
+// along with its state.
+func (p *Profile) WriteTo(w io.Writer, debug int) error {
+	if p.name == "" {
+		panic("pprof: use of zero Profile")
+	}
+	if p.write != nil {
+		return p.write(w, debug)
+	}
+
+	p.mu.Lock()
+	all := make([][]frame, 0, len(p.m))
+	for _, stk := range p.m {
+		all = append(all, symbolize(stk))
+	}
+	p.mu.Unlock()
+
+	return printCountProfile(w, debug, p.name, all)
 }
 
-func StartCPUProfile(w io.Writer) error {
+// symbolize converts program counters returned by runtime.Callers into frames.
+func symbolize(stk []uintptr) []frame {
+	var result []frame
+	frames := runtime.CallersFrames(stk)
+	for {
+		f, more := frames.Next()
+		if f.PC != 0 {
+			result = append(result, frame{function: f.Function, file: f.File, line: int64(f.Line)})
+		}
+		if !more {
+			return result
+		}
+	}
+}
+
+// printCountProfile writes a profile, in which each sample is a call stack with
+// the number of times it has been recorded.
+func printCountProfile(w io.Writer, debug int, name string, stacks [][]frame) error {
+	// Aggregate identical stacks.
+	var keys []string
+	counts := map[string]int64{}
+	byKey := map[string][]frame{}
+	for _, stk := range stacks {
+		key := fmt.Sprint(stk)
+		if counts[key] == 0 {
+			keys = append(keys, key)
+			byKey[key] = stk
+		}
+		counts[key]++
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	if debug > 0 {
+		tw := tabwriter.NewWriter(w, 1, 8, 1, '\t', 0)
+		fmt.Fprintf(tw, "%s profile: total %d\n", name, len(stacks))
+		for _, k := range keys {
+			fmt.Fprintf(tw, "%d @\n", counts[k])
+			for _, f := range byKey[k] {
+				fmt.Fprintf(tw, "#\t%s\t%s:%d\n", f.function, f.file, f.line)
+			}
+			fmt.Fprint(tw, "\n")
+		}
+		return tw.Flush()
+	}
+
+	p := &profileData{
+		sampleTypes: []valueType{{name, "count"}},
+		periodType:  valueType{name, "count"},
+		period:      1,
+		timeNanos:   time.Now().UnixNano(),
+	}
+	for _, k := range keys {
+		p.samples = append(p.samples, sample{stack: byKey[k], values: []int64{counts[k]}})
+	}
+	return writeProfile(w, p)
+}
+
+func countZero() int { return 0 }
+
+// countGoroutine returns the number of goroutines.
+func countGoroutine() int {
+	return runtime.NumGoroutine()
+}
+
+// goroutineRecord describes a live goroutine.
+type goroutineRecord struct {
+	id    int
+	state string
+	stack []frame
+}
+
+// goroutines returns records for all goroutines that have been started and
+// haven't exited yet, ordered by goroutine ID.
+//
+// Only the stack of the calling goroutine can be inspected in JavaScript, other
+// goroutines are represented by their entry function.
+func goroutines() []goroutineRecord {
+	cur := js.Global.Get("$curGoroutine")
+	live := js.Global.Get("Array").Call("from", js.Global.Get("$liveGoroutines"))
+	records := make([]goroutineRecord, 0, live.Length())
+	for i := 0; i < live.Length(); i++ {
+		g := live.Index(i)
+		r := goroutineRecord{id: g.Get("id").Int()}
+		switch {
+		case g == cur:
+			r.state = "running"
+			stk := make([]uintptr, 64)
+			r.stack = symbolize(stk[:runtime.Callers(2, stk)])
+		case g.Get("asleep").Bool():
+			r.state = g.Get("waitReason").String()
+		default:
+			r.state = "runnable"
+		}
+		if len(r.stack) == 0 {
+			r.stack = []frame{{function: jsFunctionName(g.Get("fun").Get("name").String())}}
+		}
+		records = append(records, r)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].id < records[j].id })
+	return records
+}
+
+// jsFunctionName returns a readable name of a compiled function.
+func jsFunctionName(name string) string {
+	// GopherJS adds a numeric suffix to function names to avoid collisions.
+	if i := strings.LastIndexByte(name, '$'); i > 0 && strings.Trim(name[i+1:], "0123456789") == "" {
+		name = name[:i]
+	}
+	if name == "" {
+		return "(anonymous)"
+	}
+	return name
+}
+
+func writeGoroutine(w io.Writer, debug int) error {
+	records := goroutines()
+	if debug >= 2 {
+		return writeGoroutineStacks(w, records)
+	}
+	stacks := make([][]frame, len(records))
+	for i, r := range records {
+		stacks[i] = r.stack
+	}
+	return printCountProfile(w, debug, "goroutine", stacks)
+}
+
+// writeGoroutineStacks lists goroutines in a form similar to the one a Go
+// program uses when dying due to an unrecovered panic.
+func writeGoroutineStacks(w io.Writer, records []goroutineRecord) error {
+	for i, r := range records {
+		if i > 0 {
+			fmt.Fprint(w, "\n")
+		}
+		fmt.Fprintf(w, "goroutine %d [%s]:\n", r.id, r.state)
+		for _, f := range r.stack {
+			fmt.Fprintf(w, "%s(...)\n", f.function)
+			if f.file != "" {
+				fmt.Fprintf(w, "\t%s:%d\n", f.file, f.line)
+			}
+		}
+	}
 	return nil
 }
 
-func StopCPUProfile() {
+func writeThreadCreate(w io.Writer, debug int) error {
+	return printCountProfile(w, debug, "threadcreate", nil)
 }
 
+// WriteHeapProfile is shorthand for Lookup("heap").WriteTo(w, 0).
 func WriteHeapProfile(w io.Writer) error {
-	return nil
+	return writeHeap(w, 0)
 }
 
-func Lookup(name string) *Profile {
+// writeHeap writes an empty heap profile, since the JS engine doesn't report
+// individual allocations. With debug > 0 it also includes runtime.MemStats.
+func writeHeap(w io.Writer, debug int) error {
+	if debug == 0 {
+		return writeProfile(w, &profileData{
+			sampleTypes: []valueType{
+				{"alloc_objects", "count"},
+				{"alloc_space", "bytes"},
+				{"inuse_objects", "count"},
+				{"inuse_space", "bytes"},
+			},
+			periodType: valueType{"space", "bytes"},
+			period:     int64(runtime.MemProfileRate),
+			timeNanos:  time.Now().UnixNano(),
+		})
+	}
+
+	var s runtime.MemStats
+	runtime.ReadMemStats(&s)
+	fmt.Fprintf(w, "heap profile: 0: 0 [0: 0] @ heap/%d\n", 2*runtime.MemProfileRate)
+	fmt.Fprintf(w, "\n# runtime.MemStats\n")
+	fmt.Fprintf(w, "# Alloc = %d\n", s.Alloc)
+	fmt.Fprintf(w, "# TotalAlloc = %d\n", s.TotalAlloc)
+	fmt.Fprintf(w, "# Sys = %d\n", s.Sys)
+	fmt.Fprintf(w, "# Mallocs = %d\n", s.Mallocs)
+	fmt.Fprintf(w, "# HeapAlloc = %d\n", s.HeapAlloc)
+	fmt.Fprintf(w, "# HeapSys = %d\n", s.HeapSys)
+	fmt.Fprintf(w, "# HeapIdle = %d\n", s.HeapIdle)
+	fmt.Fprintf(w, "# HeapInuse = %d\n", s.HeapInuse)
+	fmt.Fprintf(w, "# OtherSys = %d\n", s.OtherSys)
+	fmt.Fprintf(w, "# NumGC = %d\n", s.NumGC)
 	return nil
 }
+
+// writeContention returns a writer for an empty contention profile. GopherJS
+// doesn't record blocking and mutex contention events.
+func writeContention(name string) func(io.Writer, int) error {
+	return func(w io.Writer, debug int) error {
+		if debug > 0 {
+			fmt.Fprintf(w, "--- %s:\ncycles/second=%v\n", name, 1e9)
+			return nil
+		}
+		return writeProfile(w, &profileData{
+			sampleTypes: []valueType{{"contentions", "count"}, {"delay", "nanoseconds"}},
+			periodType:  valueType{"contentions", "count"},
+			period:      1,
+			timeNanos:   time.Now().UnixNano(),
+		})
+	}
+}
@@ -0,0 +1,178 @@
+//go:build js
+
+package pprof
+
+import (
+	"compress/gzip"
+	"io"
+)
+
+// valueType describes the semantics and the measurement unit of a sample value.
+type valueType struct {
+	typ  string
+	unit string
+}
+
+// frame is a single symbolized call stack frame.
+//
+// Unlike the upstream, GopherJS has no real program counters to symbolize
+// later, so frames are resolved into function names and source positions at
+// the time the profile is collected.
+type frame struct {
+	function string
+	file     string
+	line     int64
+}
+
+// sample is a call stack, leaf frame first, with the values associated with it.
+type sample struct {
+	stack  []frame
+	values []int64
+}
+
+// profileData is a profile ready to be serialized in the pprof format.
+type profileData struct {
+	sampleTypes   []valueType
+	periodType    valueType
+	period        int64
+	timeNanos     int64
+	durationNanos int64
+	samples       []sample
+}
+
+// profileBuilder encodes profileData in the gzip-compressed protocol buffer
+// format described in https://github.com/google/pprof/tree/master/proto.
+type profileBuilder struct {
+	pb        protobuf
+	strings   []string
+	stringMap map[string]int
+	funcs     map[[2]string]uint64
+	locs      map[frame]uint64
+	locList   []frame
+	funcList  [][2]string
+}
+
+// Field numbers from https://github.com/google/pprof/blob/main/proto/profile.proto.
+const (
+	// message Profile
+	tagProfile_SampleType    = 1  // repeated ValueType
+	tagProfile_Sample        = 2  // repeated Sample
+	tagProfile_Location      = 4  // repeated Location
+	tagProfile_Function      = 5  // repeated Function
+	tagProfile_StringTable   = 6  // repeated string
+	tagProfile_TimeNanos     = 9  // int64
+	tagProfile_DurationNanos = 10 // int64
+	tagProfile_PeriodType    = 11 // ValueType
+	tagProfile_Period        = 12 // int64
+
+	// message ValueType
+	tagValueType_Type = 1 // int64 (string table index)
+	tagValueType_Unit = 2 // int64 (string table index)
+
+	// message Sample
+	tagSample_Location = 1 // repeated uint64
+	tagSample_Value    = 2 // repeated int64
+
+	// message Location
+	tagLocation_ID   = 1 // uint64
+	tagLocation_Line = 4 // repeated Line
+
+	// message Line
+	tagLine_FunctionID = 1 // uint64
+	tagLine_Line       = 2 // int64
+
+	// message Function
+	tagFunction_ID         = 1 // uint64
+	tagFunction_Name       = 2 // int64 (string table index)
+	tagFunction_SystemName = 3 // int64 (string table index)
+	tagFunction_Filename   = 4 // int64 (string table index)
+)
+
+// writeProfile writes p to w as a gzip-compressed pprof protocol buffer.
+func writeProfile(w io.Writer, p *profileData) error {
+	b := &profileBuilder{
+		strings:   []string{""},
+		stringMap: map[string]int{"": 0},
+		funcs:     map[[2]string]uint64{},
+		locs:      map[frame]uint64{},
+	}
+	for _, st := range p.sampleTypes {
+		b.pbValueType(tagProfile_SampleType, st)
+	}
+	for _, s := range p.samples {
+		locs := make([]uint64, len(s.stack))
+		for i, f := range s.stack {
+			locs[i] = b.locationID(f)
+		}
+		start := b.pb.startMessage()
+		b.pb.uint64s(tagSample_Location, locs)
+		b.pb.int64s(tagSample_Value, s.values)
+		b.pb.endMessage(tagProfile_Sample, start)
+	}
+	for i, f := range b.locList {
+		start := b.pb.startMessage()
+		b.pb.uint64Opt(tagLocation_ID, uint64(i+1))
+		lineStart := b.pb.startMessage()
+		b.pb.uint64Opt(tagLine_FunctionID, b.funcs[[2]string{f.function, f.file}])
+		b.pb.int64Opt(tagLine_Line, f.line)
+		b.pb.endMessage(tagLocation_Line, lineStart)
+		b.pb.endMessage(tagProfile_Location, start)
+	}
+	for i, fn := range b.funcList {
+		start := b.pb.startMessage()
+		b.pb.uint64Opt(tagFunction_ID, uint64(i+1))
+		b.pb.int64Opt(tagFunction_Name, b.stringIndex(fn[0]))
+		b.pb.int64Opt(tagFunction_SystemName, b.stringIndex(fn[0]))
+		b.pb.int64Opt(tagFunction_Filename, b.stringIndex(fn[1]))
+		b.pb.endMessage(tagProfile_Function, start)
+	}
+	b.pb.int64Opt(tagProfile_TimeNanos, p.timeNanos)
+	b.pb.int64Opt(tagProfile_DurationNanos, p.durationNanos)
+	b.pbValueType(tagProfile_PeriodType, p.periodType)
+	b.pb.int64Opt(tagProfile_Period, p.period)
+	// The string table must be emitted last, since the messages above may add
+	// new entries to it.
+	b.pb.strings(tagProfile_StringTable, b.strings)
+
+	zw := gzip.NewWriter(w)
+	if _, err := zw.Write(b.pb.data); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// stringIndex adds s to the string table if not already present and returns
+// the index of s in the string table.
+func (b *profileBuilder) stringIndex(s string) int64 {
+	id, ok := b.stringMap[s]
+	if !ok {
+		id = len(b.strings)
+		b.strings = append(b.strings, s)
+		b.stringMap[s] = id
+	}
+	return int64(id)
+}
+
+// locationID returns the ID of the location for frame f, allocating a new
+// location and function if necessary.
+func (b *profileBuilder) locationID(f frame) uint64 {
+	if id, ok := b.locs[f]; ok {
+		return id
+	}
+	fn := [2]string{f.function, f.file}
+	if _, ok := b.funcs[fn]; !ok {
+		b.funcList = append(b.funcList, fn)
+		b.funcs[fn] = uint64(len(b.funcList))
+	}
+	b.locList = append(b.locList, f)
+	id := uint64(len(b.locList))
+	b.locs[f] = id
+	return id
+}
+
+func (b *profileBuilder) pbValueType(tag int, t valueType) {
+	start := b.pb.startMessage()
+	b.pb.int64(tagValueType_Type, b.stringIndex(t.typ))
+	b.pb.int64(tagValueType_Unit, b.stringIndex(t.unit))
+	b.pb.endMessage(tag, start)
+}
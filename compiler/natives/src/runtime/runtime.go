@@ -309,7 +309,50 @@ type Frame struct {
 	Entry    uintptr
 }
 
-func GC() {}
+// GC runs a garbage collection if the JS engine exposes a way to trigger it,
+// otherwise it is a no-op.
+//
+// Browsers generally don't allow triggering garbage collection. Under Node.js
+// the global gc() function is exposed with the --expose-gc flag, and if it is
+// missing we attempt to enable it at runtime through the v8 module.
+func GC() {
+	if gc := engineGC(); gc != nil {
+		gc.Invoke()
+	}
+}
+
+// engineGCFunc caches the JS function engineGC() has found, or js.Undefined if
+// the engine doesn't expose one.
+var engineGCFunc *js.Object
+
+// engineGC returns a JS function that triggers garbage collection, or nil if
+// there is no such function available.
+func engineGC() *js.Object {
+	if engineGCFunc == nil {
+		engineGCFunc = lookupEngineGC()
+	}
+	if engineGCFunc == js.Undefined {
+		return nil
+	}
+	return engineGCFunc
+}
+
+func lookupEngineGC() (gc *js.Object) {
+	if gc := js.Global.Get("gc"); gc != js.Undefined && gc.Get("constructor") == js.Global.Get("Function") {
+		return gc
+	}
+	require := js.Global.Get("require")
+	if require == js.Undefined {
+		return js.Undefined
+	}
+	defer func() {
+		if err := recover(); err != nil {
+			gc = js.Undefined // v8 or vm modules are unavailable.
+		}
+	}()
+	require.Invoke("v8").Call("setFlagsFromString", "--expose-gc")
+	return require.Invoke("vm").Call("runInNewContext", "gc")
+}
 
 func Goexit() {
 	js.Global.Get("$curGoroutine").Set("exit", true)
@@ -380,16 +423,6 @@ type MemStats struct {
 	}
 }
 
-func ReadMemStats(m *MemStats) {
-	// TODO(nevkontakte): This function is effectively unimplemented and may
-	// lead to silent unexpected behaviors. Consider panicing explicitly.
-}
-
-func SetFinalizer(x, f any) {
-	// TODO(nevkontakte): This function is effectively unimplemented and may
-	// lead to silent unexpected behaviors. Consider panicing explicitly.
-}
-
 type Func struct {
 	name string
 	file string
@@ -461,10 +494,6 @@ func Version() string {
 	return buildVersion
 }
 
-func StartTrace() error { return nil }
-func StopTrace()        {}
-func ReadTrace() []byte
-
 // We fake a cgo environment to catch errors. Therefore we have to implement this and always return 0
 func NumCgoCall() int64 {
 	return 0
@@ -0,0 +1,110 @@
+//go:build js
+
+package runtime
+
+import "github.com/gopherjs/gopherjs/js"
+
+// traceSession represents an active trace started by StartTrace().
+type traceSession struct {
+	data chan []byte   // Trace data to be returned by ReadTrace().
+	done chan struct{} // Closed once ReadTrace() has returned all the data.
+}
+
+var curTrace *traceSession
+
+// StartTrace enables tracing for the current process.
+//
+// Unlike the upstream Go, GopherJS records goroutine scheduling events in the
+// Chrome Trace Event JSON format rather than the Go execution trace format, so
+// the trace must be viewed with chrome://tracing or https://ui.perfetto.dev
+// instead of `go tool trace`. The trace has one track per goroutine, and
+// records goroutine creation, blocking and unblocking, channel operations,
+// timers, as well as user annotations from the runtime/trace package.
+//
+// While tracing, the data will be buffered and made available via ReadTrace
+// once StopTrace is called. StartTrace returns an error if tracing is already
+// enabled.
+func StartTrace() error {
+	if curTrace != nil {
+		return errorString("tracing is already enabled")
+	}
+	curTrace = &traceSession{
+		data: make(chan []byte),
+		done: make(chan struct{}),
+	}
+	js.Global.Call("$startTracer")
+	return nil
+}
+
+// StopTrace stops tracing, if it was previously enabled. StopTrace only returns
+// after all the reads for the trace have completed.
+func StopTrace() {
+	t := curTrace
+	if t == nil {
+		return
+	}
+	data := js.Global.Get("$tracer").Call("stop").String()
+	t.data <- []byte(data)
+	close(t.data)
+	<-t.done
+}
+
+// ReadTrace returns the next chunk of binary tracing data, blocking until data
+// is available. If tracing is turned off and all the data accumulated while it
+// was on has been returned, ReadTrace returns nil. The caller must copy the
+// returned data before calling ReadTrace again.
+func ReadTrace() []byte {
+	t := curTrace
+	if t == nil {
+		return nil
+	}
+	data, ok := <-t.data
+	if !ok {
+		curTrace = nil
+		close(t.done)
+		return nil
+	}
+	return data
+}
+
+// tracer returns the active goroutine scheduling tracer, or nil if tracing is
+// disabled.
+func tracer() *js.Object {
+	if t := js.Global.Get("$tracer"); t != nil {
+		return t
+	}
+	return nil
+}
+
+// The functions below implement user annotations from the runtime/trace
+// package.
+
+func traceUserTaskCreate(id, parentID uint64, taskType string) {
+	if t := tracer(); t != nil {
+		t.Call("async", js.Global.Get("$curGoroutine"), "b", id, taskType, js.M{"parent": parentID})
+	}
+}
+
+func traceUserTaskEnd(id uint64) {
+	if t := tracer(); t != nil {
+		t.Call("async", js.Global.Get("$curGoroutine"), "e", id, "")
+	}
+}
+
+func traceUserRegion(id, mode uint64, regionType string) {
+	t := tracer()
+	if t == nil {
+		return
+	}
+	phase := "B" // Region start.
+	if mode == 1 {
+		phase = "E" // Region end.
+	}
+	t.Call("duration", js.Global.Get("$curGoroutine"), phase, regionType, js.M{"task": id})
+}
+
+func traceUserLog(id uint64, category, message string) {
+	if t := tracer(); t != nil {
+		t.Call("instant", js.Global.Get("$curGoroutine"), category, js.M{"task": id, "message": message})
+	}
+}
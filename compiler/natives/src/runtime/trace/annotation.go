@@ -0,0 +1,17 @@
+//go:build js
+
+package trace
+
+import _ "unsafe" // for go:linkname
+
+//go:linkname userTaskCreate runtime.traceUserTaskCreate
+func userTaskCreate(id, parentID uint64, taskType string)
+
+//go:linkname userTaskEnd runtime.traceUserTaskEnd
+func userTaskEnd(id uint64)
+
+//go:linkname userRegion runtime.traceUserRegion
+func userRegion(id, mode uint64, regionType string)
+
+//go:linkname userLog runtime.traceUserLog
+func userLog(id uint64, category, message string)
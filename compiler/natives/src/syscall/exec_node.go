@@ -0,0 +1,465 @@
+//go:build js
+
+package syscall
+
+import (
+	"internal/itoa"
+	"syscall/js"
+)
+
+// Under Node.js processes are started with the child_process module. Node.js
+// can't create anonymous pipes, so Pipe returns descriptors of in-memory pipes
+// instead. When such a descriptor is passed to a started process, the
+// corresponding standard stream of the process is connected to the pipe.
+
+// jsChildProcess is the Node.js child_process module, or undefined if it is
+// unavailable.
+var jsChildProcess = func() (m js.Value) {
+	require := js.Global().Get("require")
+	if require.IsUndefined() {
+		return js.Undefined()
+	}
+	defer func() {
+		if recover() != nil {
+			m = js.Undefined()
+		}
+	}()
+	return require.Invoke("child_process")
+}()
+
+// signalNames maps the signals defined by this package to their Node.js names.
+var signalNames = map[Signal]string{
+	SIGCHLD: "SIGCHLD",
+	SIGINT:  "SIGINT",
+	SIGKILL: "SIGKILL",
+	SIGTRAP: "SIGTRAP",
+	SIGQUIT: "SIGQUIT",
+	SIGTERM: "SIGTERM",
+}
+
+// signalDescriptions are the descriptions of signals used by Linux.
+var signalDescriptions = map[Signal]string{
+	SIGCHLD: "child exited",
+	SIGINT:  "interrupt",
+	SIGKILL: "killed",
+	SIGTRAP: "trace/breakpoint trap",
+	SIGQUIT: "quit",
+	SIGTERM: "terminated",
+}
+
+func (s Signal) String() string {
+	if str, ok := signalDescriptions[s]; ok {
+		return str
+	}
+	return "signal " + itoa.Itoa(int(s))
+}
+
+// signalByName returns the signal with the given Node.js name. Signals this
+// package doesn't define are numbered after their POSIX numbers, shifted past
+// the defined ones.
+func signalByName(name string) Signal {
+	for sig, n := range signalNames {
+		if n == name {
+			return sig
+		}
+	}
+	if num := js.Global().Get("require").Invoke("os").Get("constants").Get("signals").Get(name); num.Type() == js.TypeNumber {
+		return Signal(32 + num.Int())
+	}
+	return SIGKILL
+}
+
+// Wait statuses are encoded the same way as on Linux.
+const (
+	waitMask    = 0x7F
+	waitCore    = 0x80
+	waitShift   = 8
+	waitExited  = 0
+	waitStopped = 0x7F
+)
+
+func (w WaitStatus) Exited() bool { return w&waitMask == waitExited }
+
+func (w WaitStatus) ExitStatus() int {
+	if !w.Exited() {
+		return -1
+	}
+	return int(w>>waitShift) & 0xFF
+}
+
+func (w WaitStatus) Signaled() bool { return w&waitMask != waitStopped && w&waitMask != waitExited }
+
+func (w WaitStatus) Signal() Signal {
+	if !w.Signaled() {
+		return -1
+	}
+	return Signal(w & waitMask)
+}
+
+func (w WaitStatus) CoreDump() bool { return w.Signaled() && w&waitCore != 0 }
+
+// child is a process started by StartProcess.
+type child struct {
+	status WaitStatus
+	exited chan struct{} // closed once the process exits
+}
+
+// children are the started processes, which haven't been waited for yet.
+var children = map[int]*child{}
+
+func StartProcess(argv0 string, argv []string, attr *ProcAttr) (pid int, handle uintptr, err error) {
+	if jsChildProcess.IsUndefined() {
+		return 0, 0, ENOSYS
+	}
+	if attr == nil {
+		attr = &ProcAttr{}
+	}
+
+	opts := js.Global().Get("Object").New()
+	var args []any
+	if len(argv) > 0 {
+		opts.Set("argv0", argv[0])
+		// Running the program itself, that is the script os.Args[0] with
+		// os.Executable(), requires running Node.js with the same options.
+		script := jsProcess.Get("argv").Index(1)
+		if len(argv) > 1 && argv0 == jsProcess.Get("execPath").String() && script.Type() == js.TypeString && argv[1] == script.String() {
+			execArgv := jsProcess.Get("execArgv")
+			for i := 0; i < execArgv.Length(); i++ {
+				args = append(args, execArgv.Index(i))
+			}
+		}
+		for _, arg := range argv[1:] {
+			args = append(args, arg)
+		}
+	}
+	if attr.Dir != "" {
+		opts.Set("cwd", attr.Dir)
+	}
+	env := js.Global().Get("Object").New()
+	for _, kv := range attr.Env {
+		for i := 1; i < len(kv); i++ {
+			if kv[i] == '=' {
+				env.Set(kv[:i], kv[i+1:])
+				break
+			}
+		}
+	}
+	opts.Set("env", env)
+	stdio := make([]any, len(attr.Files))
+	pipes := make([]*pipeEnd, len(attr.Files))
+	for i, fd := range attr.Files {
+		switch end, ok := pipeEnds[int(fd)]; {
+		case ok:
+			stdio[i] = "pipe"
+			pipes[i] = end
+		case int(fd) < 0:
+			stdio[i] = "ignore"
+		default:
+			stdio[i] = int(fd)
+		}
+	}
+	opts.Set("stdio", stdio)
+
+	proc, err := spawn(argv0, args, opts)
+	if err != nil {
+		return 0, 0, err
+	}
+	for i, end := range pipes {
+		switch {
+		case end == nil:
+		case end.write:
+			end.p.feed(proc.Get("stdio").Index(i))
+		default:
+			end.p.drain(proc.Get("stdio").Index(i))
+		}
+	}
+
+	pid = proc.Get("pid").Int()
+	c := &child{exited: make(chan struct{})}
+	children[pid] = c
+	var onExit js.Func
+	onExit = js.FuncOf(func(this js.Value, args []js.Value) any {
+		onExit.Release()
+		if code := args[0]; code.Type() == js.TypeNumber {
+			c.status = WaitStatus(code.Int()&0xFF) << waitShift
+		} else {
+			c.status = WaitStatus(signalByName(args[1].String()))
+		}
+		close(c.exited)
+		return nil
+	})
+	proc.Call("once", "exit", onExit)
+	return pid, 0, nil
+}
+
+// spawn starts a process. If it fails to start, child_process only reports
+// the error asynchronously.
+func spawn(file string, args []any, opts js.Value) (proc js.Value, err error) {
+	defer recoverErr(&err)
+	proc = jsChildProcess.Call("spawn", file, args, opts)
+	if !proc.Get("pid").IsUndefined() {
+		return proc, nil
+	}
+	failed := make(chan error, 1)
+	var onError js.Func
+	onError = js.FuncOf(func(this js.Value, args []js.Value) any {
+		onError.Release()
+		failed <- spawnError(args[0])
+		return nil
+	})
+	proc.Call("once", "error", onError)
+	return proc, <-failed
+}
+
+// spawnError maps an error emitted by child_process to a Go error.
+func spawnError(jsErr js.Value) error {
+	if errno, ok := errnoByCode[jsErr.Get("code").String()]; ok {
+		return errnoErr(Errno(errno))
+	}
+	return EINVAL
+}
+
+func Wait4(pid int, wstatus *WaitStatus, options int, rusage *Rusage) (wpid int, err error) {
+	c, ok := children[pid]
+	if !ok {
+		return 0, ECHILD
+	}
+	<-c.exited
+	delete(children, pid)
+	if wstatus != nil {
+		*wstatus = c.status
+	}
+	return pid, nil
+}
+
+func Kill(pid int, signum Signal) (err error) {
+	if jsProcess.IsUndefined() || jsProcess.Get("kill").IsUndefined() {
+		return ENOSYS
+	}
+	var sig any = 0
+	if signum != 0 {
+		name, ok := signalNames[signum]
+		if !ok {
+			return EINVAL
+		}
+		sig = name
+	}
+	defer recoverErr(&err)
+	jsProcess.Call("kill", pid, sig)
+	return nil
+}
+
+// pipeFDBase is the first descriptor of in-memory pipes, which is far above
+// the descriptors Node.js allocates for files.
+const pipeFDBase = 1 << 20
+
+// pipeBufSize is the amount of data a pipe holds before writers get blocked.
+const pipeBufSize = 64 << 10
+
+// pipe is an in-memory pipe.
+type pipe struct {
+	// changed is closed and replaced every time the state below changes.
+	changed chan struct{}
+	buf     []byte
+	readers int    // number of open read ends
+	writers int    // number of open write ends
+	resume  func() // resumes the stream feeding the pipe after it is read
+}
+
+// pipeEnd is one end of a pipe, which has a descriptor.
+type pipeEnd struct {
+	p     *pipe
+	write bool
+}
+
+var (
+	pipeEnds   = map[int]*pipeEnd{}
+	nextPipeFD = pipeFDBase
+)
+
+func (p *pipe) notify() {
+	changed := p.changed
+	p.changed = make(chan struct{})
+	close(changed)
+}
+
+func (p *pipe) read(b []byte) int {
+	n := copy(b, p.buf)
+	p.buf = p.buf[n:]
+	if p.resume != nil && len(p.buf) < pipeBufSize {
+		resume := p.resume
+		p.resume = nil
+		resume()
+	}
+	p.notify()
+	return n
+}
+
+// feed makes a readable Node.js stream write to the pipe until it closes.
+func (p *pipe) feed(stream js.Value) {
+	p.writers++
+	var onData, onError, onClose js.Func
+	onData = js.FuncOf(func(this js.Value, args []js.Value) any {
+		if p.readers == 0 {
+			return nil // Nobody will ever read the data.
+		}
+		data := uint8Array.New(args[0]) // Node.js Buffers aren't plain Uint8Arrays.
+		chunk := make([]byte, data.Length())
+		js.CopyBytesToGo(chunk, data)
+		p.buf = append(p.buf, chunk...)
+		if len(p.buf) >= pipeBufSize && p.resume == nil {
+			stream.Call("pause")
+			p.resume = func() { stream.Call("resume") }
+		}
+		p.notify()
+		return nil
+	})
+	onError = js.FuncOf(func(this js.Value, args []js.Value) any { return nil })
+	onClose = js.FuncOf(func(this js.Value, args []js.Value) any {
+		onData.Release()
+		onError.Release()
+		onClose.Release()
+		p.writers--
+		p.notify()
+		return nil
+	})
+	stream.Call("on", "data", onData)
+	stream.Call("on", "error", onError)
+	stream.Call("once", "close", onClose)
+}
+
+// drain writes the data from the pipe to a writable Node.js stream, ending it
+// once all write ends of the pipe are closed.
+func (p *pipe) drain(stream js.Value) {
+	p.readers++
+	closed := false
+	var onError, onClose js.Func
+	onError = js.FuncOf(func(this js.Value, args []js.Value) any { return nil })
+	onClose = js.FuncOf(func(this js.Value, args []js.Value) any {
+		onError.Release()
+		onClose.Release()
+		closed = true
+		p.notify()
+		return nil
+	})
+	stream.Call("on", "error", onError)
+	stream.Call("once", "close", onClose)
+
+	go func() {
+		defer func() {
+			p.readers--
+			p.notify()
+		}()
+		buf := make([]byte, 32<<10)
+		for {
+			for len(p.buf) == 0 && p.writers > 0 && !closed {
+				<-p.changed
+			}
+			switch {
+			case closed:
+				return
+			case len(p.buf) == 0:
+				stream.Call("end")
+				return
+			}
+			n := p.read(buf)
+			chunk := uint8Array.New(n)
+			js.CopyBytesToJS(chunk, buf[:n])
+			written := false
+			var onWritten js.Func
+			onWritten = js.FuncOf(func(this js.Value, args []js.Value) any {
+				onWritten.Release()
+				written = true
+				p.notify()
+				return nil
+			})
+			stream.Call("write", chunk, onWritten)
+			for !written && !closed {
+				<-p.changed
+			}
+		}
+	}()
+}
+
+func Pipe(fd []int) error {
+	if len(fd) != 2 {
+		return EINVAL
+	}
+	p := &pipe{changed: make(chan struct{}), readers: 1, writers: 1}
+	fd[0], fd[1] = nextPipeFD, nextPipeFD+1
+	nextPipeFD += 2
+	pipeEnds[fd[0]] = &pipeEnd{p: p}
+	pipeEnds[fd[1]] = &pipeEnd{p: p, write: true}
+	return nil
+}
+
+//gopherjs:keep-original
+func Read(fd int, b []byte) (int, error) {
+	end, ok := pipeEnds[fd]
+	if !ok {
+		return _gopherjs_original_Read(fd, b)
+	}
+	if end.write {
+		return 0, EBADF
+	}
+	p := end.p
+	for len(p.buf) == 0 && p.writers > 0 {
+		<-p.changed
+		if pipeEnds[fd] != end {
+			return 0, EBADF
+		}
+	}
+	return p.read(b), nil
+}
+
+//gopherjs:keep-original
+func Write(fd int, b []byte) (int, error) {
+	end, ok := pipeEnds[fd]
+	if !ok {
+		return _gopherjs_original_Write(fd, b)
+	}
+	if !end.write {
+		return 0, EBADF
+	}
+	p := end.p
+	n := 0
+	for n < len(b) {
+		if p.readers == 0 {
+			return n, EPIPE
+		}
+		if len(p.buf) >= pipeBufSize {
+			<-p.changed
+			if pipeEnds[fd] != end {
+				return n, EBADF
+			}
+			continue
+		}
+		m := len(b) - n
+		if free := pipeBufSize - len(p.buf); m > free {
+			m = free
+		}
+		p.buf = append(p.buf, b[n:n+m]...)
+		n += m
+		p.notify()
+	}
+	return n, nil
+}
+
+//gopherjs:keep-original
+func Close(fd int) error {
+	end, ok := pipeEnds[fd]
+	if !ok {
+		return _gopherjs_original_Close(fd)
+	}
+	delete(pipeEnds, fd)
+	if end.write {
+		end.p.writers--
+	} else {
+		end.p.readers--
+		if end.p.readers == 0 {
+			end.p.buf = nil
+		}
+	}
+	end.p.notify()
+	return nil
+}
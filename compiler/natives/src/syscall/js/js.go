@@ -80,12 +80,56 @@ func FuncOf(fn func(this Value, args []Value) any) Func {
 	}
 }
 
+// AsyncFuncOf is like FuncOf, but the returned function calls fn in a new
+// goroutine, so fn may block. It returns a Promise that resolves to the value
+// returned by fn, or is rejected with a JavaScript Error if fn panics. This
+// function is specific to GopherJS.
+func AsyncFuncOf(fn func(this Value, args []Value) any) Func {
+	js.Global.Set("$exportedFunctions", js.Global.Get("$exportedFunctions").Int()+1)
+	return Func{
+		Value: objectToValue(js.MakeAsyncFunc(func(this *js.Object, args []*js.Object) any {
+			vargs := make([]Value, len(args))
+			for i, a := range args {
+				vargs[i] = objectToValue(a)
+			}
+			return fn(objectToValue(this), vargs)
+		})),
+	}
+}
+
 type Error struct {
 	Value
 }
 
 func (e Error) Error() string {
-	return "JavaScript error: " + e.Get("message").String()
+	return (&js.Error{Object: e.internal()}).Error()
+}
+
+// Await blocks the calling goroutine until the promise v settles, like Await
+// of github.com/gopherjs/gopherjs/js. A rejection is returned as an Error.
+// This function is specific to GopherJS.
+func Await(v Value) (Value, error) {
+	x, err := js.Await(v.internal())
+	if err != nil {
+		return Undefined(), Error{objectToValue(err.(*js.Error).Object)}
+	}
+	return objectToValue(x), nil
+}
+
+// NewPromise returns a Promise that calls fn in a new goroutine and resolves
+// to ValueOf of its result, or is rejected with the value of an Error or a
+// JavaScript Error with the message of any other error. This function is
+// specific to GopherJS.
+func NewPromise(fn func() (any, error)) Value {
+	return objectToValue(js.NewPromise(func() (any, error) {
+		v, err := fn()
+		if e, ok := err.(Error); ok {
+			return nil, &js.Error{Object: e.internal()}
+		} else if err != nil {
+			return nil, err
+		}
+		return ValueOf(v).internal(), nil
+	}))
 }
 
 type Value struct {
@@ -131,12 +175,25 @@ func getValueType(obj *js.Object) Type {
 	return TypeObject
 }
 
+// Wrapper is implemented by types that are backed by a JavaScript value, or
+// that control how ValueOf converts them to JavaScript. Upstream Go removed
+// it in Go 1.18, GopherJS keeps it as a conversion hook. Types implementing
+// js.Marshaler of the gopherjs/js package are converted by ValueOf as well.
+type Wrapper interface {
+	// JSValue returns a JavaScript value associated with an object.
+	JSValue() Value
+}
+
 func ValueOf(x any) Value {
 	switch x := x.(type) {
 	case Value:
 		return x
 	case Func:
 		return x.Value
+	case Wrapper:
+		return x.JSValue()
+	case js.Marshaler:
+		return objectToValue(x.ExternalizeJS())
 	case nil:
 		return Null()
 	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, unsafe.Pointer, string, map[string]any, []any:
@@ -366,3 +423,20 @@ func CopyBytesToJS(dst Value, src []byte) int {
 	}
 	return copy(dt, src)
 }
+
+// TypedArrayOf returns a typed array that shares memory with slice, without
+// copying, unlike CopyBytesToJS. It is a GopherJS extension, see
+// TypedArrayOf of the github.com/gopherjs/gopherjs/js package for the
+// supported slice types and aliasing rules.
+func TypedArrayOf(slice any) Value {
+	return objectToValue(js.TypedArrayOf(slice))
+}
+
+// SliceOfTypedArray sets the slice pointed to by slicePtr to a slice that
+// shares memory with the typed array v, without copying, unlike
+// CopyBytesToGo. It is a GopherJS extension, see SliceOfTypedArray of the
+// github.com/gopherjs/gopherjs/js package for the supported slice types and
+// aliasing rules.
+func SliceOfTypedArray(v Value, slicePtr any) {
+	js.SliceOfTypedArray(v.internal(), slicePtr)
+}
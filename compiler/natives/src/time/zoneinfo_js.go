@@ -2,14 +2,24 @@
 
 package time
 
-import "github.com/gopherjs/gopherjs/js"
+import (
+	"errors"
 
-// The code below is based on the upstream zoneinfo_js.go to closer match
-// WebAssembly behavior.
+	"github.com/gopherjs/gopherjs/js"
+)
 
 func initLocal() {
 	localLoc.name = "Local"
 
+	if tz := intlDefaultZone(); tz != "" {
+		if z := intlZoneNamed(tz); z != nil {
+			z.install(&localLoc)
+			return
+		}
+	}
+
+	// The code below is based on the upstream zoneinfo_js.go to closer match
+	// WebAssembly behavior. It is used when the Intl API is unavailable.
 	z := zone{}
 	d := js.Global.Get("Date").New()
 	offset := d.Call("getTimezoneOffset").Int() * -1
@@ -54,3 +64,431 @@ const smallsString = "00010203040506070809" +
 	"80818283848586878889" +
 	"90919293949596979899"
 const digits = "0123456789"
+
+// loadLocation falls back to the Intl API for time zones missing from the
+// IANA Time Zone database sources, which is always the case in browsers.
+//
+//gopherjs:keep-original
+func loadLocation(name string, sources []string) (*Location, error) {
+	l, err := _gopherjs_original_loadLocation(name, sources)
+	if err == nil {
+		return l, nil
+	}
+	if l, ok := loadIntlLocation(name); ok {
+		return l, nil
+	}
+	if intlMissing() {
+		return nil, err
+	}
+	return nil, errors.New("unknown time zone " + name)
+}
+
+// loadIntlLocation returns the Location with the given name, which is backed
+// by the Intl API. It reports false if the API doesn't know the time zone.
+func loadIntlLocation(name string) (*Location, bool) {
+	z := intlZoneNamed(name)
+	if z == nil {
+		return nil, false
+	}
+	l := &Location{name: name}
+	z.install(l)
+	return l, true
+}
+
+// lookup finds transitions of Intl time zones on demand and answers the
+// lookups of other locations with the upstream implementation.
+//
+//gopherjs:keep-original
+func (l *Location) lookup(sec int64) (name string, offset int, start, end int64, isDST bool) {
+	l = l.get()
+	if len(l.tx) == 1 && len(l.extend) > len(intlPrefix) && l.extend[:len(intlPrefix)] == intlPrefix {
+		if z, ok := intlZones[l.extend[len(intlPrefix):]]; ok {
+			return z.lookup(sec)
+		}
+	}
+	return l._gopherjs_original_lookup(sec)
+}
+
+// intlZones caches the time zones backed by the Intl API by name, so that
+// the transitions found are shared by all Locations of a zone. Such Locations
+// have a single placeholder transition and the name of the zone prefixed by
+// intlPrefix in place of the extend string, which is never used for them.
+// Unlike a pointer to the Location, these are kept by copies of the Location,
+// such as the testing zone assigned to localLoc.
+var intlZones = map[string]*intlZone{}
+
+const intlPrefix = "Intl:"
+
+const (
+	// intlChunk is the number of seconds the range searched for transitions is
+	// extended by. Transitions are searched for one chunk before and after the
+	// looked up time, which is enough for all uses of the start and the end of
+	// the zone within the time package.
+	intlChunk = 1 << 25 // about a year
+	// intlStep is the interval between the probed offsets, so time zones that
+	// change more than once within it may have transitions missing.
+	intlStep = 7 * secondsPerDay
+	// intlLimit is the number of seconds the Date type can represent around
+	// the Unix epoch. Times beyond it are treated as having its offset.
+	intlLimit = 8_640_000_000_000
+)
+
+// intlZone computes transitions of a time zone using Intl.DateTimeFormat by
+// probing offsets over time. Transitions, which don't change the offset, such
+// as renamed abbreviations, aren't found.
+type intlZone struct {
+	zoneName string         // IANA name of the zone
+	offsets  *js.Object     // formats the offset of the zone, if supported
+	parts    *js.Object     // formats all date and time fields of the zone otherwise
+	names    []*js.Object   // format the abbreviation of the zone in different locales
+	zones    []zone         // zones found so far
+	segments []*intlSegment // searched ranges of time in ascending order
+}
+
+// intlSegment is a range of time searched for transitions. Segments are
+// searched independently, so that looking up distant times, such as the zero
+// Time and the current time, doesn't require probing the time between them.
+type intlSegment struct {
+	lo, hi int64
+	first  int         // index of the zone in effect at lo
+	tx     []intlTrans // transitions within (lo, hi]
+}
+
+// intlTrans is a transition of an Intl time zone.
+type intlTrans struct {
+	when  int64
+	index int
+}
+
+func intlMissing() bool {
+	intl := js.Global.Get("Intl")
+	return intl == js.Undefined || intl.Get("DateTimeFormat") == js.Undefined ||
+		intl.Get("DateTimeFormat").Get("prototype").Get("formatToParts") == js.Undefined
+}
+
+// intlDefaultZone returns the name of the time zone of the environment or ""
+// if it is unknown.
+func intlDefaultZone() (name string) {
+	if intlMissing() {
+		return ""
+	}
+	tz := js.Global.Get("Intl").Get("DateTimeFormat").New().Call("resolvedOptions").Get("timeZone")
+	if tz == js.Undefined {
+		return ""
+	}
+	return tz.String()
+}
+
+// intlZoneNamed returns the time zone with the given name, or nil if the Intl
+// API is unavailable or doesn't know the zone.
+func intlZoneNamed(name string) *intlZone {
+	if z, ok := intlZones[name]; ok {
+		return z
+	}
+	z := newIntlZone(name)
+	if z != nil {
+		intlZones[name] = z
+	}
+	return z
+}
+
+// newIntlZone returns the time zone with the given name, or nil if the Intl API
+// is unavailable or doesn't know the zone.
+func newIntlZone(name string) (z *intlZone) {
+	if intlMissing() {
+		return nil
+	}
+	defer func() {
+		if recover() != nil {
+			z = nil // A RangeError is thrown for unknown time zones.
+		}
+	}()
+	dtf := js.Global.Get("Intl").Get("DateTimeFormat")
+	z = &intlZone{
+		zoneName: name,
+		parts: dtf.New("en-US", js.M{
+			"timeZone":  name,
+			"hourCycle": "h23",
+			"era":       "short",
+			"year":      "numeric",
+			"month":     "numeric",
+			"day":       "numeric",
+			"hour":      "numeric",
+			"minute":    "numeric",
+			"second":    "numeric",
+		}),
+	}
+	// The longOffset time zone name is faster to format and parse than all
+	// fields, but it isn't supported everywhere.
+	func() {
+		defer func() { recover() }()
+		z.offsets = dtf.New("en-US", js.M{"timeZone": name, "timeZoneName": "longOffset"})
+	}()
+	// Abbreviations are only known for the zones common in a locale, so more
+	// than one is tried.
+	for _, locale := range []string{"en-US", "en-GB", "en-AU", "en-IN", "en-SG", "en-ZA"} {
+		z.names = append(z.names, dtf.New(locale, js.M{"timeZone": name, "timeZoneName": "short"}))
+	}
+	return z
+}
+
+// install makes l use the time zone. The zones of l, which time.Parse looks
+// up abbreviations in, are the ones in effect around the current time.
+func (z *intlZone) install(l *Location) {
+	l.tx = []zoneTrans{{when: alpha}}
+	l.extend = intlPrefix + z.zoneName
+	sec, _, _ := now()
+	z.cover(sec)
+	l.zone = z.zones[:len(z.zones):len(z.zones)]
+}
+
+// clampIntl limits sec to the range of time supported by the Date type.
+func clampIntl(sec int64) int64 {
+	switch {
+	case sec > intlLimit:
+		return intlLimit
+	case sec < -intlLimit:
+		return -intlLimit
+	}
+	return sec
+}
+
+// offset returns the offset of the zone in seconds east of UTC at sec.
+func (z *intlZone) offset(sec int64) int {
+	sec = clampIntl(sec)
+	d := js.Global.Get("Date").New(sec * 1000)
+	if z.offsets != nil {
+		return parseGMTOffset(z.offsets.Call("format", d).String())
+	}
+	parts := z.parts.Call("formatToParts", d)
+	var year, month, day, hour, min, s int
+	bc := false
+	for i := 0; i < parts.Length(); i++ {
+		part := parts.Index(i)
+		value := part.Get("value")
+		switch part.Get("type").String() {
+		case "era":
+			bc = value.String() == "BC"
+		case "year":
+			year = value.Int()
+		case "month":
+			month = value.Int()
+		case "day":
+			day = value.Int()
+		case "hour":
+			hour = value.Int() % 24 // Some engines ignore hourCycle and use 24 for midnight.
+		case "minute":
+			min = value.Int()
+		case "second":
+			s = value.Int()
+		}
+	}
+	if bc {
+		year = 1 - year
+	}
+	return int(Date(year, Month(month), day, hour, min, s, 0, UTC).Unix() - sec)
+}
+
+// parseGMTOffset parses the offset from a date formatted with the longOffset
+// time zone name, such as "1/1/1970, GMT+05:30".
+func parseGMTOffset(s string) int {
+	i := len(s) - 3
+	for i > 0 && s[i:i+3] != "GMT" {
+		i--
+	}
+	s = s[i+3:]
+	sign := 1
+	switch {
+	case s == "":
+		return 0
+	case s[0] == '-':
+		sign, s = -1, s[1:]
+	case len(s) >= 3 && s[:3] == "\u2212": // Some engines use the minus sign.
+		sign, s = -1, s[3:]
+	case s[0] == '+':
+		s = s[1:]
+	}
+	offset, field := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == ':' {
+			offset, field = offset*60+field, 0
+			continue
+		}
+		field = field*10 + int(s[i]-'0')
+	}
+	offset = offset*60 + field
+	if len(s) <= 5 { // No seconds.
+		offset *= 60
+	}
+	return sign * offset
+}
+
+// zoneAt returns the index of the zone in effect at sec with the given offset,
+// adding the zone if necessary.
+func (z *intlZone) zoneAt(sec int64, offset int) int {
+	// Daylight saving time is assumed when the offset is larger than the
+	// smallest one in the same year.
+	year, _, _ := Unix(sec, 0).UTC().Date()
+	std := z.offset(Date(year, January, 1, 0, 0, 0, 0, UTC).Unix())
+	if o := z.offset(Date(year, July, 1, 0, 0, 0, 0, UTC).Unix()); o < std {
+		std = o
+	}
+	zn := zone{name: z.name(sec, offset), offset: offset, isDST: offset > std}
+	for i := range z.zones {
+		if z.zones[i] == zn {
+			return i
+		}
+	}
+	z.zones = append(z.zones, zn)
+	return len(z.zones) - 1
+}
+
+// name returns the abbreviation of the zone at sec. Like the IANA Time Zone
+// database, it uses the offset in place of unknown abbreviations.
+func (z *intlZone) name(sec int64, offset int) string {
+	d := js.Global.Get("Date").New(clampIntl(sec) * 1000)
+	for _, f := range z.names {
+		parts := f.Call("formatToParts", d)
+		for i := 0; i < parts.Length(); i++ {
+			if parts.Index(i).Get("type").String() == "timeZoneName" {
+				if name := parts.Index(i).Get("value").String(); isAlphabetic(name) {
+					return name
+				}
+			}
+		}
+	}
+	name := "+"
+	if offset < 0 {
+		name = "-"
+		offset = -offset
+	}
+	name += digits2(offset / secondsPerHour)
+	if m := offset % secondsPerHour / secondsPerMinute; m != 0 {
+		name += digits2(m)
+	}
+	return name
+}
+
+func isAlphabetic(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'A' || s[i] > 'Z' && s[i] < 'a' || s[i] > 'z' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// digits2 formats i in range [0,99] with two digits.
+func digits2(i int) string {
+	return smallsString[i*2 : i*2+2]
+}
+
+// scan returns the transitions within (from, to], given the offset at from.
+func (z *intlZone) scan(from, to int64, offset int) []intlTrans {
+	var tx []intlTrans
+	for from < to {
+		next := from + intlStep
+		if next > to {
+			next = to
+		}
+		if z.offset(next) == offset {
+			from = next
+			continue
+		}
+		// Bisect to the first second with a different offset.
+		for next-from > 1 {
+			mid := from + (next-from)/2
+			if z.offset(mid) == offset {
+				from = mid
+			} else {
+				next = mid
+			}
+		}
+		offset = z.offset(next)
+		tx = append(tx, intlTrans{when: next, index: z.zoneAt(next, offset)})
+		from = next
+	}
+	return tx
+}
+
+// cover returns the segment, which contains the transitions within a chunk
+// of sec, extending and merging segments as necessary.
+func (z *intlZone) cover(sec int64) *intlSegment {
+	if sec > 2*intlLimit {
+		sec = 2 * intlLimit
+	} else if sec < -2*intlLimit {
+		sec = -2 * intlLimit
+	}
+	lo := (sec/intlChunk - 1) * intlChunk
+	hi := (sec/intlChunk + 2) * intlChunk
+
+	// Find the first segment, which ends within or after the range.
+	i := 0
+	for i < len(z.segments) && z.segments[i].hi < lo {
+		i++
+	}
+	if i < len(z.segments) && z.segments[i].lo <= lo && hi <= z.segments[i].hi {
+		return z.segments[i]
+	}
+	if i == len(z.segments) || z.segments[i].lo > hi {
+		s := &intlSegment{lo: lo, hi: lo, first: z.zoneAt(lo, z.offset(lo))}
+		z.segments = append(z.segments[:i], append([]*intlSegment{s}, z.segments[i:]...)...)
+	}
+
+	s := z.segments[i]
+	if lo < s.lo {
+		offset := z.offset(lo)
+		first := z.zoneAt(lo, offset)
+		s.tx = append(z.scan(lo, s.lo, offset), s.tx...)
+		s.lo, s.first = lo, first
+	}
+	if hi > s.hi {
+		index := s.first
+		if len(s.tx) > 0 {
+			index = s.tx[len(s.tx)-1].index
+		}
+		s.tx = append(s.tx, z.scan(s.hi, hi, z.zones[index].offset)...)
+		s.hi = hi
+	}
+	// Merge the following segments, which the extended one reached.
+	for i+1 < len(z.segments) && z.segments[i+1].lo <= s.hi {
+		next := z.segments[i+1]
+		for _, t := range next.tx {
+			if t.when > s.hi {
+				s.tx = append(s.tx, t)
+			}
+		}
+		if next.hi > s.hi {
+			s.hi = next.hi
+		}
+		z.segments = append(z.segments[:i+1], z.segments[i+2:]...)
+	}
+	return s
+}
+
+// lookup is like Location.lookup for the zone. The start and the end of a
+// zone are only known within the segment searched around sec, otherwise the
+// zone is reported to be in effect forever.
+func (z *intlZone) lookup(sec int64) (name string, offset int, start, end int64, isDST bool) {
+	s := z.cover(sec)
+	// Binary search for the first transition after sec.
+	start, end = alpha, omega
+	index := s.first
+	lo, hi := 0, len(s.tx)
+	for lo < hi {
+		m := lo + (hi-lo)/2
+		if s.tx[m].when <= sec {
+			lo = m + 1
+		} else {
+			hi = m
+		}
+	}
+	if lo > 0 {
+		start, index = s.tx[lo-1].when, s.tx[lo-1].index
+	}
+	if lo < len(s.tx) {
+		end = s.tx[lo].when
+	}
+	zone := &z.zones[index]
+	return zone.name, zone.offset, start, end, zone.isDST
+}
@@ -122,15 +122,21 @@ var $recover = () => {
 };
 var $throw = err => { throw err; };
 
-var $noGoroutine = { asleep: false, exit: false, deferStack: [], panicStack: [] };
+var $noGoroutine = { id: 0, asleep: false, exit: false, deferStack: [], panicStack: [] };
 var $curGoroutine = $noGoroutine, $totalGoroutines = 0, $awakeGoroutines = 0, $checkForDeadlock = true, $exportedFunctions = 0;
-var $mainFinished = false;
+var $mainFinished = false, $goroutineIDCounter = 0;
+// Goroutines that have been started and haven't exited yet. Used by the
+// goroutine profile in runtime/pprof.
+var $liveGoroutines = new Set();
 var $go = (fun, args) => {
     $totalGoroutines++;
     $awakeGoroutines++;
     var $goroutine = () => {
         try {
             $curGoroutine = $goroutine;
+            if ($tracer !== null) {
+                $tracer.begin($goroutine);
+            }
             var r = fun(...args);
             if (r && r.$blk !== undefined) {
                 fun = () => { return r.$blk(); };
@@ -143,9 +149,13 @@ var $go = (fun, args) => {
                 throw err;
             }
         } finally {
+            if ($tracer !== null) {
+                $tracer.end($goroutine);
+            }
             $curGoroutine = $noGoroutine;
             if ($goroutine.exit) { /* also set by runtime.Goexit() */
                 $totalGoroutines--;
+                $liveGoroutines.delete($goroutine);
                 $goroutine.asleep = true;
             }
             if ($goroutine.asleep) {
@@ -159,10 +169,17 @@ var $go = (fun, args) => {
             }
         }
     };
+    $goroutine.id = ++$goroutineIDCounter;
+    $goroutine.fun = fun;
     $goroutine.asleep = false;
     $goroutine.exit = false;
     $goroutine.deferStack = [];
     $goroutine.panicStack = [];
+    $goroutine.waitReason = "";
+    $liveGoroutines.add($goroutine);
+    if ($tracer !== null) {
+        $tracer.instant($curGoroutine, "go", { goid: $goroutine.id });
+    }
     $schedule($goroutine);
 };
 
@@ -196,6 +213,9 @@ var $runScheduled = () => {
 
 var $schedule = goroutine => {
     if (goroutine.asleep) {
+        if ($tracer !== null) {
+            $tracer.instant(goroutine, "unblock", { by: $curGoroutine.id });
+        }
         goroutine.asleep = false;
         $awakeGoroutines++;
     }
@@ -207,19 +227,343 @@ var $schedule = goroutine => {
 
 var $setTimeout = (f, t) => {
     $awakeGoroutines++;
+    if ($tracer !== null) {
+        $tracer.instant($curGoroutine, "timer", { delay: t });
+    }
     return setTimeout(() => {
         $awakeGoroutines--;
+        if ($tracer !== null) {
+            $tracer.instant($noGoroutine, "timer fired", { delay: t });
+        }
         f();
     }, t);
 };
 
-var $block = () => {
+// $awaitPromise calls f with the value or the rejection reason of the promise p
+// once it settles. Like a timer, a pending promise may awaken the program, so it
+// counts as an awake goroutine for deadlock detection.
+var $awaitPromise = (p, f) => {
+    $awakeGoroutines++;
+    Promise.resolve(p).then(value => {
+        $awakeGoroutines--;
+        f(value, false);
+    }, reason => {
+        $awakeGoroutines--;
+        f(reason, true);
+    });
+};
+
+// $goPromise calls f in a new goroutine and returns a Promise of its result,
+// converted with externalize. A panic that reaches the top of the goroutine's
+// stack rejects the promise with the JavaScript error it is turned into.
+var $goPromise = (f, externalize) => new Promise((resolve, reject) => {
+    var step = call => {
+        try {
+            var r = call();
+            if (r && r.$blk !== undefined) {
+                // Resumed by $go like a blocked function.
+                return { $blk: () => step(() => r.$blk()) };
+            }
+            resolve(externalize(r));
+        } catch (err) {
+            if ($curGoroutine.exit) { /* runtime.Goexit() */
+                throw err;
+            }
+            reject(err);
+        }
+    };
+    $go(step, [f]);
+});
+
+// $recvAsync receives a value from chan outside of a goroutine, and calls f
+// with the value and whether it was sent, like a receive with ok does. It
+// returns a function that cancels the receive if it's still waiting.
+var $recvAsync = (chan, f) => {
+    var queuedSend = chan.$sendQueue.shift();
+    if (queuedSend !== undefined) {
+        chan.$buffer.push(queuedSend(false));
+    }
+    var bufferedValue = chan.$buffer.shift();
+    if (bufferedValue !== undefined) {
+        f(bufferedValue, true);
+        return () => { };
+    }
+    if (chan.$closed) {
+        f(chan.$elem.zero(), false);
+        return () => { };
+    }
+    var queueEntry = ([value, ok]) => f(value, ok);
+    chan.$recvQueue.push(queueEntry);
+    return () => {
+        var i = chan.$recvQueue.indexOf(queueEntry);
+        if (i !== -1) {
+            chan.$recvQueue.splice(i, 1);
+        }
+    };
+};
+
+// $sendAsync sends value on chan outside of a goroutine, and calls f once a
+// receiver took it, or with true if the channel is closed instead. If a
+// receiver is waiting already, f is called before the receiver resumes, so
+// that it can't block before f gets the chance to keep the program awake.
+var $sendAsync = (chan, value, f) => {
+    if (chan.$closed) {
+        f(true);
+        return;
+    }
+    var queuedRecv = chan.$recvQueue.shift();
+    if (queuedRecv !== undefined) {
+        f(false);
+        queuedRecv([value, true]);
+        return;
+    }
+    if (chan.$buffer.length < chan.$capacity) {
+        chan.$buffer.push(value);
+        f(false);
+        return;
+    }
+    chan.$sendQueue.push(closed => {
+        f(closed);
+        return value;
+    });
+};
+
+// $chanAsyncIterator returns an async iterator, which is also an async
+// iterable, of the values received from chan, converted with externalize. A
+// value is only received when JavaScript asks for the next one, so senders
+// block until then. The iteration ends when chan is closed, and stopping it
+// early, e.g. by leaving a for await loop, cancels the pending receives.
+//
+// JavaScript may wake the program by asking for the next value at any time, so
+// like an exported function, an iterator that isn't done yet keeps the deadlock
+// detector from reporting the goroutines blocked on chan.
+var $chanAsyncIterator = (chan, externalize) => {
+    var done = false;
+    var pending = new Set();
+    var finish = () => {
+        if (!done) {
+            done = true;
+            $exportedFunctions--;
+        }
+    };
+    $exportedFunctions++;
+    return {
+        next() {
+            return new Promise(resolve => {
+                if (done) {
+                    resolve({ value: undefined, done: true });
+                    return;
+                }
+                var cancel = $recvAsync(chan, (value, ok) => {
+                    pending.delete(cancel);
+                    if (!ok) {
+                        finish();
+                        resolve({ value: undefined, done: true });
+                        return;
+                    }
+                    resolve({ value: externalize(value), done: false });
+                });
+                pending.add(cancel);
+            });
+        },
+        return(value) {
+            finish();
+            pending.forEach(cancel => cancel());
+            pending.clear();
+            return Promise.resolve({ value, done: true });
+        },
+        [Symbol.asyncIterator]() {
+            return this;
+        },
+    };
+};
+
+// $chanReadableStream implements js.ReadableStreamOf for the channel in the
+// interface i. Like $chanAsyncIterator, it receives a value only when the
+// reader pulls one, and the stream keeps the deadlock detector off until it is
+// closed or canceled.
+var $chanReadableStream = i => {
+    if (i === $ifaceNil || i.constructor.kind !== $kindChan || i.constructor.sendOnly) {
+        $panic(new $String("js.ReadableStreamOf: argument is not a channel that can be received from"));
+    }
+    if (typeof ReadableStream !== "function") {
+        $panic(new $String("js.ReadableStreamOf: ReadableStream is not supported"));
+    }
+    var chan = i.$val, elem = i.constructor.elem, cancelRecv, done = false;
+    var finish = () => {
+        if (!done) {
+            done = true;
+            $exportedFunctions--;
+        }
+    };
+    $exportedFunctions++;
+    return new ReadableStream({
+        pull(controller) {
+            return new Promise(resolve => {
+                cancelRecv = $recvAsync(chan, (value, ok) => {
+                    cancelRecv = undefined;
+                    if (ok) {
+                        controller.enqueue($externalize(value, elem));
+                    } else {
+                        finish();
+                        controller.close();
+                    }
+                    resolve();
+                });
+            });
+        },
+        cancel() {
+            finish();
+            if (cancelRecv !== undefined) {
+                cancelRecv();
+            }
+        },
+    }, { highWaterMark: 0 });
+};
+
+// $asyncIteratorOf returns an iterator of the async iterable v, which may
+// also be a ReadableStream or a synchronous iterable, or undefined.
+var $asyncIteratorOf = v => {
+    if (v === null || v === undefined) {
+        return undefined;
+    }
+    if (typeof v[Symbol.asyncIterator] === "function") {
+        return v[Symbol.asyncIterator]();
+    }
+    if (typeof v.getReader === "function") {
+        var reader = v.getReader();
+        return { next: () => reader.read(), return: () => reader.cancel() };
+    }
+    if (typeof v[Symbol.iterator] === "function" && typeof v !== "string") {
+        return v[Symbol.iterator]();
+    }
+    return undefined;
+};
+
+// $iteratorToChan sends the values of the async iterator on chan, converted
+// with internalize, and closes chan at the end. The next value is requested
+// once a receiver took the previous one. done is called with the error the
+// iteration failed with, or null, right before chan is closed. Closing chan on
+// the receiving side stops the iteration.
+var $iteratorToChan = (iterator, chan, internalize, done) => {
+    var finish = err => {
+        done(err);
+        if (!chan.$closed) {
+            $close(chan);
+        }
+    };
+    var step = () => {
+        var next;
+        try {
+            next = iterator.next();
+        } catch (err) {
+            finish(err);
+            return;
+        }
+        $awaitPromise(next, (result, rejected) => {
+            if (rejected) {
+                finish(result);
+                return;
+            }
+            if (result.done) {
+                finish(null);
+                return;
+            }
+            var value;
+            try {
+                value = internalize(result.value);
+            } catch (err) {
+                finish(err);
+                return;
+            }
+            $sendAsync(chan, value, closed => {
+                if (!closed) {
+                    step();
+                    return;
+                }
+                if (typeof iterator.return === "function") {
+                    iterator.return();
+                }
+                done(null);
+            });
+        });
+    };
+    step();
+};
+
+var $block = (reason = "block") => {
     if ($curGoroutine === $noGoroutine) {
         $throwRuntimeError("cannot block in JavaScript callback, fix by wrapping code in goroutine");
     }
+    if ($tracer !== null) {
+        $tracer.instant($curGoroutine, reason);
+    }
+    $curGoroutine.waitReason = reason;
     $curGoroutine.asleep = true;
 };
 
+// Goroutine scheduling tracer, which is active between runtime.StartTrace() and
+// runtime.StopTrace() calls. It records events in the Chrome Trace Event
+// format, which can be viewed with chrome://tracing or https://ui.perfetto.dev,
+// with a separate track for each goroutine. Track 0 represents JavaScript code
+// running outside of any goroutine, such as callbacks and timers.
+var $tracer = null;
+var $startTracer = () => {
+    var events = [];
+    var tracks = new Set();
+    var now = () => { return performance.now() * 1000; }; /* microseconds */
+    var startTime = now();
+    var track = goroutine => {
+        var id = goroutine.id;
+        if (!tracks.has(id)) {
+            tracks.add(id);
+            var name = "JavaScript";
+            if (id !== 0) {
+                name = "goroutine " + id;
+                var funName = goroutine.fun && goroutine.fun.name.replace(/\$\d+$/, "");
+                if (funName) {
+                    name += " [" + funName + "]";
+                }
+            }
+            events.push({ name: "thread_name", ph: "M", pid: 1, tid: id, args: { name: name } });
+            events.push({ name: "thread_sort_index", ph: "M", pid: 1, tid: id, args: { sort_index: id } });
+        }
+        return id;
+    };
+    $tracer = {
+        /* Records an instantaneous event on the goroutine's track. */
+        instant(goroutine, name, args = {}) {
+            events.push({ name: name, ph: "i", s: "t", ts: now(), pid: 1, tid: track(goroutine), args: args });
+        },
+        /* Marks the moment the goroutine starts or resumes execution. */
+        begin(goroutine) {
+            goroutine.traceStart = now();
+        },
+        /* Records a slice of time the goroutine has been running for. */
+        end(goroutine) {
+            var start = goroutine.traceStart === undefined || goroutine.traceStart < startTime ? startTime : goroutine.traceStart;
+            var state = goroutine.exit ? "exit" : (goroutine.asleep ? "blocked" : "runnable");
+            events.push({ name: "running", ph: "X", ts: start, dur: now() - start, pid: 1, tid: track(goroutine), args: { next: state } });
+        },
+        /* Records the beginning or the end of a duration event on the goroutine's track. */
+        duration(goroutine, phase, name, args = {}) {
+            events.push({ name: name, ph: phase, ts: now(), pid: 1, tid: track(goroutine), args: args });
+        },
+        /* Records an async event, which may span several goroutines. */
+        async(goroutine, phase, id, name, args = {}) {
+            events.push({ name: name, cat: "task", ph: phase, id: id, ts: now(), pid: 1, tid: track(goroutine), args: args });
+        },
+        /* Finishes tracing and returns the trace as a JSON string. */
+        stop() {
+            if ($curGoroutine !== $noGoroutine) {
+                this.end($curGoroutine);
+            }
+            $tracer = null;
+            return JSON.stringify({ traceEvents: events, displayTimeUnit: "ms" });
+        },
+    };
+};
+
 var $restore = (context, params) => {
     if (context !== undefined && context.$blk !== undefined) {
         return context;
@@ -248,7 +592,7 @@ var $send = (chan, value) => {
         $schedule(thisGoroutine);
         return value;
     });
-    $block();
+    $block("chan send");
     return {
         $blk() {
             if (closedDuringSend) {
@@ -277,7 +621,7 @@ var $recv = chan => {
         $schedule(thisGoroutine);
     };
     chan.$recvQueue.push(queueEntry);
-    $block();
+    $block("chan receive");
     return f;
 };
 var $close = chan => {
@@ -285,6 +629,9 @@ var $close = chan => {
         $throwRuntimeError("close of closed channel");
     }
     chan.$closed = true;
+    if ($tracer !== null) {
+        $tracer.instant($curGoroutine, "chan close");
+    }
     while (true) {
         var queuedSend = chan.$sendQueue.shift();
         if (queuedSend === undefined) {
@@ -384,6 +731,6 @@ var $select = comms => {
             }
         })(i);
     }
-    $block();
+    $block("select");
     return f;
 };
@@ -1,6 +1,63 @@
 var $jsObjectPtr, $jsErrorPtr;
 
+// $findMethod returns the exported method of the method set of type t with the
+// given name and signature, or undefined. Results are cached, since it is used
+// to look for the js.Marshaler and js.Unmarshaler methods on every conversion.
+var $methodCache = new Map();
+var $findMethod = (t, name, signature) => {
+    if (!t.named && t.kind !== $kindPtr && t.kind !== $kindStruct) {
+        return undefined;
+    }
+    var methods = $methodCache.get(t);
+    if (methods === undefined) {
+        methods = new Map();
+        $methodCache.set(t, methods);
+    }
+    if (!methods.has(name)) {
+        methods.set(name, $methodSet(t).find(m => m.name === name && m.pkg === "" && m.typ.string === signature));
+    }
+    return methods.get(name);
+};
+
+// $jsMarshaler returns the ExternalizeJS method if type t implements
+// js.Marshaler, or undefined.
+var $jsMarshaler = t => {
+    if (t.kind === $kindInterface) {
+        return undefined;
+    }
+    return $findMethod(t, "ExternalizeJS", "func() *js.Object");
+};
+
+// $jsUnmarshaler returns the InternalizeJS method if t is a pointer type or
+// pointer to t implements js.Unmarshaler, or undefined.
+var $jsUnmarshaler = t => {
+    if (t.kind === $kindInterface) {
+        return undefined;
+    }
+    return $findMethod(t.kind === $kindPtr ? t : $ptrType(t), "InternalizeJS", "func(*js.Object) error");
+};
+
+// $newPointer returns a pointer of type t to a new zero value. Like for
+// new(T), pointers to structs and arrays are the values themselves.
+var $newPointer = t => {
+    switch (t.elem.kind) {
+        case $kindStruct:
+        case $kindArray:
+            return t.elem.zero();
+    }
+    return $newDataPointer(t.elem.zero(), t);
+};
+
+// $callInternalizeJS calls the js.Unmarshaler method m of the pointer p of
+// type t with the JavaScript value v, and returns the error.
+var $callInternalizeJS = (p, t, m, v) => {
+    return (t.wrapped ? new t(p) : p)[m.prop](v);
+};
+
 var $needsExternalization = t => {
+    if ($jsMarshaler(t) !== undefined) {
+        return true;
+    }
     switch (t.kind) {
         case $kindBool:
         case $kindInt:
@@ -24,6 +81,13 @@ var $externalize = (v, t, makeWrapper) => {
     if (t === $jsObjectPtr) {
         return v;
     }
+    var marshaler = $jsMarshaler(t);
+    if (marshaler !== undefined) {
+        if (t.kind === $kindPtr && v === t.nil) {
+            return null;
+        }
+        return (t.wrapped ? new t(v) : v)[marshaler.prop]();
+    }
     switch (t.kind) {
         case $kindBool:
         case $kindInt:
@@ -46,6 +110,11 @@ var $externalize = (v, t, makeWrapper) => {
                 return $mapArray(v, e => { return $externalize(e, t.elem, makeWrapper); });
             }
             return v;
+        case $kindChan:
+            if (t.sendOnly) {
+                break;
+            }
+            return $chanAsyncIterator(v, e => $externalize(e, t.elem, makeWrapper));
         case $kindFunc:
             return $externalizeFunction(v, t, false, makeWrapper);
         case $kindInterface:
@@ -149,13 +218,17 @@ var $externalize = (v, t, makeWrapper) => {
     $throwRuntimeError("cannot externalize " + t.string);
 };
 
-var $externalizeFunction = (v, t, passThis, makeWrapper) => {
+// $externalizeFunction returns a JavaScript function calling v. If async is
+// true, the function calls v in a new goroutine and returns a Promise instead,
+// so v may block.
+var $externalizeFunction = (v, t, passThis, makeWrapper, async = false) => {
     if (v === $throwNilPointerError) {
         return null;
     }
-    if (v.$externalizeWrapper === undefined) {
+    var wrapper = async ? "$externalizeAsyncWrapper" : "$externalizeWrapper";
+    if (v[wrapper] === undefined) {
         $checkForDeadlock = false;
-        v.$externalizeWrapper = function () {
+        v[wrapper] = function () {
             var args = [];
             for (var i = 0; i < t.params.length; i++) {
                 if (t.variadic && i === t.params.length - 1) {
@@ -168,21 +241,66 @@ var $externalizeFunction = (v, t, passThis, makeWrapper) => {
                 }
                 args.push($internalize(arguments[i], t.params[i], makeWrapper));
             }
-            var result = v.apply(passThis ? this : undefined, args);
-            switch (t.results.length) {
-                case 0:
-                    return;
-                case 1:
-                    return $externalize($copyIfRequired(result, t.results[0]), t.results[0], makeWrapper);
-                default:
-                    for (var i = 0; i < t.results.length; i++) {
-                        result[i] = $externalize($copyIfRequired(result[i], t.results[i]), t.results[i], makeWrapper);
-                    }
-                    return result;
+            var call = () => v.apply(passThis ? this : undefined, args);
+            var externalizeResult = result => {
+                switch (t.results.length) {
+                    case 0:
+                        return;
+                    case 1:
+                        return $externalize($copyIfRequired(result, t.results[0]), t.results[0], makeWrapper);
+                    default:
+                        for (var i = 0; i < t.results.length; i++) {
+                            result[i] = $externalize($copyIfRequired(result[i], t.results[i]), t.results[i], makeWrapper);
+                        }
+                        return result;
+                }
+            };
+            if (async) {
+                return $goPromise(call, externalizeResult);
             }
+            return externalizeResult(call());
         };
     }
-    return v.$externalizeWrapper;
+    return v[wrapper];
+};
+
+// $externalizeAsync externalizes the function held by the interface value i
+// with $externalizeFunction in async mode.
+var $externalizeAsync = i => {
+    if (i === $ifaceNil || i.constructor.kind !== $kindFunc) {
+        $panic(new $String("js.Async: argument is not a function"));
+    }
+    return $externalizeFunction(i.$val, i.constructor, false, undefined, true);
+};
+
+// $typedArrayOf implements js.TypedArrayOf, it returns a view of the memory of
+// the slice in the interface i.
+var $typedArrayOf = i => {
+    if (i === $ifaceNil || i.constructor.kind !== $kindSlice || i.constructor.nativeArray === Array) {
+        $panic(new $String("js.TypedArrayOf: argument is not a slice of numbers"));
+    }
+    var s = i.$val;
+    return s.$array.subarray(s.$offset, s.$offset + s.$length);
+};
+
+// $sliceOfTypedArray implements js.SliceOfTypedArray, it sets the slice
+// pointed to by the pointer in the interface i to a slice over the memory of
+// the typed array or ArrayBuffer a.
+var $sliceOfTypedArray = (a, i) => {
+    if (i === $ifaceNil || i.constructor.kind !== $kindPtr || i.constructor.elem.kind !== $kindSlice ||
+        i.constructor.elem.nativeArray === Array || i.$val === i.constructor.nil) {
+        $panic(new $String("js.SliceOfTypedArray: argument is not a pointer to a slice of numbers"));
+    }
+    var t = i.constructor.elem;
+    if (a instanceof ArrayBuffer) {
+        a = new t.nativeArray(a);
+    } else if (t.nativeArray === Uint8Array && a instanceof Uint8ClampedArray) {
+        a = new Uint8Array(a.buffer, a.byteOffset, a.length);
+    } else if (!(a instanceof t.nativeArray)) {
+        var name = a === null || a === undefined ? String(a) : a.constructor.name;
+        $panic(new $String("js.SliceOfTypedArray: cannot use " + name + " as " + t.string + ", need " + t.nativeArray.name));
+    }
+    i.$val.$set(new t(a));
 };
 
 var $internalize = (v, t, recv, seen, makeWrapper) => {
@@ -195,12 +313,26 @@ var $internalize = (v, t, recv, seen, makeWrapper) => {
     if (v && v.__internal_object__ !== undefined) {
         return $assertType(v.__internal_object__, t, false);
     }
+    var unmarshaler = $jsUnmarshaler(t);
+    if (unmarshaler !== undefined) {
+        if (t.kind === $kindPtr && (v === null || v === undefined)) {
+            return t.nil;
+        }
+        var pt = t.kind === $kindPtr ? t : $ptrType(t);
+        var p = $newPointer(pt);
+        var err = $callInternalizeJS(p, pt, unmarshaler, v);
+        if (err !== $ifaceNil) {
+            $panic(err);
+        }
+        return t.kind === $kindPtr || t.kind === $kindStruct || t.kind === $kindArray ? p : p.$get();
+    }
     var timePkg = $packages["time"];
     if (timePkg !== undefined && t === timePkg.Time) {
         if (!(v !== null && v !== undefined && v.constructor === Date)) {
             $throwRuntimeError("cannot internalize time.Time from " + typeof v + ", must be Date");
         }
-        return timePkg.Unix(new $Int64(0, 0), new $Int64(0, v.getTime() * 1000000));
+        var sec = Math.floor(v.getTime() / 1000);
+        return timePkg.Unix(new $Int64(0, sec), new $Int64(0, (v.getTime() - sec * 1000) * 1000000));
     }
 
     // Cache for values we've already internalized in order to deal with circular
@@ -243,6 +375,17 @@ var $internalize = (v, t, recv, seen, makeWrapper) => {
                 $throwRuntimeError("got array with wrong size from JavaScript native");
             }
             return $mapArray(v, e => { return $internalize(e, t.elem, makeWrapper); });
+        case $kindChan:
+            if (v === null || v === undefined) {
+                return t.zero();
+            }
+            var iterator = t.sendOnly ? undefined : $asyncIteratorOf(v);
+            if (iterator === undefined) {
+                break;
+            }
+            var chan = new $Chan(t.elem, 0);
+            $iteratorToChan(iterator, chan, e => $internalize(e, t.elem, makeWrapper), err => { });
+            return chan;
         case $kindFunc:
             return function () {
                 var args = [];
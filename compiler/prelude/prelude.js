@@ -33,7 +33,7 @@ if (!$global.fs) {
     var outputBuf = "";
     var decoder = new TextDecoder("utf-8");
     $global.fs = {
-        constants: { O_WRONLY: -1, O_RDWR: -1, O_CREAT: -1, O_TRUNC: -1, O_APPEND: -1, O_EXCL: -1 }, // unused
+        constants: { O_WRONLY: 1, O_RDWR: 2, O_CREAT: 64, O_TRUNC: 512, O_APPEND: 1024, O_EXCL: 128 }, // Linux values, used by the memfs package
         writeSync: function writeSync(fd, buf) {
             // check for a hook to redirect `os.Stdout` and `os.Stderr` to a different output
             // instead of the default `console.log`. This is useful for redirecting methods like
@@ -75,6 +75,7 @@ var $throwRuntimeError; /* set by package "runtime" */
 var $throwNilPointerError = () => { $throwRuntimeError("invalid memory address or nil pointer dereference"); };
 var $call = (fn, rcvr, args) => { return fn.apply(rcvr, args); };
 var $makeFunc = fn => { return function(...args) { return $externalize(fn(this, new ($sliceType($jsObjectPtr))($global.Array.prototype.slice.call(args, []))), $emptyInterface); }; };
+var $makeAsyncFunc = fn => { return function(...args) { return $goPromise(() => fn(this, new ($sliceType($jsObjectPtr))(args)), r => $externalize(r, $emptyInterface)); }; };
 var $unused = v => { };
 var $print = console.log;
 // Under Node we can emulate print() more closely by avoiding a newline.
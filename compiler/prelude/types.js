@@ -642,10 +642,22 @@ var $ptrType = elem => {
     return typ;
 };
 
+// Heap allocation counters reported by runtime.ReadMemStats(). They are only
+// updated when the program is compiled with the "memstats" experiment enabled,
+// see internal/experiments.
+var $memStats = { enabled: false, mallocs: 0, totalAlloc: 0 };
+var $recordAlloc = size => {
+    $memStats.mallocs++;
+    $memStats.totalAlloc += size;
+};
+
 var $newDataPointer = (data, constructor) => {
     if (constructor.elem.kind === $kindStruct) {
         return data;
     }
+    if ($memStats.enabled) {
+        $recordAlloc(constructor.elem.size);
+    }
     return new constructor(() => { return data; }, v => { data = v; });
 };
 
@@ -679,6 +691,9 @@ var $makeSlice = (typ, length, capacity = length) => {
     if (capacity < 0 || capacity < length || capacity > 2147483647) {
         $throwRuntimeError("makeslice: cap out of range");
     }
+    if ($memStats.enabled) {
+        $recordAlloc(typ.elem.size * capacity);
+    }
     var array = new typ.nativeArray(capacity);
     if (typ.nativeArray === Array) {
         for (var i = 0; i < capacity; i++) {
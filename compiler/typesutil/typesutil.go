@@ -18,6 +18,42 @@ func IsJsObject(t types.Type) bool {
 	return isNamed && IsJsPackage(named.Obj().Pkg()) && named.Obj().Name() == "Object"
 }
 
+// IsJsMarshaler returns true if values of type t implement js.Marshaler, so
+// they are converted to JavaScript by their ExternalizeJS method.
+func IsJsMarshaler(t types.Type) bool {
+	sig := exportedMethod(t, "ExternalizeJS")
+	return sig != nil && sig.Params().Len() == 0 && sig.Results().Len() == 1 && IsJsObject(sig.Results().At(0).Type())
+}
+
+// IsJsUnmarshaler returns true if t is a pointer type or pointer to t that
+// implements js.Unmarshaler, so values of type t are converted from
+// JavaScript by the InternalizeJS method.
+func IsJsUnmarshaler(t types.Type) bool {
+	if _, isPtr := t.Underlying().(*types.Pointer); !isPtr {
+		t = types.NewPointer(t)
+	}
+	sig := exportedMethod(t, "InternalizeJS")
+	return sig != nil && sig.Params().Len() == 1 && IsJsObject(sig.Params().At(0).Type()) &&
+		sig.Results().Len() == 1 && types.Identical(sig.Results().At(0).Type(), types.Universe.Lookup("error").Type())
+}
+
+// exportedMethod returns the signature of the named method in the method set
+// of t, or nil if there is none. Methods of interfaces are ignored.
+func exportedMethod(t types.Type, name string) *types.Signature {
+	if types.IsInterface(t) {
+		return nil
+	}
+	sel := types.NewMethodSet(t).Lookup(nil, name)
+	if sel == nil {
+		return nil
+	}
+	sig := sel.Type().(*types.Signature)
+	if sig.Variadic() {
+		return nil
+	}
+	return sig
+}
+
 // RecvType returns a named type of a method receiver, or nil if it's not a method.
 //
 // For methods on a pointer receiver, the underlying named type is returned.
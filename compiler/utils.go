@@ -200,23 +200,12 @@ func (fc *funcContext) translateSelection(sel typesutil.Selection, pos token.Pos
 		}
 		s := t.Underlying().(*types.Struct)
 		if jsTag := getJsTag(s.Tag(index)); jsTag != "" {
-			jsFieldName := s.Field(index).Name()
-			for {
-				fields = append(fields, fieldName(s, 0))
-				ft := fc.fieldType(s, 0)
-				if typesutil.IsJsObject(ft) {
-					return fields, jsTag
-				}
-				ft = ft.Underlying()
-				if ptr, ok := ft.(*types.Pointer); ok {
-					ft = ptr.Elem().Underlying()
-				}
-				var ok bool
-				s, ok = ft.(*types.Struct)
-				if !ok || s.NumFields() == 0 {
-					fc.pkgCtx.errList = append(fc.pkgCtx.errList, types.Error{Fset: fc.pkgCtx.fileSet, Pos: pos, Msg: fmt.Sprintf("could not find field with type *js.Object for 'js' tag of field '%s'", jsFieldName), Soft: true})
-					return nil, ""
-				}
+			if objFields, ok := fc.jsObjectFields(s); ok {
+				return append(fields, objFields...), jsTag
+			}
+			if hasJsObjectField(s, map[*types.Struct]bool{}) {
+				fc.pkgCtx.errList = append(fc.pkgCtx.errList, types.Error{Fset: fc.pkgCtx.fileSet, Pos: pos, Msg: fmt.Sprintf("could not find field with type *js.Object for 'js' tag of field '%s'", s.Field(index).Name()), Soft: true})
+				return nil, ""
 			}
 		}
 		fields = append(fields, fieldName(s, index))
@@ -225,6 +214,59 @@ func (fc *funcContext) translateSelection(sel typesutil.Selection, pos token.Pos
 	return fields, ""
 }
 
+// jsObjectFields returns the names of the first fields that lead from struct s
+// to a *js.Object field. Fields of such structs with a "js" tag are properties
+// of the object, while in other structs the tag is only used by js.Marshal.
+func (fc *funcContext) jsObjectFields(s *types.Struct) ([]string, bool) {
+	var fields []string
+	seen := map[*types.Struct]bool{}
+	for s.NumFields() > 0 && !seen[s] {
+		seen[s] = true
+		fields = append(fields, fieldName(s, 0))
+		ft := fc.fieldType(s, 0)
+		if typesutil.IsJsObject(ft) {
+			return fields, true
+		}
+		ft = ft.Underlying()
+		if ptr, ok := ft.(*types.Pointer); ok {
+			ft = ptr.Elem().Underlying()
+		}
+		next, ok := ft.(*types.Struct)
+		if !ok {
+			break
+		}
+		s = next
+	}
+	return nil, false
+}
+
+// hasJsObjectField returns true if struct s has a *js.Object field, directly
+// or in an embedded struct. If it isn't the first field, the "js" tags of the
+// fields of s are likely meant to be properties of the object nonetheless.
+func hasJsObjectField(s *types.Struct, seen map[*types.Struct]bool) bool {
+	if seen[s] {
+		return false
+	}
+	seen[s] = true
+	for i := 0; i < s.NumFields(); i++ {
+		f := s.Field(i)
+		if typesutil.IsJsObject(f.Type()) {
+			return true
+		}
+		if !f.Embedded() {
+			continue
+		}
+		ft := f.Type().Underlying()
+		if ptr, ok := ft.(*types.Pointer); ok {
+			ft = ptr.Elem().Underlying()
+		}
+		if es, ok := ft.(*types.Struct); ok && hasJsObjectField(es, seen) {
+			return true
+		}
+	}
+	return false
+}
+
 var nilObj = types.Universe.Lookup("nil")
 
 func (fc *funcContext) zeroValue(ty types.Type) ast.Expr {
@@ -644,7 +686,7 @@ func (fc *funcContext) externalize(s string, t types.Type) string {
 	}
 	switch u := t.Underlying().(type) {
 	case *types.Basic:
-		if isNumeric(u) && !is64Bit(u) && !isComplex(u) {
+		if isNumeric(u) && !is64Bit(u) && !isComplex(u) && !typesutil.IsJsMarshaler(t) {
 			return s
 		}
 		if u.Kind() == types.UntypedNil {
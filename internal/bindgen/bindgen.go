@@ -0,0 +1,73 @@
+// Package bindgen generates Go bindings for JavaScript APIs from TypeScript
+// declaration files, like lib.dom.d.ts or the types of npm packages.
+//
+// Interfaces and classes become structs embedding *js.Object, with a field
+// tagged with `js:"..."` for each property and a method for each method.
+// Properties holding other interfaces or classes, or arrays of them, are read
+// by a method of the same name instead, which returns nil for null or
+// undefined, and written by a method prefixed with Set unless they are
+// readonly.
+// Methods returning a Promise block until it settles and return its value and
+// an error. Interfaces that only describe a function, like event listeners,
+// become Go function types. Other declarations map to Go as follows:
+//
+//   - functions become functions of the same name;
+//   - variables become functions prefixed with Global that return their value;
+//   - constructors become functions prefixed with New, and static methods and
+//     properties functions prefixed with the name of their class, the latter
+//     returning the value of the property;
+//   - string, number and boolean become string, float64 and bool, and arrays
+//     slices;
+//   - unions of null or undefined and another type become that type;
+//   - type aliases and enums become the types they stand for;
+//   - types that have no close Go equivalent, like other unions, become
+//     *js.Object.
+//
+// Overloaded methods are bound by the last of their declarations, which
+// usually is the most general one. Optional parameters are passed with a
+// variadic parameter.
+package bindgen
+
+import (
+	"fmt"
+)
+
+// Options configure the generated bindings.
+type Options struct {
+	// Package is the name of the generated package.
+	Package string
+	// Module is the name of the module bindings are generated for, which is
+	// loaded with require(). Declarations are looked up in the global object
+	// if it's empty.
+	Module string
+}
+
+// File is a TypeScript declaration file.
+type File struct {
+	Name    string
+	Content string
+}
+
+// Generate returns the formatted source of a Go package with bindings for the
+// declarations in files. Declarations that can't be bound, for example
+// because their names clash in Go, are skipped and described by warnings.
+func Generate(files []File, opts Options) (src []byte, warnings []string, err error) {
+	if opts.Package == "" {
+		return nil, nil, fmt.Errorf("missing package name")
+	}
+	g := &generator{opts: opts, types: map[string]*typeInfo{}, names: map[string]bool{}}
+	var names []string
+	for _, f := range files {
+		decls, err := parse(f.Name, f.Content)
+		if err != nil {
+			return nil, nil, err
+		}
+		g.collect(decls)
+		names = append(names, f.Name)
+	}
+	src, err = g.generate(names)
+	if err != nil {
+		return nil, nil, err
+	}
+	return src, g.warnings, nil
+}
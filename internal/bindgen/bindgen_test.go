@@ -0,0 +1,109 @@
+package bindgen
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/gopherjs/gopherjs/internal/srctesting"
+)
+
+var update = flag.Bool("update", false, "update the golden files of TestGenerate")
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		file string
+		opts Options
+	}{
+		{file: "dom.d.ts", opts: Options{Package: "dom"}},
+		{file: "module.d.ts", opts: Options{Package: "kvstore", Module: "kvstore"}},
+	}
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			path := filepath.Join("testdata", test.file)
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Failed to read fixture: %s", err)
+			}
+			got, warnings, err := Generate([]File{{Name: path, Content: string(content)}}, test.opts)
+			if err != nil {
+				t.Fatalf("Got: Generate() returned error: %s. Want: no error.", err)
+			}
+			if len(warnings) != 0 {
+				t.Errorf("Got: Generate() returned warnings %q. Want: no warnings.", warnings)
+			}
+
+			golden := strings.TrimSuffix(path, ".d.ts") + ".go.golden"
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatalf("Failed to update golden file: %s", err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("Failed to read golden file: %s", err)
+			}
+			if diff := cmp.Diff(string(want), string(got)); diff != "" {
+				t.Errorf("Generated bindings differ from %s (-want,+got):\n%s", golden, diff)
+			}
+
+			// The bindings must compile against the js package.
+			f := srctesting.New(t)
+			jsSrc, err := os.ReadFile(filepath.Join("..", "..", "js", "js.go"))
+			if err != nil {
+				t.Fatalf("Failed to read js package: %s", err)
+			}
+			f.Check("github.com/gopherjs/gopherjs/js", f.Parse("js.go", string(jsSrc)))
+			f.Check("example.com/"+test.opts.Package, f.Parse("bindings.go", string(got)))
+		})
+	}
+}
+
+func TestGenerateWarnings(t *testing.T) {
+	src := `
+		interface A {
+			foo_bar: string;
+			fooBar: number;
+			object: number;
+			parent: A | null;
+			setParent: string;
+		}
+	`
+	_, warnings, err := Generate([]File{{Name: "a.d.ts", Content: src}}, Options{Package: "a"})
+	if err != nil {
+		t.Fatalf("Got: Generate() returned error: %s. Want: no error.", err)
+	}
+	want := []string{
+		"a.d.ts:4:4: skipping property A.fooBar, its Go name FooBar is taken",
+		"a.d.ts:5:4: skipping property A.object, its Go name Object is taken",
+		"a.d.ts:6:4: skipping setter of property A.parent, its Go name SetParent is taken",
+	}
+	if diff := cmp.Diff(want, warnings); diff != "" {
+		t.Errorf("Generate() returned different warnings (-want,+got):\n%s", diff)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{src: "interface A { a: string", want: `x.d.ts:1:24: unexpected end of file in member list`},
+		{src: "declare var x: ;", want: `x.d.ts:1:16: unexpected ";" in type`},
+		{src: "interface { }", want: `x.d.ts:1:11: expected identifier, found "{"`},
+		{src: "declare function f(a: string;", want: `x.d.ts:1:29: expected ")", found ";"`},
+		{src: "/* unterminated", want: `x.d.ts:1:1: unterminated comment`},
+		{src: "type A = 'a\n';", want: `x.d.ts:1:10: unterminated string`},
+		{src: "= 1;", want: `x.d.ts:1:1: unexpected "="`},
+	}
+	for _, test := range tests {
+		_, err := parse("x.d.ts", test.src)
+		if err == nil || err.Error() != test.want {
+			t.Errorf("Got: parse(%q) returned error %v. Want: %s.", test.src, err, test.want)
+		}
+	}
+}
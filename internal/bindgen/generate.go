@@ -0,0 +1,995 @@
+package bindgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+type goKind int
+
+const (
+	goNone goKind = iota // No value, like void results.
+	goString
+	goFloat
+	goBool
+	goObject
+	goStruct // Pointer to a generated struct.
+	goSlice
+	goFunc
+)
+
+// goType is the Go type a TypeScript type is mapped to.
+type goType struct {
+	kind     goKind
+	expr     string
+	elem     *goType // Element type of slices.
+	nullable bool    // Whether JavaScript values may be null or undefined.
+}
+
+var (
+	noType     = &goType{kind: goNone}
+	stringType = &goType{kind: goString, expr: "string"}
+	floatType  = &goType{kind: goFloat, expr: "float64"}
+	boolType   = &goType{kind: goBool, expr: "bool"}
+	objectType = &goType{kind: goObject, expr: "*js.Object"}
+	anyType    = &goType{kind: goObject, expr: "any"}
+)
+
+// zero returns the zero value of t.
+func (t *goType) zero() string {
+	switch t.kind {
+	case goString:
+		return `""`
+	case goFloat:
+		return "0"
+	case goBool:
+		return "false"
+	default:
+		return "nil"
+	}
+}
+
+// typeInfo holds the declarations of a named type. Interfaces can be declared
+// several times, in which case their members are merged.
+type typeInfo struct {
+	name   string // Qualified by namespaces.
+	goName string
+	decls  []*decl
+}
+
+func (ti *typeInfo) kind() declKind { return ti.decls[0].kind }
+
+// isStruct reports whether ti is bound as a struct embedding *js.Object.
+func (ti *typeInfo) isStruct() bool {
+	return (ti.kind() == declInterface || ti.kind() == declClass) && ti.callSignature() == nil
+}
+
+// callSignature returns the signature of interfaces that only describe a
+// function, like event listeners, which are bound as Go functions.
+func (ti *typeInfo) callSignature() *signature {
+	if ti.kind() != declInterface {
+		return nil
+	}
+	var sig *signature
+	for _, d := range ti.decls {
+		if len(d.extends) > 0 {
+			return nil
+		}
+		for _, m := range d.members {
+			if m.kind != memberCall {
+				return nil
+			}
+			sig = m.sig
+		}
+	}
+	return sig
+}
+
+// scope is the context TypeScript type names are resolved in.
+type scope struct {
+	ns         []string
+	typeParams map[string]tsType // Type parameters and their constraints.
+	this       *goType           // What the `this` type refers to, if anything.
+	outer      *scope
+}
+
+func (sc *scope) with(params []*typeParam) *scope {
+	if len(params) == 0 {
+		return sc
+	}
+	inner := &scope{ns: sc.ns, typeParams: map[string]tsType{}, this: sc.this, outer: sc}
+	for _, tp := range params {
+		inner.typeParams[tp.name] = tp.constraint
+	}
+	return inner
+}
+
+func (sc *scope) typeParam(name string) (constraint tsType, ok bool) {
+	for s := sc; s != nil; s = s.outer {
+		if c, ok := s.typeParams[name]; ok {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+type generator struct {
+	opts     Options
+	buf      bytes.Buffer
+	warnings []string
+	types    map[string]*typeInfo
+	order    []*typeInfo
+	values   []*decl // Variables and functions.
+	names    map[string]bool
+	depth    int
+}
+
+func (g *generator) warnf(pos position, format string, args ...any) {
+	g.warnings = append(g.warnings, fmt.Sprintf("%s: %s", pos, fmt.Sprintf(format, args...)))
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// qualify joins the namespaces ns and name.
+func qualify(ns []string, name string) string {
+	return strings.Join(append(append([]string(nil), ns...), name), ".")
+}
+
+// declare returns a unique exported Go name for the package level based on
+// the TypeScript names.
+func (g *generator) declare(names ...string) string {
+	var name string
+	for _, n := range names {
+		name += exportName(n)
+	}
+	if name == "" {
+		name = "X"
+	}
+	unique := name
+	for i := 2; g.names[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+	g.names[unique] = true
+	return unique
+}
+
+func (g *generator) collect(decls []*decl) {
+	for _, d := range decls {
+		switch d.kind {
+		case declVar, declFunc:
+			g.values = append(g.values, d)
+			continue
+		}
+		name := qualify(d.ns, d.name)
+		ti := g.types[name]
+		if ti == nil {
+			ti = &typeInfo{name: name}
+			g.types[name] = ti
+			g.order = append(g.order, ti)
+		}
+		if len(ti.decls) > 0 && ti.kind() != d.kind && !(ti.kind() == declInterface && d.kind == declClass || ti.kind() == declClass && d.kind == declInterface) {
+			g.warnf(d.pos, "%s redeclared as a different kind of type", name)
+			continue
+		}
+		ti.decls = append(ti.decls, d)
+	}
+	for _, ti := range g.order {
+		if ti.isStruct() {
+			ti.goName = g.declare(append(ti.decls[0].ns, ti.decls[0].name)...)
+		}
+	}
+}
+
+// lookup resolves the type name in sc, trying the enclosing namespaces from
+// the innermost one.
+func (g *generator) lookup(name string, sc *scope) *typeInfo {
+	for i := len(sc.ns); i >= 0; i-- {
+		if ti := g.types[qualify(sc.ns[:i], name)]; ti != nil {
+			return ti
+		}
+	}
+	return nil
+}
+
+// mapType returns the Go type TypeScript type t is bound as in sc.
+func (g *generator) mapType(t tsType, sc *scope) *goType {
+	// Recursive type aliases and type parameter constraints end up as objects.
+	if g.depth > 20 {
+		return objectType
+	}
+	g.depth++
+	defer func() { g.depth-- }()
+
+	switch t := t.(type) {
+	case *typeRef:
+		switch t.name {
+		case "string":
+			return stringType
+		case "number":
+			return floatType
+		case "boolean":
+			return boolType
+		case "void", "undefined", "never":
+			return noType
+		case "Array", "ReadonlyArray":
+			if len(t.args) == 1 {
+				return g.sliceOf(g.mapType(t.args[0], sc))
+			}
+		case "this":
+			if sc.this != nil {
+				return sc.this
+			}
+			return objectType
+		}
+		if c, ok := sc.typeParam(t.name); ok {
+			if c == nil {
+				return objectType
+			}
+			return g.mapType(c, sc)
+		}
+		ti := g.lookup(t.name, sc)
+		switch {
+		case ti == nil:
+			return objectType
+		case ti.isStruct():
+			return &goType{kind: goStruct, expr: "*" + ti.goName}
+		case ti.kind() == declInterface:
+			d := ti.decls[0]
+			return g.funcType(ti.callSignature(), g.declScope(d, nil))
+		case ti.kind() == declAlias:
+			d := ti.decls[0]
+			return g.mapType(d.typ, g.declScope(d, nil))
+		case ti.kind() == declEnum:
+			if ti.decls[0].stringEnum {
+				return stringType
+			}
+			return floatType
+		}
+	case *arrayType:
+		return g.sliceOf(g.mapType(t.elem, sc))
+	case *unionType:
+		var types []*goType
+		nullable := false
+		for _, u := range t.types {
+			if ref, ok := u.(*typeRef); ok && (ref.name == "null" || ref.name == "undefined" || ref.name == "void") {
+				nullable = true
+				continue
+			}
+			types = append(types, g.mapType(u, sc))
+		}
+		if len(types) == 0 {
+			return objectType
+		}
+		for _, u := range types[1:] {
+			if u.expr != types[0].expr {
+				return objectType
+			}
+		}
+		if nullable {
+			t := *types[0]
+			t.nullable = true
+			return &t
+		}
+		return types[0]
+	case *literalType:
+		switch t.kind {
+		case tokString:
+			return stringType
+		case tokNumber:
+			return floatType
+		default:
+			return boolType
+		}
+	case *funcType:
+		if !t.construct {
+			return g.funcType(t.sig, sc)
+		}
+	case *keyofType:
+		return stringType
+	}
+	return objectType
+}
+
+func (g *generator) sliceOf(elem *goType) *goType {
+	if elem.kind == goNone {
+		elem = objectType
+	}
+	return &goType{kind: goSlice, expr: "[]" + elem.expr, elem: elem}
+}
+
+// funcType returns the Go function type of a callback with signature sig.
+func (g *generator) funcType(sig *signature, sc *scope) *goType {
+	sc = sc.with(sig.typeParams)
+	var params []string
+	for _, p := range sig.params {
+		if p.name == "this" {
+			continue
+		}
+		t := g.valueType(p.typ, sc)
+		if p.rest {
+			if t.kind == goSlice {
+				params = append(params, "..."+t.elem.expr)
+			} else {
+				params = append(params, "...*js.Object")
+			}
+			continue
+		}
+		params = append(params, t.expr)
+	}
+	expr := "func(" + strings.Join(params, ", ") + ")"
+	if sig.result != nil {
+		if result := g.mapType(sig.result, sc); result.kind != goNone {
+			expr += " " + result.expr
+		}
+	}
+	return &goType{kind: goFunc, expr: expr}
+}
+
+// valueType is like mapType, for types of values that must exist, like fields
+// and parameters.
+func (g *generator) valueType(t tsType, sc *scope) *goType {
+	if gt := g.mapType(t, sc); gt.kind != goNone {
+		return gt
+	}
+	return objectType
+}
+
+// paramType is like valueType, for parameters of generated functions, which
+// accept any Go value where JavaScript accepts any value.
+func (g *generator) paramType(t tsType, sc *scope) *goType {
+	if ref, ok := t.(*typeRef); ok && (ref.name == "any" || ref.name == "unknown") {
+		return anyType
+	}
+	return g.valueType(t, sc)
+}
+
+// declScope returns the scope of the members of d, where `this` is this.
+func (g *generator) declScope(d *decl, this *goType) *scope {
+	return (&scope{ns: d.ns, this: this}).with(d.typeParams)
+}
+
+// scopedMember is a member of an interface or a class, or of one of its base
+// types, with the scope it's declared in.
+type scopedMember struct {
+	*member
+	sc    *scope
+	level int // 0 for own members, 1 for the members of direct base types and so on.
+}
+
+// bases returns the interfaces and classes ti directly extends.
+func (g *generator) bases(ti *typeInfo) []*typeInfo {
+	var bases []*typeInfo
+	for _, d := range ti.decls {
+		sc := g.declScope(d, nil)
+		for _, e := range d.extends {
+			if ref, ok := e.(*typeRef); ok {
+				if base := g.lookup(ref.name, sc); base != nil && base.isStruct() {
+					bases = append(bases, base)
+				}
+			}
+		}
+	}
+	return bases
+}
+
+// ancestors returns ti and the types it extends, directly or indirectly.
+func (g *generator) ancestors(ti *typeInfo) map[*typeInfo]bool {
+	seen := map[*typeInfo]bool{}
+	var visit func(ti *typeInfo)
+	visit = func(ti *typeInfo) {
+		if seen[ti] {
+			return
+		}
+		seen[ti] = true
+		for _, base := range g.bases(ti) {
+			visit(base)
+		}
+	}
+	visit(ti)
+	return seen
+}
+
+// embedded returns the type the struct of ti embeds instead of *js.Object,
+// which is the first one it extends, or nil.
+func (g *generator) embedded(ti *typeInfo) *typeInfo {
+	for _, base := range g.bases(ti) {
+		if !g.ancestors(base)[ti] { // Cyclic declarations can't be embedded.
+			return base
+		}
+	}
+	return nil
+}
+
+// members returns the members of ti, followed by those of the types it
+// extends, except the ones promoted from the embedded type.
+func (g *generator) members(ti *typeInfo) []scopedMember {
+	this := &goType{kind: goStruct, expr: "*" + ti.goName}
+	var members []scopedMember
+	seen := map[*typeInfo]bool{}
+	if base := g.embedded(ti); base != nil {
+		seen = g.ancestors(base)
+	}
+	var visit func(ti *typeInfo, level int)
+	visit = func(ti *typeInfo, level int) {
+		seen[ti] = true
+		for _, d := range ti.decls {
+			sc := g.declScope(d, this)
+			for _, m := range d.members {
+				members = append(members, scopedMember{m, sc, level})
+			}
+		}
+		for _, base := range g.bases(ti) {
+			if !seen[base] {
+				visit(base, level+1)
+			}
+		}
+	}
+	visit(ti, 0)
+	return members
+}
+
+// Generate returns bindings for the declarations.
+func (g *generator) generate(files []string) ([]byte, error) {
+	for i, f := range files {
+		files[i] = filepath.Base(f)
+	}
+	g.printf("// Code generated by gopherjs bindgen from %s; DO NOT EDIT.\n\n", strings.Join(files, ", "))
+	g.printf("//go:build js\n\n")
+	g.printf("package %s\n\n", g.opts.Package)
+	g.printf("import \"github.com/gopherjs/gopherjs/js\"\n")
+	if g.opts.Module != "" {
+		g.printf("\n// module is the JavaScript module the bindings are for.\n")
+		g.printf("var module = js.Global.Call(\"require\", %q)\n", g.opts.Module)
+	}
+
+	// Variables holding the constructors of interfaces are bound right after
+	// the interfaces.
+	ctors := map[*typeInfo]*decl{}
+	for _, d := range g.values {
+		if ti := g.types[qualify(d.ns, d.name)]; d.kind == declVar && ti != nil && ti.isStruct() && ctors[ti] == nil && g.constructorVar(d) != nil {
+			ctors[ti] = d
+		}
+	}
+	for _, ti := range g.order {
+		if ti.isStruct() {
+			g.genStruct(ti)
+			if d := ctors[ti]; d != nil {
+				g.genVar(d)
+			}
+		}
+	}
+	for _, d := range g.values {
+		if ti := g.types[qualify(d.ns, d.name)]; ti != nil && ctors[ti] == d {
+			continue
+		}
+		switch d.kind {
+		case declFunc:
+			g.genFunc(d)
+		case declVar:
+			g.genVar(d)
+		}
+	}
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated bindings: %v", err)
+	}
+	return src, nil
+}
+
+// owner returns the Go expression of the JavaScript object holding the
+// declarations of namespace ns.
+func (g *generator) owner(ns []string) string {
+	expr := "js.Global"
+	if g.opts.Module != "" {
+		expr = "module"
+	}
+	for _, n := range ns {
+		expr += fmt.Sprintf(".Get(%q)", n)
+	}
+	return expr
+}
+
+func (g *generator) genStruct(ti *typeInfo) {
+	g.printf("\n")
+	g.printDoc(ti.decls[0].doc, "")
+	base := g.embedded(ti)
+	embedded := "*js.Object"
+	used := map[string]bool{"Object": true}
+	if base != nil {
+		embedded = "*" + base.goName
+		used[base.goName] = true
+	}
+	g.printf("type %s struct {\n\t%s\n", ti.goName, embedded)
+
+	members := g.members(ti)
+	props := map[string]bool{}
+	var accessors []scopedMember
+	for _, m := range members {
+		if m.kind != memberProperty || m.static || m.private || m.name == "" || props[m.name] {
+			continue
+		}
+		props[m.name] = true
+		name := exportName(m.name)
+		if name == "" || used[name] {
+			g.warnf(m.pos, "skipping property %s.%s, its Go name %s is taken", ti.name, m.name, name)
+			continue
+		}
+		used[name] = true
+		t := g.valueType(m.typ, m.sc)
+		if wraps(t) {
+			// Fields can't be wrapped, so these properties get methods.
+			accessors = append(accessors, m)
+			continue
+		}
+		g.printDoc(m.doc, "\t")
+		g.printf("\t%s %s `js:%q`\n", name, t.expr, m.name)
+	}
+	g.printf("}\n")
+
+	g.printf("\n// wrap%s returns o as a *%[1]s, or nil if o is null or undefined.\n", ti.goName)
+	g.printf("func wrap%s(o *js.Object) *%[1]s {\n", ti.goName)
+	g.printf("\tif o == nil || o == js.Undefined {\n\t\treturn nil\n\t}\n")
+	if base != nil {
+		g.printf("\treturn &%s{%s: wrap%[2]s(o)}\n}\n", ti.goName, base.goName)
+	} else {
+		g.printf("\treturn &%s{Object: o}\n}\n", ti.goName)
+	}
+
+	recv := receiverName(ti.goName)
+	for _, m := range accessors {
+		prop, name := m.name, exportName(m.name)
+		g.printf("\n")
+		g.printDoc(m.doc, "")
+		g.genCall(fmt.Sprintf("(%s *%s) %s", recv, ti.goName, name), recv, &signature{result: m.typ}, m.sc, func(string) string {
+			return fmt.Sprintf("%s.Object.Get(%q)", recv, prop)
+		})
+		if m.readonly {
+			continue
+		}
+		setter := "Set" + name
+		if used[setter] {
+			g.warnf(m.pos, "skipping setter of property %s.%s, its Go name %s is taken", ti.name, prop, setter)
+			continue
+		}
+		used[setter] = true
+		g.printf("\nfunc (%s *%s) %s(v %s) {\n", recv, ti.goName, setter, g.valueType(m.typ, m.sc).expr)
+		g.printf("\t%s.Object.Set(%q, v)\n}\n", recv, prop)
+	}
+
+	// Overloads of methods are bound by the last declaration among the
+	// nearest ones, which tends to be the most general.
+	var methods []string
+	chosen := map[string]scopedMember{}
+	for _, m := range members {
+		if m.kind != memberMethod || m.static || m.private || m.name == "" || props[m.name] {
+			continue
+		}
+		prev, ok := chosen[m.name]
+		if !ok {
+			methods = append(methods, m.name)
+		}
+		if !ok || prev.level == m.level {
+			chosen[m.name] = m
+		}
+	}
+	for _, name := range methods {
+		m := chosen[name]
+		goName := exportName(name)
+		if goName == "" || used[goName] {
+			g.warnf(m.pos, "skipping method %s.%s, its Go name %s is taken", ti.name, name, goName)
+			continue
+		}
+		used[goName] = true
+		g.printf("\n")
+		g.printDoc(m.doc, "")
+		g.genCall(fmt.Sprintf("(%s *%s) %s", recv, ti.goName, goName), recv, m.sig, m.sc, func(args string) string {
+			return fmt.Sprintf("%s.Object.Call(%q%s)", recv, name, args)
+		})
+	}
+
+	// Interfaces get constructors and static methods from a variable holding
+	// their constructor, see genVar.
+	if ti.kind() == declClass {
+		var statics []scopedMember
+		for _, m := range members {
+			if m.level == 0 && (m.static || m.kind == memberConstruct) {
+				statics = append(statics, m)
+			}
+		}
+		d := ti.decls[0]
+		g.genStatics(ti, d.ns, d.name, statics, true)
+	}
+}
+
+// constructorVar returns the members of a variable holding a constructor,
+// like `declare var Node: { prototype: Node; new(): Node; }`, or nil if v
+// isn't one.
+func (g *generator) constructorVar(v *decl) []scopedMember {
+	sc := &scope{ns: v.ns}
+	var members []scopedMember
+	switch t := v.typ.(type) {
+	case *typeLiteral:
+		for _, m := range t.members {
+			members = append(members, scopedMember{m, sc, 0})
+		}
+	case *typeRef:
+		if ti := g.lookup(t.name, sc); ti != nil && ti.isStruct() {
+			members = g.members(ti)
+		}
+	}
+	for _, m := range members {
+		if m.kind == memberConstruct || m.kind == memberProperty && m.name == "prototype" {
+			return members
+		}
+	}
+	return nil
+}
+
+// genStatics generates constructor functions and functions for static
+// methods of the JavaScript constructor name in namespace ns, which creates
+// values of ti.
+func (g *generator) genStatics(ti *typeInfo, ns []string, name string, members []scopedMember, class bool) {
+	owner := g.owner(ns) + fmt.Sprintf(".Get(%q)", name)
+	var ctor *scopedMember
+	for i, m := range members {
+		if m.kind == memberConstruct && (ctor == nil || m.level <= ctor.level) {
+			ctor = &members[i]
+		}
+	}
+	if ctor == nil && class {
+		// Classes without declared constructors have a default one.
+		ctor = &scopedMember{member: &member{kind: memberConstruct, sig: &signature{}}, sc: &scope{ns: ns}}
+	}
+	if ctor != nil && !ctor.private {
+		sig := *ctor.sig
+		if ti != nil {
+			sig.result = &typeRef{name: qualify(ns, name)}
+		}
+		if g.mapType(sig.result, ctor.sc).kind != goStruct {
+			sig.result = &typeRef{name: "any"}
+		}
+		goName := g.declare("New", qualify(ns, name))
+		g.printf("\n")
+		g.printDoc(ctor.doc, "")
+		g.genCall(goName, "", &sig, ctor.sc, func(args string) string {
+			return fmt.Sprintf("%s.New(%s)", owner, strings.TrimPrefix(args, ", "))
+		})
+	}
+
+	chosen := map[string]scopedMember{}
+	var methods []string
+	for _, m := range members {
+		if m.kind != memberMethod || m.level > 0 || m.private || m.name == "" || class && !m.static {
+			continue
+		}
+		if _, ok := chosen[m.name]; !ok {
+			methods = append(methods, m.name)
+		}
+		chosen[m.name] = m
+	}
+	for _, method := range methods {
+		m := chosen[method]
+		goName := g.declare(qualify(ns, name), method)
+		g.printf("\n")
+		g.printDoc(m.doc, "")
+		g.genCall(goName, "", m.sig, m.sc, func(args string) string {
+			return fmt.Sprintf("%s.Call(%q%s)", owner, method, args)
+		})
+	}
+
+	// Static properties are read by functions, like variables in genVar.
+	seen := map[string]bool{}
+	for _, m := range members {
+		if m.kind != memberProperty || m.level > 0 || m.private || m.name == "" || m.name == "prototype" || class && !m.static {
+			continue
+		}
+		if _, ok := chosen[m.name]; ok || seen[m.name] {
+			continue
+		}
+		seen[m.name] = true
+		prop := m.name
+		goName := g.declare(qualify(ns, name), prop)
+		g.printf("\n")
+		g.printDoc(m.doc, "")
+		g.genCall(goName, "", &signature{result: m.typ}, m.sc, func(string) string {
+			return fmt.Sprintf("%s.Get(%q)", owner, prop)
+		})
+	}
+}
+
+func (g *generator) genFunc(d *decl) {
+	goName := g.declare(append(d.ns, d.name)...)
+	g.printf("\n")
+	g.printDoc(d.doc, "")
+	g.genCall(goName, "", d.sig, &scope{ns: d.ns}, func(args string) string {
+		return fmt.Sprintf("%s.Call(%q%s)", g.owner(d.ns), d.name, args)
+	})
+}
+
+func (g *generator) genVar(d *decl) {
+	if members := g.constructorVar(d); members != nil {
+		ti := g.types[qualify(d.ns, d.name)]
+		if ti != nil && !ti.isStruct() {
+			ti = nil
+		}
+		g.genStatics(ti, d.ns, d.name, members, false)
+		return
+	}
+	goName := g.declare(append([]string{"Global"}, append(d.ns, d.name)...)...)
+	g.printf("\n")
+	g.printDoc(d.doc, "")
+	g.genCall(goName, "", &signature{result: d.typ}, &scope{ns: d.ns}, func(string) string {
+		return fmt.Sprintf("%s.Get(%q)", g.owner(d.ns), d.name)
+	})
+}
+
+// genCall generates a function named decl, which may include a receiver,
+// that calls JavaScript with the signature sig. The call function returns the
+// Go expression of the call for a list of arguments that starts with a comma.
+func (g *generator) genCall(decl, recv string, sig *signature, sc *scope, call func(args string) string) {
+	sc = sc.with(sig.typeParams)
+	reserved := map[string]bool{recv: true}
+	var params, args []string
+	variadic, variadicType := "", ""
+	for i, p := range sig.params {
+		if p.name == "this" {
+			continue
+		}
+		name := paramName(p.name, reserved)
+		reserved[name] = true
+		t := g.paramType(p.typ, sc)
+		switch {
+		case p.rest && i == len(sig.params)-1 && variadic == "":
+			variadic, variadicType = name, "*js.Object"
+			if elem, ok := p.typ.(*arrayType); ok {
+				variadicType = g.paramType(elem.elem, sc).expr
+			} else if t.kind == goSlice {
+				variadicType = t.elem.expr
+			}
+		case p.optional && variadic == "":
+			// A single optional parameter becomes a variadic parameter of its
+			// type, several become one of type any.
+			rest := sig.params[i:]
+			if len(rest) == 1 {
+				variadic, variadicType = name, t.expr
+			} else {
+				variadic, variadicType = "optional", "any"
+				for reserved[variadic] {
+					variadic += "_"
+				}
+			}
+		case variadic == "":
+			params = append(params, name+" "+t.expr)
+			args = append(args, name)
+		}
+	}
+	if variadic != "" {
+		params = append(params, variadic+" ..."+variadicType)
+	}
+
+	result := noType
+	promise := false
+	if sig.result != nil {
+		t := sig.result
+		if ref, ok := t.(*typeRef); ok && (ref.name == "Promise" || ref.name == "PromiseLike") && len(ref.args) == 1 {
+			t, promise = ref.args[0], true
+		}
+		result = g.mapType(t, sc)
+		if result.kind == goFunc || result.kind == goSlice && result.elem.kind == goSlice || result.kind == goSlice && result.elem.kind == goFunc {
+			result = objectType // Results are converted explicitly, which is only done for simple types.
+		}
+	}
+	results := result.expr
+	if promise {
+		if result.kind == goNone {
+			results = "error"
+		} else {
+			results = "(" + result.expr + ", error)"
+		}
+	}
+
+	g.printf("func %s(%s) %s {\n", decl, strings.Join(params, ", "), results)
+	argList := ""
+	if len(args) > 0 {
+		argList = ", " + strings.Join(args, ", ")
+	}
+	switch {
+	case variadic != "" && variadicType == "any" && len(args) == 0:
+		argList = ", " + variadic + "..."
+	case variadic != "":
+		if variadicType == "any" {
+			g.printf("\targs := append([]any{%s}, %s...)\n", strings.Join(args, ", "), variadic)
+		} else {
+			g.printf("\targs := []any{%s}\n", strings.Join(args, ", "))
+			g.printf("\tfor _, v := range %s {\n\t\targs = append(args, v)\n\t}\n", variadic)
+		}
+		argList = ", args..."
+	}
+	expr := call(argList)
+
+	ret := func(v string) string { return v }
+	if promise {
+		if result.kind == goNone {
+			g.printf("\t_, err := js.Await(%s)\n\treturn err\n}\n", expr)
+			return
+		}
+		g.printf("\tr, err := js.Await(%s)\n", expr)
+		g.printf("\tif err != nil {\n\t\treturn %s, err\n\t}\n", result.zero())
+		expr = "r"
+		ret = func(v string) string { return v + ", nil" }
+	}
+
+	switch result.kind {
+	case goNone:
+		g.printf("\t%s\n", expr)
+	case goSlice:
+		if expr != "r" {
+			g.printf("\tr := %s\n", expr)
+		}
+		g.printf("\tif r == nil || r == js.Undefined {\n\t\treturn %s\n\t}\n", ret("nil"))
+		g.printf("\ts := make(%s, r.Length())\n", result.expr)
+		g.printf("\tfor i := range s {\n\t\ts[i] = %s\n\t}\n", convert(result.elem, "r.Index(i)"))
+		g.printf("\treturn %s\n", ret("s"))
+	case goString:
+		if result.nullable {
+			// Strings that are null or undefined become empty, not "null".
+			if expr != "r" {
+				g.printf("\tr := %s\n", expr)
+			}
+			g.printf("\tif r == nil || r == js.Undefined {\n\t\treturn %s\n\t}\n", ret(`""`))
+			expr = "r"
+		}
+		g.printf("\treturn %s\n", ret(convert(result, expr)))
+	default:
+		g.printf("\treturn %s\n", ret(convert(result, expr)))
+	}
+	g.printf("}\n")
+}
+
+// wraps reports whether values of type t are converted with the wrap
+// functions of generated structs, which the js package doesn't do for fields.
+func wraps(t *goType) bool {
+	switch t.kind {
+	case goStruct:
+		return true
+	case goSlice:
+		return wraps(t.elem)
+	default:
+		return false
+	}
+}
+
+// convert returns the Go expression converting the *js.Object expression v to
+// the simple type t.
+func convert(t *goType, v string) string {
+	switch t.kind {
+	case goString:
+		return v + ".String()"
+	case goFloat:
+		return v + ".Float()"
+	case goBool:
+		return v + ".Bool()"
+	case goStruct:
+		return fmt.Sprintf("wrap%s(%s)", strings.TrimPrefix(t.expr, "*"), v)
+	default:
+		return v
+	}
+}
+
+var (
+	jsdocLink = regexp.MustCompile(`\{@link(?:code|plain)?\s+([^}|\s]+)(?:\s*\|\s*|\s+)?([^}]*)\}`)
+	jsdocTag  = regexp.MustCompile(`^@(\w+)\s*`)
+)
+
+// printDoc prints the JSDoc comment doc as a Go comment. Block tags are left
+// out, except for @deprecated, which becomes a Go deprecation notice.
+func (g *generator) printDoc(doc, indent string) {
+	var lines []string
+	skip := false
+	for _, line := range strings.Split(doc, "\n") {
+		line = strings.TrimSpace(line)
+		line = strings.TrimSpace(strings.TrimPrefix(line, "*"))
+		line = jsdocLink.ReplaceAllStringFunc(line, func(link string) string {
+			m := jsdocLink.FindStringSubmatch(link)
+			if m[2] != "" {
+				return m[2]
+			}
+			return m[1]
+		})
+		if tag := jsdocTag.FindStringSubmatch(line); tag != nil {
+			skip = tag[1] != "deprecated"
+			if !skip {
+				lines = append(lines, "", strings.TrimSpace("Deprecated: "+line[len(tag[0]):]))
+			}
+			continue
+		}
+		if !skip {
+			lines = append(lines, line)
+		}
+	}
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for _, line := range lines {
+		if line == "" {
+			g.printf("%s//\n", indent)
+		} else {
+			g.printf("%s// %s\n", indent, line)
+		}
+	}
+}
+
+// exportName returns an exported Go identifier for the JavaScript name s,
+// leaving out characters that can't be part of one.
+func exportName(s string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			if b.Len() == 0 && unicode.IsDigit(r) {
+				b.WriteByte('X')
+			}
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// receiverName returns the receiver name for methods of the type typeName.
+func receiverName(typeName string) string {
+	name := strings.ToLower(typeName[:1])
+	if goReserved[name] && len(typeName) > 1 {
+		name = strings.ToLower(typeName[:2])
+	}
+	if goReserved[name] {
+		name = "x"
+	}
+	return name
+}
+
+// goReserved are identifiers parameters must not be named, because they are
+// keywords, or predeclared or used by generated code.
+var goReserved = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true, "default": true,
+	"defer": true, "else": true, "fallthrough": true, "for": true, "func": true, "go": true, "goto": true,
+	"if": true, "import": true, "interface": true, "map": true, "package": true, "range": true,
+	"return": true, "select": true, "struct": true, "switch": true, "type": true, "var": true,
+	"any": true, "append": true, "bool": true, "error": true, "false": true, "float64": true,
+	"len": true, "make": true, "nil": true, "string": true, "true": true,
+	"args": true, "err": true, "i": true, "js": true, "module": true, "r": true, "s": true, "v": true,
+}
+
+// paramName returns a Go name for the parameter name, which doesn't clash
+// with the reserved ones.
+func paramName(name string, reserved map[string]bool) string {
+	name = strings.Map(func(r rune) rune {
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, name)
+	if name == "" {
+		name = "arg"
+	}
+	for goReserved[name] || reserved[name] {
+		name += "_"
+	}
+	return name
+}
@@ -0,0 +1,192 @@
+package bindgen
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokTemplate
+	tokPunct
+)
+
+// token is a lexical token of a TypeScript declaration file.
+type token struct {
+	kind    tokenKind
+	text    string // Identifier name, punctuation, unquoted string or number.
+	doc     string // Text of the JSDoc comment right before the token, if any.
+	newline bool   // Whether a line break precedes the token.
+	pos     position
+}
+
+// position is a location in a declaration file, for error messages.
+type position struct {
+	file      string
+	line, col int
+}
+
+func (p position) String() string { return fmt.Sprintf("%s:%d:%d", p.file, p.line, p.col) }
+
+// punctuators that are longer than one character. Others are single
+// characters, so that closing type argument lists like "A<B<C>>" need no
+// special handling.
+var punctuators = []string{"...", "=>"}
+
+// lex splits the declaration file src into tokens, ending with tokEOF.
+func lex(file, src string) ([]token, error) {
+	l := lexer{src: src, pos: position{file: file, line: 1, col: 1}}
+	var tokens []token
+	for {
+		t, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+		if t.kind == tokEOF {
+			return tokens, nil
+		}
+	}
+}
+
+type lexer struct {
+	src     string
+	off     int
+	pos     position
+	doc     string
+	newline bool
+}
+
+// advance consumes n bytes, keeping track of the position.
+func (l *lexer) advance(n int) {
+	for _, c := range l.src[l.off : l.off+n] {
+		if c == '\n' {
+			l.newline = true
+			l.pos.line++
+			l.pos.col = 1
+		} else {
+			l.pos.col++
+		}
+	}
+	l.off += n
+}
+
+func (l *lexer) errorf(format string, args ...any) error {
+	return fmt.Errorf("%s: %s", l.pos, fmt.Sprintf(format, args...))
+}
+
+// skipSpace skips white space and comments, remembering the text of the last
+// JSDoc comment for the next token and whether there was a line break.
+func (l *lexer) skipSpace() error {
+	for l.off < len(l.src) {
+		rest := l.src[l.off:]
+		switch {
+		case strings.HasPrefix(rest, "//"):
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			l.advance(end)
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				return l.errorf("unterminated comment")
+			}
+			if strings.HasPrefix(rest, "/**") && end > 0 {
+				l.doc = rest[3 : end+2]
+			}
+			l.advance(end + 4)
+		default:
+			r, size := utf8.DecodeRuneInString(rest)
+			if !unicode.IsSpace(r) {
+				return nil
+			}
+			l.advance(size)
+		}
+	}
+	return nil
+}
+
+func (l *lexer) next() (token, error) {
+	if err := l.skipSpace(); err != nil {
+		return token{}, err
+	}
+	t := token{doc: l.doc, newline: l.newline, pos: l.pos}
+	l.doc = ""
+	if l.off == len(l.src) {
+		return t, nil
+	}
+	rest := l.src[l.off:]
+	r, size := utf8.DecodeRuneInString(rest)
+	switch {
+	case isIdentStart(r):
+		n := size
+		for n < len(rest) {
+			r, size := utf8.DecodeRuneInString(rest[n:])
+			if !isIdentStart(r) && !unicode.IsDigit(r) {
+				break
+			}
+			n += size
+		}
+		t.kind, t.text = tokIdent, rest[:n]
+		l.advance(n)
+	case r >= '0' && r <= '9' || r == '.' && len(rest) > 1 && rest[1] >= '0' && rest[1] <= '9':
+		n := 1
+		for n < len(rest) && (isIdentStart(rune(rest[n])) || rest[n] >= '0' && rest[n] <= '9' || rest[n] == '.') {
+			n++
+		}
+		t.kind, t.text = tokNumber, rest[:n]
+		l.advance(n)
+	case r == '"' || r == '\'':
+		var b strings.Builder
+		n := 1
+		for {
+			if n >= len(rest) || rest[n] == '\n' {
+				return t, l.errorf("unterminated string")
+			}
+			c := rest[n]
+			if c == byte(r) {
+				break
+			}
+			if c == '\\' && n+1 < len(rest) {
+				n++
+				c = rest[n]
+			}
+			b.WriteByte(c)
+			n++
+		}
+		t.kind, t.text = tokString, b.String()
+		l.advance(n + 1)
+	case r == '`':
+		// Template literal types are only skipped, so nested substitutions
+		// don't need to be tracked precisely.
+		end := strings.IndexByte(rest[1:], '`')
+		if end < 0 {
+			return t, l.errorf("unterminated template literal")
+		}
+		t.kind, t.text = tokTemplate, rest[1:end+1]
+		l.advance(end + 2)
+	default:
+		t.kind, t.text = tokPunct, rest[:size]
+		for _, p := range punctuators {
+			if strings.HasPrefix(rest, p) {
+				t.text = p
+				break
+			}
+		}
+		l.advance(len(t.text))
+	}
+	l.newline = false // Line breaks inside of the token don't count.
+	return t, nil
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r)
+}
@@ -0,0 +1,813 @@
+package bindgen
+
+import (
+	"fmt"
+	"strings"
+)
+
+type declKind int
+
+const (
+	declInterface declKind = iota
+	declClass
+	declAlias
+	declEnum
+	declVar
+	declFunc
+)
+
+// decl is a top-level declaration, or one nested in namespaces.
+type decl struct {
+	kind       declKind
+	ns         []string // Enclosing namespaces, outermost first.
+	name       string
+	doc        string
+	pos        position
+	typeParams []*typeParam
+	extends    []tsType  // Base types of interfaces and classes.
+	members    []*member // Members of interfaces, classes and enums.
+	typ        tsType    // Type of variables and type aliases.
+	sig        *signature
+	stringEnum bool // Whether all members of an enum are initialized with strings.
+}
+
+type typeParam struct {
+	name       string
+	constraint tsType
+}
+
+type memberKind int
+
+const (
+	memberProperty memberKind = iota
+	memberMethod
+	memberCall
+	memberConstruct
+	memberIndex
+)
+
+// member is a member of an interface, a class, an object type or an enum.
+type member struct {
+	kind     memberKind
+	name     string // Empty for computed names like [Symbol.iterator].
+	doc      string
+	pos      position
+	optional bool
+	readonly bool
+	static   bool
+	private  bool   // Private and protected members, which bindings leave out.
+	typ      tsType // Type of properties and index signatures.
+	sig      *signature
+}
+
+type signature struct {
+	typeParams []*typeParam
+	params     []*param
+	result     tsType // Nil if the result type is omitted.
+}
+
+type param struct {
+	name     string
+	typ      tsType
+	optional bool
+	rest     bool
+}
+
+// tsType is a TypeScript type expression.
+type tsType interface{ isType() }
+
+type (
+	// typeRef refers to a named type, including primitive types like string.
+	typeRef struct {
+		name string // Possibly qualified by namespaces, like "WebAssembly.Module".
+		args []tsType
+	}
+	arrayType        struct{ elem tsType }
+	tupleType        struct{ elems []tsType }
+	unionType        struct{ types []tsType }
+	intersectionType struct{ types []tsType }
+	// literalType is a string, number or boolean literal, whose kind is
+	// tokString, tokNumber or tokIdent respectively.
+	literalType struct{ kind tokenKind }
+	funcType    struct {
+		sig       *signature
+		construct bool
+	}
+	typeLiteral struct{ members []*member }
+	keyofType   struct{}
+	// unknownType stands for type expressions that bindings don't model, like
+	// conditional, mapped or indexed access types.
+	unknownType struct{}
+)
+
+func (*typeRef) isType()          {}
+func (*arrayType) isType()        {}
+func (*tupleType) isType()        {}
+func (*unionType) isType()        {}
+func (*intersectionType) isType() {}
+func (*literalType) isType()      {}
+func (*funcType) isType()         {}
+func (*typeLiteral) isType()      {}
+func (*keyofType) isType()        {}
+func (*unknownType) isType()      {}
+
+// parseError is used to abort parsing, see parser.errorf.
+type parseError struct{ err error }
+
+type parser struct {
+	toks  []token
+	i     int
+	ns    []string
+	decls []*decl
+}
+
+// parse returns the declarations of the declaration file src.
+func parse(file, src string) (decls []*decl, err error) {
+	toks, err := lex(file, src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	defer func() {
+		if e := recover(); e != nil {
+			pe, ok := e.(parseError)
+			if !ok {
+				panic(e)
+			}
+			err = pe.err
+		}
+	}()
+	p.parseStatements()
+	return p.decls, nil
+}
+
+func (p *parser) tok() token { return p.peek(0) }
+
+func (p *parser) peek(n int) token {
+	if p.i+n >= len(p.toks) {
+		return p.toks[len(p.toks)-1]
+	}
+	return p.toks[p.i+n]
+}
+
+func (p *parser) next() token {
+	t := p.tok()
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+// is reports whether the current token is the identifier or punctuation text.
+func (p *parser) is(text string) bool {
+	t := p.tok()
+	return (t.kind == tokIdent || t.kind == tokPunct) && t.text == text
+}
+
+// got consumes the current token if it is text.
+func (p *parser) got(text string) bool {
+	if p.is(text) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(text string) {
+	if !p.got(text) {
+		p.errorf("expected %q, found %s", text, describe(p.tok()))
+	}
+}
+
+func (p *parser) ident() string {
+	if p.tok().kind != tokIdent {
+		p.errorf("expected identifier, found %s", describe(p.tok()))
+	}
+	return p.next().text
+}
+
+func (p *parser) errorf(format string, args ...any) {
+	panic(parseError{fmt.Errorf("%s: %s", p.tok().pos, fmt.Sprintf(format, args...))})
+}
+
+func describe(t token) string {
+	switch t.kind {
+	case tokEOF:
+		return "end of file"
+	case tokString:
+		return fmt.Sprintf("string %q", t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+// skipBalanced skips the bracketed tokens starting at the current one.
+func (p *parser) skipBalanced() {
+	depth := 0
+	for {
+		t := p.next()
+		switch {
+		case t.kind == tokEOF:
+			p.errorf("unexpected end of file")
+		case t.kind != tokPunct:
+		case strings.Contains("([{", t.text):
+			depth++
+		case strings.Contains(")]}", t.text):
+			depth--
+		}
+		if depth == 0 {
+			return
+		}
+	}
+}
+
+// skipStatement skips tokens up to the end of a statement that bindings
+// don't use, like imports and exports.
+func (p *parser) skipStatement() {
+	p.next()
+	for !p.tok().newline && !p.is("}") && p.tok().kind != tokEOF {
+		if p.got(";") {
+			return
+		}
+		if p.is("(") || p.is("[") || p.is("{") {
+			p.skipBalanced()
+		} else {
+			p.next()
+		}
+	}
+	p.got(";")
+}
+
+func (p *parser) parseStatements() {
+	for p.tok().kind != tokEOF && !p.is("}") {
+		p.parseStatement()
+	}
+}
+
+func (p *parser) parseStatement() {
+	doc, pos := p.tok().doc, p.tok().pos
+	for {
+		switch {
+		case p.is("export") && (p.peek(1).text == "=" || p.peek(1).text == "{" || p.peek(1).text == "*" || p.peek(1).text == "as"),
+			p.is("export") && p.peek(1).text == "default" && p.peek(2).kind == tokIdent && (p.peek(3).text == ";" || p.peek(3).newline),
+			p.is("import"):
+			p.skipStatement()
+			return
+		case p.is("export"), p.is("declare"), p.is("default"), p.is("abstract"):
+			p.next()
+			continue
+		}
+		break
+	}
+	d := &decl{doc: doc, pos: pos, ns: append([]string(nil), p.ns...)}
+	switch {
+	case p.got(";"):
+		return
+	case p.got("interface"):
+		d.kind = declInterface
+		d.name = p.ident()
+		d.typeParams = p.parseTypeParams()
+		if p.got("extends") {
+			d.extends = p.parseTypeList()
+		}
+		d.members = p.parseMembers()
+	case p.got("class"):
+		d.kind = declClass
+		d.name = p.ident()
+		d.typeParams = p.parseTypeParams()
+		if p.got("extends") {
+			d.extends = p.parseTypeList()
+		}
+		if p.got("implements") {
+			p.parseTypeList() // Classes declare the members of interfaces they implement.
+		}
+		d.members = p.parseMembers()
+	case p.is("type") && p.peek(1).kind == tokIdent:
+		p.next()
+		d.kind = declAlias
+		d.name = p.ident()
+		d.typeParams = p.parseTypeParams()
+		p.expect("=")
+		d.typ = p.parseType()
+		p.got(";")
+	case p.is("const") && p.peek(1).text == "enum", p.is("enum"):
+		p.got("const")
+		p.next()
+		d.kind = declEnum
+		d.name = p.ident()
+		p.parseEnumMembers(d)
+	case p.is("var"), p.is("let"), p.is("const"):
+		p.next()
+		p.parseVars(d)
+		return
+	case p.got("function"):
+		d.kind = declFunc
+		d.name = p.ident()
+		d.sig = p.parseSignature()
+		if p.is("{") {
+			p.skipBalanced()
+		}
+		p.got(";")
+	case p.is("global") && p.peek(1).text == "{":
+		p.next()
+		p.parseBlock(nil)
+		return
+	case p.is("namespace"), p.is("module"):
+		p.next()
+		if p.tok().kind == tokString {
+			// Ambient modules like `declare module "name"` describe the
+			// declarations of the package bindings are generated for.
+			p.next()
+			if p.is("{") {
+				p.parseBlock(nil)
+			} else {
+				p.got(";")
+			}
+			return
+		}
+		name := []string{p.ident()}
+		for p.got(".") {
+			name = append(name, p.ident())
+		}
+		p.parseBlock(name)
+		return
+	default:
+		p.errorf("unexpected %s", describe(p.tok()))
+	}
+	p.decls = append(p.decls, d)
+}
+
+// parseBlock parses the statements of a namespace body.
+func (p *parser) parseBlock(ns []string) {
+	outer := p.ns
+	p.ns = append(append([]string(nil), outer...), ns...)
+	p.expect("{")
+	p.parseStatements()
+	p.expect("}")
+	p.ns = outer
+}
+
+// parseVars parses the variables of a statement like `var a: A, b: B;`, the
+// first of which is d.
+func (p *parser) parseVars(d *decl) {
+	for {
+		d.kind = declVar
+		d.name = p.ident()
+		d.typ = &typeRef{name: "any"}
+		if p.got(":") {
+			d.typ = p.parseType()
+		}
+		if p.got("=") {
+			// Initializers of constants are values, like `const x = 1`.
+			if t := p.next(); t.kind == tokString || t.kind == tokNumber {
+				d.typ = &literalType{kind: t.kind}
+			}
+		}
+		p.decls = append(p.decls, d)
+		if !p.got(",") {
+			break
+		}
+		d = &decl{doc: d.doc, pos: p.tok().pos, ns: d.ns}
+	}
+	p.got(";")
+}
+
+func (p *parser) parseEnumMembers(d *decl) {
+	p.expect("{")
+	d.stringEnum = true
+	for !p.got("}") {
+		t := p.next()
+		if t.kind != tokIdent && t.kind != tokString {
+			p.errorf("unexpected %s in enum", describe(t))
+		}
+		d.members = append(d.members, &member{name: t.text, doc: t.doc, pos: t.pos})
+		if !p.got("=") || p.tok().kind != tokString {
+			d.stringEnum = false
+		}
+		for !p.is(",") && !p.is("}") {
+			p.next()
+		}
+		p.got(",")
+	}
+}
+
+// parseTypeParams parses optional type parameters like <K extends keyof T = string>.
+func (p *parser) parseTypeParams() []*typeParam {
+	if !p.got("<") {
+		return nil
+	}
+	var params []*typeParam
+	for !p.got(">") {
+		for p.is("const") || p.is("in") || p.is("out") {
+			if p.peek(1).kind != tokIdent {
+				break
+			}
+			p.next()
+		}
+		tp := &typeParam{name: p.ident()}
+		if p.got("extends") {
+			tp.constraint = p.parseType()
+		}
+		if p.got("=") {
+			p.parseType()
+		}
+		params = append(params, tp)
+		if !p.got(",") {
+			p.expect(">")
+			break
+		}
+	}
+	return params
+}
+
+func (p *parser) parseTypeList() []tsType {
+	list := []tsType{p.parseType()}
+	for p.got(",") {
+		list = append(list, p.parseType())
+	}
+	return list
+}
+
+// modifiers of members, which are names when they aren't followed by one.
+var modifiers = map[string]bool{
+	"public": true, "private": true, "protected": true, "static": true, "readonly": true,
+	"abstract": true, "declare": true, "override": true, "accessor": true, "get": true, "set": true,
+}
+
+// startsName reports whether t starts the name of a member.
+func startsName(t token) bool {
+	return !t.newline && (t.kind == tokIdent || t.kind == tokString || t.kind == tokNumber || t.text == "[" || t.text == "#")
+}
+
+func (p *parser) parseMembers() []*member {
+	p.expect("{")
+	var members []*member
+	for !p.got("}") {
+		members = append(members, p.parseMember())
+		if !p.got(";") {
+			p.got(",")
+		}
+	}
+	return members
+}
+
+func (p *parser) parseMember() *member {
+	m := &member{doc: p.tok().doc, pos: p.tok().pos}
+	accessor := ""
+	for p.tok().kind == tokIdent && modifiers[p.tok().text] && startsName(p.peek(1)) {
+		switch t := p.next().text; t {
+		case "static":
+			m.static = true
+		case "readonly":
+			m.readonly = true
+		case "private", "protected":
+			m.private = true
+		case "get", "set":
+			accessor = t
+		}
+	}
+
+	switch {
+	case p.is("(") || p.is("<"):
+		m.kind = memberCall
+		m.sig = p.parseSignature()
+		return m
+	case p.is("new") && (p.peek(1).text == "(" || p.peek(1).text == "<"):
+		p.next()
+		m.kind = memberConstruct
+		m.sig = p.parseSignature()
+		return m
+	case p.is("constructor") && p.peek(1).text == "(":
+		p.next()
+		m.kind = memberConstruct
+		m.sig = p.parseSignature()
+		return m
+	case p.is("[") && p.peek(1).kind == tokIdent && p.peek(2).text == ":":
+		p.next()
+		p.ident()
+		p.expect(":")
+		p.parseType()
+		p.expect("]")
+		m.kind = memberIndex
+		m.typ = &typeRef{name: "any"}
+		if p.got(":") {
+			m.typ = p.parseType()
+		}
+		return m
+	case p.is("["):
+		p.skipBalanced() // Computed names, like [Symbol.iterator].
+	case p.got("#"):
+		p.ident() // Private names.
+		m.private = true
+	default:
+		t := p.next()
+		if t.kind != tokIdent && t.kind != tokString && t.kind != tokNumber {
+			p.errorf("unexpected %s in member list", describe(t))
+		}
+		m.name = t.text
+	}
+	m.optional = p.got("?")
+	p.got("!")
+
+	switch {
+	case accessor != "":
+		// Accessors are modeled as the properties they define.
+		m.kind = memberProperty
+		sig := p.parseSignature()
+		m.typ = sig.result
+		if accessor == "set" && len(sig.params) > 0 {
+			m.typ = sig.params[0].typ
+		}
+		if m.typ == nil {
+			m.typ = &typeRef{name: "any"}
+		}
+	case p.is("(") || p.is("<"):
+		m.kind = memberMethod
+		m.sig = p.parseSignature()
+	default:
+		m.kind = memberProperty
+		m.typ = &typeRef{name: "any"}
+		if p.got(":") {
+			m.typ = p.parseType()
+		}
+	}
+	return m
+}
+
+// parseSignature parses type parameters, parameters and an optional result
+// type, like <T>(a: T, b?: string): T.
+func (p *parser) parseSignature() *signature {
+	sig := &signature{typeParams: p.parseTypeParams()}
+	p.expect("(")
+	for !p.got(")") {
+		sig.params = append(sig.params, p.parseParam())
+		if !p.got(",") {
+			p.expect(")")
+			break
+		}
+	}
+	if p.got(":") {
+		sig.result = p.parseType()
+	}
+	return sig
+}
+
+func (p *parser) parseParam() *param {
+	for p.tok().kind == tokIdent && modifiers[p.tok().text] && p.peek(1).kind == tokIdent {
+		p.next() // Parameter properties of constructors, like `readonly x: number`.
+	}
+	prm := &param{rest: p.got("...")}
+	if p.is("{") || p.is("[") {
+		p.skipBalanced() // Destructuring patterns.
+		prm.name = "arg"
+	} else {
+		prm.name = p.ident()
+	}
+	prm.optional = p.got("?")
+	prm.typ = &typeRef{name: "any"}
+	if p.got(":") {
+		prm.typ = p.parseType()
+	}
+	if p.got("=") {
+		prm.optional = true
+		for !p.is(",") && !p.is(")") {
+			p.next()
+		}
+	}
+	return prm
+}
+
+func (p *parser) parseType() tsType {
+	switch {
+	case p.is("<"):
+		return &funcType{sig: p.parseArrowSignature()}
+	case p.is("(") && p.isArrow():
+		return &funcType{sig: p.parseArrowSignature()}
+	case p.is("abstract") && p.peek(1).text == "new":
+		p.next()
+		fallthrough
+	case p.is("new"):
+		p.next()
+		return &funcType{sig: p.parseArrowSignature(), construct: true}
+	}
+	t := p.parseUnion()
+	if p.got("extends") {
+		// Conditional types.
+		p.parseUnion()
+		p.expect("?")
+		p.parseType()
+		p.expect(":")
+		p.parseType()
+		return &unknownType{}
+	}
+	return t
+}
+
+// isArrow reports whether the parenthesis at the current token starts the
+// parameters of a function type, rather than a parenthesized type.
+func (p *parser) isArrow() bool {
+	depth := 0
+	for n := 0; ; n++ {
+		t := p.peek(n)
+		switch {
+		case t.kind == tokEOF:
+			return false
+		case t.kind != tokPunct:
+		case strings.Contains("([{", t.text):
+			depth++
+		case strings.Contains(")]}", t.text):
+			depth--
+		}
+		if depth == 0 {
+			return p.peek(n+1).text == "=>"
+		}
+	}
+}
+
+func (p *parser) parseArrowSignature() *signature {
+	sig := &signature{typeParams: p.parseTypeParams()}
+	p.expect("(")
+	for !p.got(")") {
+		sig.params = append(sig.params, p.parseParam())
+		if !p.got(",") {
+			p.expect(")")
+			break
+		}
+	}
+	p.expect("=>")
+	sig.result = p.parseType()
+	return sig
+}
+
+func (p *parser) parseUnion() tsType {
+	p.got("|")
+	types := []tsType{p.parseIntersection()}
+	for p.got("|") {
+		types = append(types, p.parseIntersection())
+	}
+	if len(types) == 1 {
+		return types[0]
+	}
+	return &unionType{types: types}
+}
+
+func (p *parser) parseIntersection() tsType {
+	p.got("&")
+	types := []tsType{p.parseTypeOperator()}
+	for p.got("&") {
+		types = append(types, p.parseTypeOperator())
+	}
+	if len(types) == 1 {
+		return types[0]
+	}
+	return &intersectionType{types: types}
+}
+
+func (p *parser) parseTypeOperator() tsType {
+	switch {
+	case p.is("keyof") && p.peek(1).text != "]":
+		p.next()
+		p.parseTypeOperator()
+		return &keyofType{}
+	case p.is("unique") && p.peek(1).text == "symbol",
+		p.is("readonly") && (p.peek(1).kind == tokIdent || p.peek(1).text == "["):
+		p.next()
+		return p.parseTypeOperator()
+	case p.is("infer") && p.peek(1).kind == tokIdent:
+		p.next()
+		p.ident()
+		return &unknownType{}
+	}
+	t := p.parsePrimary()
+	for p.is("[") && !p.tok().newline {
+		p.next()
+		if p.got("]") {
+			t = &arrayType{elem: t}
+			continue
+		}
+		p.parseType()
+		p.expect("]")
+		t = &unknownType{} // Indexed access types.
+	}
+	return t
+}
+
+func (p *parser) parsePrimary() tsType {
+	t := p.tok()
+	switch {
+	case p.got("("):
+		typ := p.parseType()
+		p.expect(")")
+		return typ
+	case p.is("{"):
+		if p.isMappedType() {
+			p.skipBalanced()
+			return &unknownType{}
+		}
+		return &typeLiteral{members: p.parseMembers()}
+	case p.got("["):
+		tuple := &tupleType{}
+		for !p.got("]") {
+			p.got("...")
+			if p.tok().kind == tokIdent && (p.peek(1).text == ":" || p.peek(1).text == "?" && p.peek(2).text == ":") {
+				p.next() // Labels of tuple elements.
+				p.got("?")
+				p.next()
+			}
+			tuple.elems = append(tuple.elems, p.parseType())
+			p.got("?")
+			if !p.got(",") {
+				p.expect("]")
+				break
+			}
+		}
+		return tuple
+	case t.kind == tokString, t.kind == tokNumber:
+		p.next()
+		return &literalType{kind: t.kind}
+	case p.is("-") && p.peek(1).kind == tokNumber:
+		p.next()
+		p.next()
+		return &literalType{kind: tokNumber}
+	case t.kind == tokTemplate:
+		p.next()
+		return &literalType{kind: tokString}
+	case p.is("true"), p.is("false"):
+		p.next()
+		return &literalType{kind: tokIdent}
+	case p.got("typeof"):
+		if p.got("import") {
+			p.skipBalanced()
+		} else {
+			p.ident()
+		}
+		for p.got(".") {
+			p.ident()
+		}
+		if p.is("<") && !p.tok().newline {
+			p.parseTypeArgs()
+		}
+		return &unknownType{}
+	case p.is("import") && p.peek(1).text == "(":
+		p.next()
+		p.skipBalanced()
+		for p.got(".") {
+			p.ident()
+		}
+		if p.is("<") {
+			p.parseTypeArgs()
+		}
+		return &unknownType{}
+	case p.is("asserts") && p.peek(1).kind == tokIdent:
+		p.next()
+		p.next()
+		if p.got("is") {
+			p.parseType()
+		}
+		return &typeRef{name: "void"}
+	case t.kind == tokIdent && p.peek(1).text == "is" && !p.peek(1).newline:
+		// Type predicates, like `x is string`.
+		p.next()
+		p.next()
+		p.parseType()
+		return &typeRef{name: "boolean"}
+	case t.kind == tokIdent:
+		ref := &typeRef{name: p.ident()}
+		for p.got(".") {
+			ref.name += "." + p.ident()
+		}
+		if p.is("<") && !p.tok().newline {
+			ref.args = p.parseTypeArgs()
+		}
+		return ref
+	default:
+		p.errorf("unexpected %s in type", describe(t))
+		return nil
+	}
+}
+
+// isMappedType reports whether the brace at the current token starts a mapped
+// type, like { readonly [P in keyof T]?: T[P] }.
+func (p *parser) isMappedType() bool {
+	n := 1
+	if t := p.peek(n); t.text == "+" || t.text == "-" {
+		n++
+	}
+	if p.peek(n).text == "readonly" {
+		n++
+	}
+	return p.peek(n).text == "[" && p.peek(n+1).kind == tokIdent && p.peek(n+2).text == "in"
+}
+
+func (p *parser) parseTypeArgs() []tsType {
+	p.expect("<")
+	var args []tsType
+	for !p.got(">") {
+		args = append(args, p.parseType())
+		if !p.got(",") {
+			p.expect(">")
+			break
+		}
+	}
+	return args
+}
@@ -0,0 +1,245 @@
+/// <reference no-default-lib="true"/>
+
+/**
+ * An abstract interface upon which many other DOM API objects depend.
+ *
+ * [MDN Reference](https://developer.mozilla.org/docs/Web/API/Node)
+ */
+interface Node extends EventTarget {
+    /** Returns the children. */
+    readonly childNodes: NodeListOf<ChildNode>;
+    readonly nodeName: string;
+    readonly nodeType: number;
+    readonly parentNode: ParentNode | null;
+    textContent: string | null;
+    appendChild<T extends Node>(node: T): T;
+    /**
+     * Returns a copy of node.
+     * @param deep Whether to copy the descendants of node too.
+     */
+    cloneNode(deep?: boolean): Node;
+    contains(other: Node | null): boolean;
+    readonly ELEMENT_NODE: 1;
+}
+
+declare var Node: {
+    prototype: Node;
+    new(): Node;
+    readonly ELEMENT_NODE: 1;
+};
+
+interface EventTarget {
+    addEventListener(type: string, callback: EventListenerOrEventListenerObject | null, options?: AddEventListenerOptions | boolean): void;
+    dispatchEvent(event: Event): boolean;
+}
+
+declare var EventTarget: {
+    prototype: EventTarget;
+    new(): EventTarget;
+};
+
+interface EventListener {
+    (evt: Event): void;
+}
+
+interface EventListenerObject {
+    handleEvent(object: Event): void;
+}
+
+type EventListenerOrEventListenerObject = EventListener | EventListenerObject;
+
+interface AddEventListenerOptions {
+    once?: boolean;
+    passive?: boolean;
+    signal?: AbortSignal;
+}
+
+interface Event {
+    readonly type: string;
+    readonly target: EventTarget | null;
+    readonly timeStamp: DOMHighResTimeStamp;
+    preventDefault(): void;
+    /** @deprecated Use composedPath() instead. */
+    readonly path: EventTarget[];
+}
+
+declare var Event: {
+    prototype: Event;
+    new(type: string, eventInitDict?: EventInit): Event;
+};
+
+interface EventInit {
+    bubbles?: boolean;
+}
+
+type DOMHighResTimeStamp = number;
+
+interface ParentNode extends Node {
+    readonly children: HTMLCollection;
+    querySelector<K extends keyof HTMLElementTagNameMap>(selectors: K): HTMLElementTagNameMap[K] | null;
+    querySelector<E extends Element = Element>(selectors: string): E | null;
+    append(...nodes: (Node | string)[]): void;
+}
+
+interface ChildNode extends Node {
+    remove(): void;
+}
+
+interface NodeListOf<TNode extends Node> {
+    readonly length: number;
+    item(index: number): TNode;
+    forEach(callbackfn: (value: TNode, key: number, parent: NodeListOf<TNode>) => void, thisArg?: any): void;
+}
+
+interface HTMLCollection {
+    readonly length: number;
+    item(index: number): Element | null;
+    [index: number]: Element;
+}
+
+interface Element extends Node, ParentNode, ChildNode {
+    id: string;
+    className: string;
+    getAttribute(qualifiedName: string): string | null;
+    getAttributeNames(): string[];
+    setAttribute(qualifiedName: string, value: string): void;
+    getBoundingClientRect(): DOMRect;
+    requestFullscreen(options?: FullscreenOptions): Promise<void>;
+    animate(keyframes: Keyframe[] | null, options?: number | KeyframeAnimationOptions): Animation;
+}
+
+interface HTMLElement extends Element {
+    hidden: boolean;
+    innerText: string;
+    onclick: ((this: GlobalEventHandlers, ev: MouseEvent) => any) | null;
+    click(): void;
+}
+
+interface HTMLElementTagNameMap {
+    "a": HTMLAnchorElement;
+    "div": HTMLDivElement;
+}
+
+interface HTMLAnchorElement extends HTMLElement {
+    href: string;
+}
+
+interface HTMLDivElement extends HTMLElement {
+    align: string;
+}
+
+interface MouseEvent extends Event {
+    readonly button: number;
+    readonly clientX: number;
+}
+
+interface DOMRect {
+    x: number;
+    y: number;
+    toJSON(): any;
+}
+
+interface Document extends Node, ParentNode {
+    title: string;
+    readonly body: HTMLElement;
+    createElement<K extends keyof HTMLElementTagNameMap>(tagName: K, options?: ElementCreationOptions): HTMLElementTagNameMap[K];
+    /** @deprecated */
+    createElement<K extends keyof HTMLElementDeprecatedTagNameMap>(tagName: K, options?: ElementCreationOptions): HTMLElementDeprecatedTagNameMap[K];
+    createElement(tagName: string, options?: ElementCreationOptions): HTMLElement;
+    getElementById(elementId: string): HTMLElement | null;
+    getElementsByTagName(qualifiedName: string): HTMLCollection;
+    hasFocus(): boolean;
+}
+
+interface Response {
+    readonly ok: boolean;
+    readonly status: number;
+    readonly headers: Headers;
+    json(): Promise<any>;
+    text(): Promise<string>;
+    arrayBuffer(): Promise<ArrayBuffer>;
+    clone(): Response;
+}
+
+interface Headers {
+    get(name: string): string | null;
+    has(name: string): boolean;
+    set(name: string, value: string): void;
+    forEach(callbackfn: (value: string, key: string, parent: Headers) => void, thisArg?: any): void;
+}
+
+declare var Headers: {
+    prototype: Headers;
+    new(init?: HeadersInit): Headers;
+};
+
+type HeadersInit = [string, string][] | Record<string, string> | Headers;
+
+interface RequestInit {
+    body?: BodyInit | null;
+    headers?: HeadersInit;
+    method?: string;
+    signal?: AbortSignal | null;
+}
+
+interface Storage {
+    readonly length: number;
+    clear(): void;
+    getItem(key: string): string | null;
+    key(index: number): string | null;
+    removeItem(key: string): void;
+    setItem(key: string, value: string): void;
+    [name: string]: any;
+}
+
+interface Console {
+    log(...data: any[]): void;
+    error(...data: any[]): void;
+}
+
+declare var console: Console;
+
+declare var document: Document;
+declare var localStorage: Storage;
+/** The number of device pixels per CSS pixel. */
+declare var devicePixelRatio: number;
+
+declare function fetch(input: RequestInfo | URL, init?: RequestInit): Promise<Response>;
+declare function queueMicrotask(callback: VoidFunction): void;
+declare function setTimeout(handler: TimerHandler, timeout?: number, ...arguments: any[]): number;
+declare function clearTimeout(id: number | undefined): void;
+declare function requestAnimationFrame(callback: FrameRequestCallback): number;
+declare function structuredClone<T = any>(value: T, options?: StructuredSerializeOptions): T;
+
+interface VoidFunction {
+    (): void;
+}
+
+interface FrameRequestCallback {
+    (time: DOMHighResTimeStamp): void;
+}
+
+type TimerHandler = string | Function;
+type RequestInfo = Request | string;
+type DocumentReadyState = "complete" | "interactive" | "loading";
+
+declare namespace WebAssembly {
+    interface Module {
+    }
+
+    var Module: {
+        prototype: Module;
+        new(bytes: BufferSource): Module;
+        exports(moduleObject: Module): ModuleExportDescriptor[];
+    };
+
+    interface ModuleExportDescriptor {
+        kind: ImportExportKind;
+        name: string;
+    }
+
+    type ImportExportKind = "function" | "global" | "memory" | "table";
+
+    function compile(bytes: BufferSource): Promise<Module>;
+    function validate(bytes: BufferSource): boolean;
+}
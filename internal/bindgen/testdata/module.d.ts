@@ -0,0 +1,60 @@
+// Type definitions for a hypothetical key-value store package.
+
+import { EventEmitter } from "events";
+
+export as namespace kvstore;
+
+/** Levels of consistency of reads. */
+export enum Consistency {
+    Eventual = "eventual",
+    Strong = "strong",
+}
+
+export const enum Flags {
+    None = 0,
+    Compress = 1 << 0,
+}
+
+/** Options of a Store. */
+export interface StoreOptions {
+    /** Name of the store, "default" if omitted. */
+    name?: string;
+    consistency?: Consistency;
+    flags?: Flags;
+    /** Called when a value changes. */
+    onChange?: (key: string, value: unknown) => void;
+}
+
+/** A persistent key-value store. */
+export declare class Store<V = unknown> extends EventEmitter {
+    /** Creates a store. */
+    constructor(options?: StoreOptions);
+    readonly name: string;
+    get size(): number;
+    /** Gets the value of key. */
+    get(key: string): Promise<V | undefined>;
+    set(key: string, value: V): Promise<this>;
+    delete(key: string): Promise<boolean>;
+    keys(): string[];
+    entries(): Array<[string, V]>;
+    close(): Promise<void>;
+    on(event: "change", listener: (key: string) => void): this;
+    private _lock;
+    static open(path: string, options?: StoreOptions): Promise<Store>;
+    static readonly version: string;
+    [Symbol.asyncIterator](): AsyncIterableIterator<[string, V]>;
+}
+
+export declare function defaultStore(): Store;
+export declare function compact(store: Store, options: { dryRun?: boolean }, signal?: AbortSignal): Promise<number>;
+
+export declare namespace util {
+    function encode(value: unknown, type?: "json" | "msgpack", level?: number): Uint8Array;
+    class Codec {
+        constructor(type: string);
+        encode(value: unknown): Uint8Array;
+    }
+}
+
+export declare const VERSION: string;
+export default Store;
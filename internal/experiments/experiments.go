@@ -35,7 +35,10 @@ func init() {
 
 // Flags contains flags for currently supported experiments.
 type Flags struct {
-	// e.g. Generics bool `flag:"generics"`
+	// MemStats enables instrumentation of heap allocations, which makes
+	// allocation counters reported by runtime.ReadMemStats() meaningful at the
+	// cost of slightly slower allocations.
+	MemStats bool `flag:"memstats"`
 }
 
 // parseFlags parses the `raw` flags string and populates flag values in the
@@ -85,6 +85,46 @@ func (tm *TestMain) Scan(fset *token.FileSet) error {
 	return nil
 }
 
+// ScanOverlay adds test functions from the overlay files that aren't defined
+// in the original package, so that tests can be added by natives.
+func (tm *TestMain) ScanOverlay(files []*ast.File, loc FuncLocation) error {
+	overlay := TestMain{}
+	for _, f := range files {
+		if err := overlay.scanFile(f, loc); err != nil {
+			return err
+		}
+	}
+
+	known := map[string]bool{}
+	for _, list := range [][]TestFunc{tm.Tests, tm.Benchmarks, tm.Fuzz} {
+		for _, t := range list {
+			known[t.Name] = true
+		}
+	}
+	for _, e := range tm.Examples {
+		known[e.Name] = true
+	}
+	addNew := func(to *[]TestFunc, from []TestFunc) {
+		for _, t := range from {
+			if !known[t.Name] {
+				*to = append(*to, t)
+			}
+		}
+	}
+	addNew(&tm.Tests, overlay.Tests)
+	addNew(&tm.Benchmarks, overlay.Benchmarks)
+	addNew(&tm.Fuzz, overlay.Fuzz)
+	for _, e := range overlay.Examples {
+		if !known[e.Name] {
+			tm.Examples = append(tm.Examples, e)
+		}
+	}
+	if tm.TestMain == nil {
+		tm.TestMain = overlay.TestMain
+	}
+	return nil
+}
+
 func (tm *TestMain) scanPkg(fset *token.FileSet, files []string, loc FuncLocation) error {
 	for _, name := range files {
 		srcPath := path.Join(tm.Package.Dir, name)
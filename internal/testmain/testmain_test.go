@@ -1,6 +1,7 @@
 package testmain_test
 
 import (
+	"go/ast"
 	gobuild "go/build"
 	"go/token"
 	"testing"
@@ -58,6 +59,37 @@ func TestScan(t *testing.T) {
 	}
 }
 
+func TestScanOverlay(t *testing.T) {
+	f := srctesting.New(t)
+	overlay := f.Parse("gopherjs__foo_test.go", `package foo
+
+	import "testing"
+
+	func TestXxx(t *testing.T) {}
+	func TestNew(t *testing.T) {}
+	func BenchmarkNew(b *testing.B) {}`)
+
+	got := TestMain{
+		Tests: []TestFunc{{Location: LocInPackage, Name: "TestXxx"}},
+	}
+	if err := got.ScanOverlay([]*ast.File{overlay}, LocInPackage); err != nil {
+		t.Fatalf("Got: tm.ScanOverlay() returned error: %s. Want: no error.", err)
+	}
+
+	want := TestMain{
+		Tests: []TestFunc{
+			{Location: LocInPackage, Name: "TestXxx"},
+			{Location: LocInPackage, Name: "TestNew"},
+		},
+		Benchmarks: []TestFunc{
+			{Location: LocInPackage, Name: "BenchmarkNew"},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("List of test function is different from expected (-want,+got):\n%s", diff)
+	}
+}
+
 func TestSynthesize(t *testing.T) {
 	pkg := &gobuild.Package{ImportPath: "foo/bar"}
 
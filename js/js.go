@@ -19,10 +19,11 @@
 //	| arrays                | see slice type        | see slice type          |
 //	| functions             | Function              | func(...any) *js.Object |
 //	| time.Time             | Date                  | time.Time               |
+//	| channels              | async iterator        | -                       |
 //	| -                     | instanceof Node       | *js.Object              |
 //	| maps, structs         | instanceof Object     | map[string]any          |
 //
-// Additionally, for a struct containing a *js.Object field, only the content of the field will be passed to JavaScript and vice versa.
+// Additionally, for a struct containing a *js.Object field, only the content of the field will be passed to JavaScript and vice versa. Channels that can be received from are passed as async iterators of their values, and JavaScript async iterables are converted to channels that receive their values, see ReadableStreamOf and ChanOfAsyncIterable.
 package js
 
 // Object is a container for a native JavaScript object. Calls to its methods are treated specially by GopherJS and translated directly to their JavaScript syntax. A nil pointer to Object is equal to JavaScript's "null". Object can not be used as a map key.
@@ -84,8 +85,11 @@ type Error struct {
 	*Object
 }
 
-// Error returns the message of the encapsulated JavaScript error object.
+// Error returns the message of the encapsulated JavaScript error object. Values thrown or rejected that have no message, like strings, null or undefined, are converted to string instead.
 func (err *Error) Error() string {
+	if err.Object == nil || err.Object == Undefined || err.Get("message") == Undefined {
+		return "JavaScript error: " + Global.Call("String", err.Object).String()
+	}
 	return "JavaScript error: " + err.Get("message").String()
 }
 
@@ -122,6 +126,84 @@ func MakeFunc(fn func(this *Object, arguments []*Object) any) *Object {
 	return Global.Call("$makeFunc", InternalObject(fn))
 }
 
+// MakeAsyncFunc is like MakeFunc, but the returned JavaScript function calls fn in a new goroutine, so fn may block. It returns a Promise that resolves to the value returned by fn, or is rejected with a JavaScript Error if fn panics.
+func MakeAsyncFunc(fn func(this *Object, arguments []*Object) any) *Object {
+	return Global.Call("$makeAsyncFunc", InternalObject(fn))
+}
+
+// Async converts the Go function fn to a JavaScript function like passing fn to JavaScript does, except that the JavaScript function calls fn in a new goroutine and returns a Promise, like functions made with MakeAsyncFunc. Calls of Async with a function argument are translated directly by GopherJS.
+func Async(fn any) *Object {
+	return Global.Call("$externalizeAsync", InternalObject(fn))
+}
+
+// Await blocks the calling goroutine until the promise (or any other value) p settles. It returns the value p resolved to, or an *Error holding the reason p was rejected with. Await panics if it is not called by a goroutine, e.g. in a function called by JavaScript, since those can't block.
+func Await(p *Object) (*Object, error) {
+	if Global.Get("$curGoroutine") == Global.Get("$noGoroutine") {
+		panic("js.Await: cannot wait for a promise in a JavaScript callback, fix by wrapping code in goroutine")
+	}
+	type result struct {
+		value    *Object
+		rejected bool
+	}
+	c := make(chan result, 1)
+	Global.Call("$awaitPromise", p, InternalObject(func(value *Object, rejected bool) {
+		c <- result{value, rejected}
+	}))
+	r := <-c
+	if r.rejected {
+		return nil, &Error{r.value}
+	}
+	return r.value, nil
+}
+
+// NewPromise returns a JavaScript Promise that calls fn in a new goroutine. The promise resolves to the value returned by fn, converted like arguments passed to JavaScript, or is rejected if fn returns an error. The reason is the JavaScript value of an *Error, and a JavaScript Error with the message of any other error.
+func NewPromise(fn func() (any, error)) *Object {
+	var resolve, reject *Object
+	p := Global.Get("Promise").New(InternalObject(func(res, rej *Object) {
+		resolve, reject = res, rej
+	}))
+	go func() {
+		v, err := fn()
+		if err == nil {
+			resolve.Invoke(v)
+		} else if jsErr, ok := err.(*Error); ok {
+			reject.Invoke(jsErr.Object)
+		} else {
+			reject.Invoke(Global.Get("Error").New(err.Error()))
+		}
+	}()
+	return p
+}
+
+// ReadableStreamOf returns a JavaScript ReadableStream of the values received from the channel ch, converted like values passed to JavaScript. The stream receives a value only when its reader pulls one, so senders block until then, and it ends when ch is closed. Cancelling the stream stops receiving, but doesn't close ch. ReadableStreamOf panics if ch is not a channel that can be received from.
+//
+// Passing such a channel to JavaScript directly gives an async iterator of its values, which can be used with for await.
+//
+// Like a Go function passed to JavaScript, the stream or iterator may wake the program at any time, so until it is done or cancelled, goroutines blocked on ch are not reported as a deadlock.
+func ReadableStreamOf(ch any) *Object {
+	return Global.Call("$chanReadableStream", InternalObject(ch))
+}
+
+// ChanOfAsyncIterable returns a channel of the values of the JavaScript async iterable it, like an async generator or a ReadableStream. The next value is requested once the previous one has been received from values, and values is closed at the end of the iteration. Then errc receives the *Error the iteration failed with, or nil. ChanOfAsyncIterable panics if it is not an async iterable.
+//
+// JavaScript async iterables passed to Go as a channel type, e.g. as an argument of a Go function called by JavaScript, are converted the same way, except that errors end the iteration silently.
+func ChanOfAsyncIterable(it *Object) (values <-chan *Object, errc <-chan error) {
+	iterator := Global.Call("$asyncIteratorOf", it)
+	if iterator == Undefined {
+		panic("js.ChanOfAsyncIterable: argument is not an async iterable")
+	}
+	v := make(chan *Object)
+	e := make(chan error, 1)
+	Global.Call("$iteratorToChan", iterator, InternalObject(v), InternalObject(func(value *Object) *Object { return value }), InternalObject(func(err *Object) {
+		if err != nil {
+			e <- &Error{err}
+			return
+		}
+		e <- nil
+	}))
+	return v, e
+}
+
 // Keys returns the keys of the given JavaScript object.
 func Keys(o *Object) []string {
 	if o == nil || o == Undefined {
@@ -246,12 +328,59 @@ func MakeFullWrapper(i any) *Object {
 	return wrapperObj
 }
 
-// NewArrayBuffer creates a JavaScript ArrayBuffer from a byte slice.
+// Marshaler is implemented by types that control how their values are converted to JavaScript, e.g. to a string for an identifier type. ExternalizeJS is called wherever a value of the type is passed to JavaScript, including by Marshal, instead of the default conversion. Nil pointers are converted to null without calling it.
+type Marshaler interface {
+	ExternalizeJS() *Object
+}
+
+// Unmarshaler is implemented by pointers to types that control how their values are converted from JavaScript. InternalizeJS is called on a pointer to a new zero value wherever a JavaScript value is converted to the type, or on the existing value in Unmarshal. An error returned by InternalizeJS is panicked with, except in Unmarshal, which returns an error with its message.
+type Unmarshaler interface {
+	InternalizeJS(o *Object) error
+}
+
+// Marshal converts v to a plain JavaScript value, copying it recursively. Unlike passing v to JavaScript, which wraps pointers to structs, structs become objects with a property for each exported field, including the fields of embedded structs. time.Time becomes a Date, []byte a Uint8Array, other slices and arrays become arrays and maps with string or integer keys become objects. *Object and structs embedding it become the object itself, and functions are converted like arguments passed to JavaScript. Values implementing Marshaler are converted by their ExternalizeJS method, and Unmarshal uses the InternalizeJS method of Unmarshaler.
+//
+// The "js" tag of a struct field works like the "json" tag of encoding/json: it gives the name of the property, optionally followed by ",omitempty" to leave out empty values and ",string" to convert numbers and booleans to strings, which preserves the precision of 64-bit integers. Fields tagged "-" are left out.
+//
+// Marshal panics if v contains a channel, a complex number or a cycle.
+func Marshal(v any) *Object {
+	return Global.Call("$jsMarshal", InternalObject(v))
+}
+
+// Unmarshal stores the JavaScript value o in the value pointed to by v, converting it like Marshal in reverse. Properties are matched to fields by the name Marshal would use, or case-insensitively if there is no exact match, and other properties are ignored. Existing structs, maps and pointers in v are updated in place. Unmarshal returns an error if a value doesn't fit the Go type, in which case v may be partially updated.
+func Unmarshal(o *Object, v any) error {
+	if msg := Global.Call("$jsUnmarshal", o, InternalObject(v)); msg != nil {
+		return unmarshalError(msg.String())
+	}
+	return nil
+}
+
+type unmarshalError string
+
+func (err unmarshalError) Error() string { return string(err) }
+
+// NewArrayBuffer creates a JavaScript ArrayBuffer from a byte slice. The bytes are copied, use TypedArrayOf(b) to share them with JavaScript instead.
 func NewArrayBuffer(b []byte) *Object {
 	slice := InternalObject(b)
-	offset := slice.Get("$offset").Int()
-	length := slice.Get("$length").Int()
-	return slice.Get("$array").Get("buffer").Call("slice", offset, offset+length)
+	array := slice.Get("$array")
+	size := array.Get("BYTES_PER_ELEMENT").Int()
+	offset := array.Get("byteOffset").Int() + slice.Get("$offset").Int()*size
+	length := slice.Get("$length").Int() * size
+	return array.Get("buffer").Call("slice", offset, offset+length)
+}
+
+// TypedArrayOf returns a typed array that shares memory with slice, without copying. slice must be a slice of a number type other than the 64-bit integer and complex types, which map to typed arrays as follows: byte and uint8 to Uint8Array, int8 to Int8Array, int16 and uint16 to Int16Array and Uint16Array, int and int32 to Int32Array, uint, uint32 and uintptr to Uint32Array, and float32 and float64 to Float32Array and Float64Array. TypedArrayOf panics for other types. Passing such a slice to JavaScript, e.g. as an argument of Call, converts it the same way.
+//
+// Writes through the slice are visible in the typed array and vice versa, as long as both use the same memory. The typed array covers the elements of slice up to its length, but its buffer is the whole backing array of slice, which other slices may share as well. Appending to slice beyond its capacity moves it to new memory that isn't shared with the typed array. Detaching the buffer in JavaScript, e.g. by transferring it with postMessage, makes the slice and all slices sharing its backing array unusable, so it must not be done while Go code still uses them.
+func TypedArrayOf(slice any) *Object {
+	return Global.Call("$typedArrayOf", InternalObject(slice))
+}
+
+// SliceOfTypedArray sets the slice pointed to by slicePtr to a slice that shares memory with the typed array a, without copying. The element type must match the type of a like for TypedArrayOf, e.g. slicePtr must be a *[]float32 for a Float32Array, except that a Uint8ClampedArray may be used for a byte slice. a may also be an ArrayBuffer whose size is a multiple of the element size, which the slice then covers entirely. SliceOfTypedArray panics if slicePtr is not a pointer to a slice of a suitable type or a doesn't match it.
+//
+// The slice has the length and capacity of a, so appending to it moves it to new memory that isn't shared with a. Otherwise the aliasing rules of TypedArrayOf apply.
+func SliceOfTypedArray(a *Object, slicePtr any) {
+	Global.Call("$sliceOfTypedArray", a, InternalObject(slicePtr))
 }
 
 // M is a simple map type. It is intended as a shorthand for JavaScript objects (before conversion).
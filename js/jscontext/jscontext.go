@@ -0,0 +1,127 @@
+//go:build js
+
+// Package jscontext bridges context.Context and the AbortSignal API of
+// JavaScript, so that cancellation propagates between Go code and JavaScript
+// APIs like fetch() in both directions.
+//
+// While a context waits for a signal to abort, the program is kept alive and
+// isn't reported as deadlocked, since the signal may still cancel the context.
+// The bridges stop listening as soon as the context is done.
+//
+// These helpers live outside of the js package, because the context package
+// depends on packages that import it.
+package jscontext
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// AbortSignal returns an AbortSignal that is aborted when ctx is done. The
+// abort reason is the JavaScript value held by a *js.Error cause of ctx (see
+// context.Cause), such as the reason of a signal passed to WithAbortSignal.
+// Otherwise it is a DOMException with the message of the cause, named
+// "TimeoutError" if the deadline of ctx was exceeded and "AbortError" if not,
+// like the reasons of AbortSignal.timeout() and AbortController.abort().
+//
+// The signal never aborts if ctx can't be canceled. Otherwise a goroutine
+// waits for ctx to be done, so cancel ctx once it's no longer needed.
+func AbortSignal(ctx context.Context) *js.Object {
+	controller := js.Global.Get("AbortController").New()
+	done := ctx.Done()
+	if done == nil {
+		return controller.Get("signal")
+	}
+	select {
+	case <-done:
+		controller.Call("abort", abortReason(ctx))
+	default:
+		go func() {
+			<-done
+			controller.Call("abort", abortReason(ctx))
+		}()
+	}
+	return controller.Get("signal")
+}
+
+// abortReason returns the reason to abort a signal with once ctx is done.
+func abortReason(ctx context.Context) *js.Object {
+	cause := context.Cause(ctx)
+	var jsErr *js.Error
+	if errors.As(cause, &jsErr) && jsErr.Object != nil {
+		return jsErr.Object
+	}
+	name := "AbortError"
+	if errors.Is(cause, context.DeadlineExceeded) {
+		name = "TimeoutError"
+	}
+	if exception := js.Global.Get("DOMException"); exception != js.Undefined {
+		return exception.New(cause.Error(), name)
+	}
+	err := js.Global.Get("Error").New(cause.Error())
+	err.Set("name", name)
+	return err
+}
+
+// WithAbortSignal returns a copy of parent that is canceled when signal
+// aborts, or when the returned cancel function is called, whichever happens
+// first. When signal aborts, context.Cause of the returned context is a
+// *js.Error holding the reason of signal.
+//
+// The listener added to signal is removed as soon as the returned context is
+// done. Until then the program is kept alive, so cancel the context once it's
+// no longer needed.
+func WithAbortSignal(parent context.Context, signal *js.Object) (ctx context.Context, cancel context.CancelFunc) {
+	ctx, cancelCause := context.WithCancelCause(parent)
+	cancel = func() { cancelCause(nil) }
+	if signal.Get("aborted").Bool() {
+		cancelCause(signalCause(signal))
+		return ctx, cancel
+	}
+
+	listener := js.MakeFunc(func(this *js.Object, arguments []*js.Object) any {
+		cancelCause(signalCause(signal))
+		return nil
+	})
+	signal.Call("addEventListener", "abort", listener, js.M{"once": true})
+	hold(1)
+	go func() {
+		<-ctx.Done()
+		signal.Call("removeEventListener", "abort", listener)
+		hold(-1)
+	}()
+	return ctx, cancel
+}
+
+var (
+	listeners int        // Number of signals listened to by WithAbortSignal.
+	keepAlive *js.Object // Interval keeping Node.js running while listening.
+)
+
+// hold adjusts the number of signals listened to. While there are any, the
+// deadlock detector must not report the program as stuck, and Node.js must not
+// exit, since timers like the one of AbortSignal.timeout() don't keep it alive.
+func hold(delta int) {
+	listeners += delta
+	switch {
+	case delta > 0 && listeners == 1:
+		js.Global.Set("$exportedFunctions", js.Global.Get("$exportedFunctions").Int()+1)
+		keepAlive = js.Global.Call("setInterval", js.InternalObject(func() {}), 1<<30)
+	case delta < 0 && listeners == 0:
+		js.Global.Set("$exportedFunctions", js.Global.Get("$exportedFunctions").Int()-1)
+		js.Global.Call("clearInterval", keepAlive)
+		keepAlive = nil
+	}
+}
+
+// signalCause returns the cause to cancel a context with when signal aborts.
+// Environments that predate AbortSignal.reason give context.Canceled.
+func signalCause(signal *js.Object) error {
+	reason := signal.Get("reason")
+	if reason == js.Undefined {
+		return context.Canceled
+	}
+	return &js.Error{Object: reason}
+}
@@ -0,0 +1,153 @@
+//go:build js
+
+package jscontext_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gopherjs/gopherjs/js"
+	"github.com/gopherjs/gopherjs/js/jscontext"
+)
+
+// waitAbort blocks until signal aborts and returns its reason.
+func waitAbort(signal *js.Object) *js.Object {
+	if !signal.Get("aborted").Bool() {
+		aborted := make(chan struct{})
+		signal.Call("addEventListener", "abort", func() { close(aborted) })
+		<-aborted
+	}
+	return signal.Get("reason")
+}
+
+func TestAbortSignal(t *testing.T) {
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		signal := jscontext.AbortSignal(ctx)
+		if signal.Get("aborted").Bool() {
+			t.Fatal("signal aborted before the context was canceled")
+		}
+		cancel()
+		reason := waitAbort(signal)
+		if got := reason.Get("name").String(); got != "AbortError" {
+			t.Errorf("Got reason named %q, want AbortError", got)
+		}
+		if got, want := reason.Get("message").String(), context.Canceled.Error(); got != want {
+			t.Errorf("Got reason message %q, want %q", got, want)
+		}
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		if got := waitAbort(jscontext.AbortSignal(ctx)).Get("name").String(); got != "TimeoutError" {
+			t.Errorf("Got reason named %q, want TimeoutError", got)
+		}
+	})
+
+	t.Run("already done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if !jscontext.AbortSignal(ctx).Get("aborted").Bool() {
+			t.Error("signal of a done context isn't aborted")
+		}
+	})
+
+	t.Run("JavaScript cause", func(t *testing.T) {
+		reason := js.Global.Get("Error").New("stop")
+		ctx, cancel := context.WithCancelCause(context.Background())
+		cancel(&js.Error{Object: reason})
+		if got := waitAbort(jscontext.AbortSignal(ctx)); got != reason {
+			t.Errorf("Got reason %v, want the JavaScript cause %v", got, reason)
+		}
+	})
+
+	t.Run("never canceled", func(t *testing.T) {
+		if jscontext.AbortSignal(context.Background()).Get("aborted").Bool() {
+			t.Error("signal of a background context is aborted")
+		}
+	})
+}
+
+func TestWithAbortSignal(t *testing.T) {
+	exported := js.Global.Get("$exportedFunctions").Int()
+
+	t.Run("aborted", func(t *testing.T) {
+		controller := js.Global.Get("AbortController").New()
+		ctx, cancel := jscontext.WithAbortSignal(context.Background(), controller.Get("signal"))
+		defer cancel()
+		if ctx.Err() != nil {
+			t.Fatalf("Got error %v before the signal aborted, want nil", ctx.Err())
+		}
+		reason := js.Global.Get("Error").New("stop")
+		controller.Call("abort", reason)
+		<-ctx.Done()
+		if !errors.Is(ctx.Err(), context.Canceled) {
+			t.Errorf("Got error %v, want %v", ctx.Err(), context.Canceled)
+		}
+		var jsErr *js.Error
+		if !errors.As(context.Cause(ctx), &jsErr) || jsErr.Object != reason {
+			t.Errorf("Got cause %v, want a *js.Error holding %v", context.Cause(ctx), reason)
+		}
+	})
+
+	t.Run("already aborted", func(t *testing.T) {
+		signal := js.Global.Get("AbortSignal").Call("abort", "gone")
+		ctx, cancel := jscontext.WithAbortSignal(context.Background(), signal)
+		defer cancel()
+		if got, want := context.Cause(ctx).Error(), "JavaScript error: gone"; got != want {
+			t.Errorf("Got cause %q, want %q", got, want)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		controller := js.Global.Get("AbortController").New()
+		ctx, cancel := jscontext.WithAbortSignal(context.Background(), controller.Get("signal"))
+		cancel()
+		controller.Call("abort")
+		if got := context.Cause(ctx); got != context.Canceled {
+			t.Errorf("Got cause %v after cancel, want %v", got, context.Canceled)
+		}
+	})
+
+	t.Run("parent canceled", func(t *testing.T) {
+		parent, cancelParent := context.WithCancel(context.Background())
+		ctx, cancel := jscontext.WithAbortSignal(parent, js.Global.Get("AbortController").New().Get("signal"))
+		defer cancel()
+		cancelParent()
+		<-ctx.Done()
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		// Only the timer of the signal is pending while the test waits, which
+		// must not be reported as a deadlock.
+		ctx, cancel := jscontext.WithAbortSignal(context.Background(), js.Global.Get("AbortSignal").Call("timeout", 50))
+		defer cancel()
+		<-ctx.Done()
+		var jsErr *js.Error
+		if !errors.As(context.Cause(ctx), &jsErr) || jsErr.Get("name").String() != "TimeoutError" {
+			t.Errorf("Got cause %v, want a *js.Error holding a TimeoutError", context.Cause(ctx))
+		}
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		parent, cancelParent := context.WithCancelCause(context.Background())
+		ctx, cancel := jscontext.WithAbortSignal(context.Background(), jscontext.AbortSignal(parent))
+		defer cancel()
+		reason := js.Global.Get("Error").New("stop")
+		cancelParent(&js.Error{Object: reason})
+		<-ctx.Done()
+		var jsErr *js.Error
+		if !errors.As(context.Cause(ctx), &jsErr) || jsErr.Object != reason {
+			t.Errorf("Got cause %v, want a *js.Error holding %v", context.Cause(ctx), reason)
+		}
+	})
+
+	// Let the goroutines of the done contexts stop listening.
+	time.Sleep(10 * time.Millisecond)
+	if got := js.Global.Get("$exportedFunctions").Int(); got != exported {
+		t.Errorf("Got %d exported functions, want %d", got, exported)
+	}
+}
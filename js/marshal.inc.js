@@ -0,0 +1,618 @@
+// Helpers of js.Marshal and js.Unmarshal, which convert between Go values and
+// plain JavaScript objects by walking the type descriptors of the Go values.
+// Struct fields are mapped to properties according to their "js" tags, like
+// encoding/json does with "json" tags.
+//
+// They are part of the js package rather than the prelude. Only $jsMarshal and
+// $jsUnmarshal are global variables, which js.Marshal and js.Unmarshal call.
+
+// $UnmarshalError carries the message of the error returned by js.Unmarshal.
+var $UnmarshalError = function (message) {
+    this.message = message;
+};
+
+var $marshalPanic = message => {
+    $panic(new $String("js.Marshal: " + message));
+};
+
+var $isTimeType = t => {
+    const timePkg = $packages["time"];
+    return timePkg !== undefined && t === timePkg.Time;
+};
+
+// $hasJsObject returns true if the first field of struct type t, or of its
+// first embedded struct, is a *js.Object. Such structs are the object itself
+// in JavaScript.
+var $hasJsObject = t => {
+    const seen = new Set();
+    for (;;) {
+        if (t === $jsObjectPtr) {
+            return true;
+        }
+        if (t.kind === $kindPtr) {
+            t = t.elem;
+        }
+        if (t.kind !== $kindStruct || t.fields.length === 0 || seen.has(t)) {
+            return false;
+        }
+        seen.add(t);
+        t = t.fields[0].typ;
+    }
+};
+
+var $jsTagPattern = /(?:^|\s)js:"((?:[^"\\]|\\.)*)"/;
+
+// $parseJsTag returns the options of the "js" tag of a struct field.
+var $parseJsTag = tag => {
+    const match = $jsTagPattern.exec(tag);
+    if (match === null) {
+        return { name: "", omitempty: false, string: false, skip: false };
+    }
+    const parts = JSON.parse('"' + match[1] + '"').split(",");
+    return {
+        name: parts[0],
+        omitempty: parts.includes("omitempty", 1),
+        string: parts.includes("string", 1),
+        skip: parts[0] === "-" && parts.length === 1,
+    };
+};
+
+var $compareFieldIndex = (a, b) => {
+    for (let i = 0; i < a.index.length && i < b.index.length; i++) {
+        if (a.index[i] !== b.index[i]) {
+            return a.index[i] - b.index[i];
+        }
+    }
+    return a.index.length - b.index.length;
+};
+
+var $marshalFieldsCache = new Map();
+
+// $marshalFields returns the properties of struct type t in field order. Each
+// has the path of fields from t to the field, which includes the embedded
+// structs it's promoted from. Like in encoding/json, an untagged embedded
+// struct is inlined, a shallower field hides deeper ones and fields with the
+// same name at the same depth hide each other, unless exactly one is tagged.
+var $marshalFields = t => {
+    let result = $marshalFieldsCache.get(t);
+    if (result !== undefined) {
+        return result;
+    }
+    result = [];
+    const hidden = new Set();
+    const visited = new Set();
+    let current = [{ t, path: [], index: [] }];
+    while (current.length > 0) {
+        const next = [];
+        const found = new Map();
+        for (const { t, path, index } of current) {
+            if (visited.has(t)) {
+                continue;
+            }
+            visited.add(t);
+            t.fields.forEach((f, i) => {
+                const tag = $parseJsTag(f.tag);
+                if (tag.skip) {
+                    return;
+                }
+                if (f.embedded && tag.name === "") {
+                    const st = f.typ.kind === $kindPtr ? f.typ.elem : f.typ;
+                    if (st.kind === $kindStruct && !$isTimeType(st) && !$hasJsObject(st)) {
+                        next.push({ t: st, path: path.concat([f]), index: index.concat([i]) });
+                        return;
+                    }
+                }
+                if (!f.exported) {
+                    return;
+                }
+                const name = tag.name || f.name;
+                if (!found.has(name)) {
+                    found.set(name, []);
+                }
+                found.get(name).push({
+                    name,
+                    field: f,
+                    path: path.concat([f]),
+                    index: index.concat([i]),
+                    tagged: tag.name !== "",
+                    omitempty: tag.omitempty,
+                    string: tag.string,
+                });
+            });
+        }
+        for (const [name, candidates] of found) {
+            if (hidden.has(name)) {
+                continue;
+            }
+            hidden.add(name);
+            const tagged = candidates.filter(c => c.tagged);
+            if (candidates.length === 1) {
+                result.push(candidates[0]);
+            } else if (tagged.length === 1) {
+                result.push(tagged[0]);
+            }
+        }
+        current = next;
+    }
+    result.sort($compareFieldIndex);
+    $marshalFieldsCache.set(t, result);
+    return result;
+};
+
+var $isEmptyValue = (v, t) => {
+    switch (t.kind) {
+        case $kindBool:
+            return !v;
+        case $kindInt64:
+        case $kindUint64:
+            return v.$high === 0 && v.$low === 0;
+        case $kindString:
+            return v === "";
+        case $kindArray:
+            return t.len === 0;
+        case $kindSlice:
+            return v.$length === 0;
+        case $kindMap:
+            return v.keys === undefined || v.size === 0;
+        case $kindPtr:
+        case $kindFunc:
+            return v === t.nil;
+        case $kindInterface:
+            return v === $ifaceNil;
+        case $kindStruct:
+            return false;
+        default:
+            return v === 0;
+    }
+};
+
+var $int64String = (v, t) => {
+    if (typeof BigInt !== "function") {
+        return String($flatten64(v));
+    }
+    const n = (BigInt(v.$high >>> 0) << BigInt(32)) | BigInt(v.$low >>> 0);
+    return (t.kind === $kindInt64 ? BigInt.asIntN(64, n) : n).toString();
+};
+
+var $marshalKey = (k, t) => {
+    switch (t.kind) {
+        case $kindString:
+            return $externalize(k, t);
+        case $kindInt64:
+        case $kindUint64:
+            return $int64String(k, t);
+        case $kindInt:
+        case $kindInt8:
+        case $kindInt16:
+        case $kindInt32:
+        case $kindUint:
+        case $kindUint8:
+        case $kindUint16:
+        case $kindUint32:
+        case $kindUintptr:
+            return String(k);
+    }
+    $marshalPanic("unsupported map key type " + t.string);
+};
+
+// $marshal converts the Go value v of type t. Pointers and maps being
+// converted are in active, so that cycles are detected.
+var $marshal = (v, t, string, active) => {
+    if (t === $jsObjectPtr || $jsMarshaler(t) !== undefined) {
+        return $externalize(v, t);
+    }
+    switch (t.kind) {
+        case $kindBool:
+        case $kindInt:
+        case $kindInt8:
+        case $kindInt16:
+        case $kindInt32:
+        case $kindUint:
+        case $kindUint8:
+        case $kindUint16:
+        case $kindUint32:
+        case $kindUintptr:
+        case $kindFloat32:
+        case $kindFloat64:
+            return string ? String(v) : v;
+        case $kindInt64:
+        case $kindUint64:
+            return string ? $int64String(v, t) : $flatten64(v);
+        case $kindString:
+            return $externalize(v, t);
+        case $kindArray:
+            return Array.from(v, e => $marshal(e, t.elem, false, active));
+        case $kindSlice:
+            if (v === t.nil) {
+                return null;
+            }
+            if (t.elem.kind === $kindUint8) {
+                return $sliceToNativeArray(v).slice();
+            }
+            return Array.from($sliceToNativeArray(v), e => $marshal(e, t.elem, false, active));
+        case $kindMap: {
+            if (v.keys === undefined) {
+                return null;
+            }
+            if (active.has(v)) {
+                $marshalPanic("cycle through " + t.string);
+            }
+            active.add(v);
+            const entries = Array.from(v.values(), e => [$marshalKey(e.k, t.key), e.v]);
+            entries.sort((a, b) => a[0] < b[0] ? -1 : a[0] > b[0] ? 1 : 0);
+            const o = {};
+            for (const [k, e] of entries) {
+                o[k] = $marshal(e, t.elem, false, active);
+            }
+            active.delete(v);
+            return o;
+        }
+        case $kindPtr: {
+            if (v === t.nil) {
+                return null;
+            }
+            if (active.has(v)) {
+                $marshalPanic("cycle through " + t.string);
+            }
+            active.add(v);
+            const o = $marshal(v.$get(), t.elem, string, active);
+            active.delete(v);
+            return o;
+        }
+        case $kindInterface:
+            if (v === $ifaceNil) {
+                return null;
+            }
+            if (v.constructor === $jsObjectPtr) {
+                return v.$val.object;
+            }
+            return $marshal(v.$val, v.constructor, false, active);
+        case $kindFunc:
+            return $externalize(v, t);
+        case $kindStruct: {
+            if ($isTimeType(t) || $hasJsObject(t)) {
+                return $externalize(v, t);
+            }
+            const o = {};
+            fields: for (const f of $marshalFields(t)) {
+                let holder = v;
+                for (let i = 0; i < f.path.length - 1; i++) {
+                    const e = f.path[i];
+                    holder = holder[e.prop];
+                    if (e.typ.kind === $kindPtr && holder === e.typ.nil) {
+                        continue fields;
+                    }
+                }
+                const fv = holder[f.field.prop];
+                if (f.omitempty && $isEmptyValue(fv, f.field.typ)) {
+                    continue;
+                }
+                o[f.name] = $marshal(fv, f.field.typ, f.string, active);
+            }
+            return o;
+        }
+    }
+    $marshalPanic("unsupported type " + t.string);
+};
+
+$global.$jsMarshal = i => {
+    if (i === $ifaceNil) {
+        return null;
+    }
+    return $marshal(i.$val, i.constructor, false, new Set());
+};
+
+var $describeJsValue = x => {
+    if (x === null) {
+        return "null";
+    }
+    if (Array.isArray(x)) {
+        return "array";
+    }
+    switch (typeof x) {
+        case "number":
+            return "number " + x;
+        case "string":
+            return "string";
+        case "object":
+            return x.constructor === Date ? "Date" : ArrayBuffer.isView(x) ? "typed array" : "object";
+    }
+    return typeof x;
+};
+
+// $unmarshalContext describes where a value is stored for error messages,
+// like "Go struct field T.A.B" in the errors of encoding/json.
+var $unmarshalContext = (ctx, t) => {
+    if (ctx === undefined) {
+        return "Go value of type " + t.string;
+    }
+    return "Go struct field " + ctx.struct + "." + ctx.fields.join(".") + " of type " + t.string;
+};
+
+var $unmarshalTypeError = (x, t, ctx) => {
+    throw new $UnmarshalError("js: cannot unmarshal " + $describeJsValue(x) + " into " + $unmarshalContext(ctx, t));
+};
+
+var $numberLiteral = /^-?(?:0|[1-9]\d*)(?:\.\d+)?(?:[eE][+-]?\d+)?$/;
+
+var $integerRanges = {
+    [$kindInt]: [-2147483648, 2147483647],
+    [$kindInt8]: [-128, 127],
+    [$kindInt16]: [-32768, 32767],
+    [$kindInt32]: [-2147483648, 2147483647],
+    [$kindUint]: [0, 4294967295],
+    [$kindUint8]: [0, 255],
+    [$kindUint16]: [0, 65535],
+    [$kindUint32]: [0, 4294967295],
+    [$kindUintptr]: [0, 4294967295],
+};
+
+// $unmarshalNumber returns the number in x, which may be a string of a number
+// literal with the "string" option, or undefined.
+var $unmarshalNumber = (x, string) => {
+    if (string && typeof x === "string" && $numberLiteral.test(x)) {
+        return Number(x);
+    }
+    if (!string && typeof x === "number") {
+        return x;
+    }
+    return undefined;
+};
+
+var $unmarshalInt64 = (x, t, string, ctx) => {
+    // 2^63 and 2^64 are exact doubles, unlike the largest values of the types,
+    // which round up to them.
+    const min = t.kind === $kindInt64 ? -(2 ** 63) : 0;
+    const limit = t.kind === $kindInt64 ? 2 ** 63 : 2 ** 64;
+    if (typeof BigInt === "function" && (typeof x === "bigint" || (string && typeof x === "string" && /^-?\d+$/.test(x)))) {
+        const n = BigInt(x);
+        const bits = t.kind === $kindInt64 ? BigInt.asIntN(64, n) : BigInt.asUintN(64, n);
+        if (bits !== n) {
+            throw new $UnmarshalError("js: cannot unmarshal " + $describeJsValue(x) + " " + x + " into " + $unmarshalContext(ctx, t));
+        }
+        return new t(Number(bits >> BigInt(32)), Number(BigInt.asUintN(32, bits)));
+    }
+    const n = $unmarshalNumber(x, string);
+    if (n === undefined) {
+        $unmarshalTypeError(x, t, ctx);
+    }
+    if (!Number.isInteger(n) || n < min || n >= limit) {
+        throw new $UnmarshalError("js: cannot unmarshal number " + x + " into " + $unmarshalContext(ctx, t));
+    }
+    return new t(0, n);
+};
+
+var $unmarshalKey = (k, t) => {
+    switch (t.kind) {
+        case $kindString:
+            return $internalize(k, t);
+        case $kindInt64:
+        case $kindUint64:
+            return $unmarshalInt64(k, t, true, undefined);
+    }
+    const range = $integerRanges[t.kind];
+    if (range !== undefined) {
+        const n = Number(k);
+        if (/^-?\d+$/.test(k) && n >= range[0] && n <= range[1]) {
+            return n;
+        }
+        throw new $UnmarshalError("js: cannot unmarshal number " + k + " into Go value of type " + t.string);
+    }
+    throw new $UnmarshalError("js: cannot unmarshal object into Go value of type map[" + t.string + "]");
+};
+
+// $unmarshal converts the JavaScript value x to type t. Structs, arrays,
+// pointers and maps are updated in place if old holds one already, like
+// encoding/json does.
+var $unmarshal = (x, t, string, old, ctx) => {
+    if (t === $jsObjectPtr) {
+        return x;
+    }
+    const unmarshaler = $jsUnmarshaler(t);
+    if (unmarshaler !== undefined && (t.kind !== $kindPtr || (x !== null && x !== undefined))) {
+        const pt = t.kind === $kindPtr ? t : $ptrType(t);
+        const direct = t.kind === $kindPtr || t.kind === $kindStruct || t.kind === $kindArray;
+        let p;
+        if (t.kind === $kindPtr) {
+            p = old !== undefined && old !== t.nil ? old : $newPointer(t);
+        } else {
+            const v = old !== undefined ? old : t.zero();
+            p = direct ? v : $newDataPointer(v, pt);
+        }
+        const err = $callInternalizeJS(p, pt, unmarshaler, x);
+        if (err !== $ifaceNil) {
+            throw new $UnmarshalError(err.Error());
+        }
+        return direct ? p : p.$get();
+    }
+    if (x === null || x === undefined) {
+        switch (t.kind) {
+            case $kindPtr:
+            case $kindSlice:
+            case $kindMap:
+            case $kindFunc:
+            case $kindInterface:
+                return t.zero();
+        }
+        return old === undefined ? t.zero() : old;
+    }
+    switch (t.kind) {
+        case $kindBool:
+            if (typeof x === "boolean" && !string) {
+                return x;
+            }
+            if (string && (x === "true" || x === "false")) {
+                return x === "true";
+            }
+            $unmarshalTypeError(x, t, ctx);
+        case $kindInt:
+        case $kindInt8:
+        case $kindInt16:
+        case $kindInt32:
+        case $kindUint:
+        case $kindUint8:
+        case $kindUint16:
+        case $kindUint32:
+        case $kindUintptr: {
+            const n = $unmarshalNumber(x, string);
+            if (n === undefined) {
+                $unmarshalTypeError(x, t, ctx);
+            }
+            const range = $integerRanges[t.kind];
+            if (!Number.isInteger(n) || n < range[0] || n > range[1]) {
+                throw new $UnmarshalError("js: cannot unmarshal number " + x + " into " + $unmarshalContext(ctx, t));
+            }
+            return n;
+        }
+        case $kindInt64:
+        case $kindUint64:
+            return $unmarshalInt64(x, t, string, ctx);
+        case $kindFloat32:
+        case $kindFloat64: {
+            const n = $unmarshalNumber(x, string);
+            if (n === undefined) {
+                $unmarshalTypeError(x, t, ctx);
+            }
+            return t.kind === $kindFloat32 ? $fround(n) : n;
+        }
+        case $kindString:
+            if (typeof x !== "string") {
+                $unmarshalTypeError(x, t, ctx);
+            }
+            return $internalize(x, t);
+        case $kindArray: {
+            if (!Array.isArray(x) && !ArrayBuffer.isView(x)) {
+                $unmarshalTypeError(x, t, ctx);
+            }
+            const a = old === undefined ? t.zero() : old;
+            for (let i = 0; i < t.len; i++) {
+                a[i] = i < x.length ? $unmarshal(x[i], t.elem, false, a[i], ctx) : t.elem.zero();
+            }
+            return a;
+        }
+        case $kindSlice: {
+            if (t.elem.kind === $kindUint8 && x instanceof ArrayBuffer) {
+                return new t(new Uint8Array(x.slice(0)));
+            }
+            if (!Array.isArray(x) && !ArrayBuffer.isView(x)) {
+                $unmarshalTypeError(x, t, ctx);
+            }
+            const a = new t.nativeArray(x.length);
+            for (let i = 0; i < x.length; i++) {
+                a[i] = $unmarshal(x[i], t.elem, false, undefined, ctx);
+            }
+            return new t(a);
+        }
+        case $kindMap: {
+            if (typeof x !== "object" || Array.isArray(x) || x.constructor === Date) {
+                $unmarshalTypeError(x, t, ctx);
+            }
+            const m = old === undefined || old.keys === undefined ? new Map() : old;
+            for (const k of Object.keys(x)) {
+                const key = $unmarshalKey(k, t.key);
+                m.set(t.key.keyFor(key), { k: key, v: $unmarshal(x[k], t.elem, false, undefined, ctx) });
+            }
+            return m;
+        }
+        case $kindPtr: {
+            if (old !== undefined && old !== t.nil) {
+                if (t.elem.kind === $kindStruct) {
+                    $unmarshal(x, t.elem, string, old, ctx);
+                } else {
+                    old.$set($unmarshal(x, t.elem, string, old.$get(), ctx));
+                }
+                return old;
+            }
+            if (t.elem.kind === $kindStruct) {
+                return $unmarshal(x, t.elem, string, undefined, ctx);
+            }
+            return $newDataPointer($unmarshal(x, t.elem, string, undefined, ctx), t);
+        }
+        case $kindInterface:
+            if (t.methods.length !== 0) {
+                $unmarshalTypeError(x, t, ctx);
+            }
+            return $internalize(x, t);
+        case $kindFunc:
+            if (typeof x !== "function") {
+                $unmarshalTypeError(x, t, ctx);
+            }
+            return $internalize(x, t);
+        case $kindStruct: {
+            if ($isTimeType(t)) {
+                if (!(x instanceof Date)) {
+                    $unmarshalTypeError(x, t, ctx);
+                }
+                const time = $internalize(x, t);
+                if (old === undefined) {
+                    return time;
+                }
+                t.copy(old, time);
+                return old;
+            }
+            if ($hasJsObject(t)) {
+                const o = $internalize(x, t);
+                if (old === undefined) {
+                    return o;
+                }
+                t.copy(old, o);
+                return old;
+            }
+            if (typeof x !== "object" || Array.isArray(x)) {
+                $unmarshalTypeError(x, t, ctx);
+            }
+            const s = old === undefined ? new t.ptr() : old;
+            const fields = $marshalFields(t);
+            const structName = t.string.slice(t.string.lastIndexOf(".") + 1);
+            for (const k of Object.keys(x)) {
+                let f = fields.find(f => f.name === k);
+                if (f === undefined) {
+                    const lower = k.toLowerCase();
+                    f = fields.find(f => f.name.toLowerCase() === lower);
+                    if (f === undefined) {
+                        continue;
+                    }
+                }
+                let holder = s;
+                for (let i = 0; i < f.path.length - 1; i++) {
+                    const e = f.path[i];
+                    if (e.typ.kind === $kindPtr && holder[e.prop] === e.typ.nil) {
+                        if (!e.exported) {
+                            throw new $UnmarshalError("js: cannot set embedded pointer to unexported struct: " + e.typ.string);
+                        }
+                        holder[e.prop] = new e.typ.elem.ptr();
+                    }
+                    holder = holder[e.prop];
+                }
+                const fieldCtx = {
+                    struct: ctx === undefined ? structName : ctx.struct,
+                    fields: (ctx === undefined ? [] : ctx.fields).concat([f.field.name]),
+                };
+                holder[f.field.prop] = $unmarshal(x[k], f.field.typ, f.string, holder[f.field.prop], fieldCtx);
+            }
+            return s;
+        }
+    }
+    throw new $UnmarshalError("js: cannot unmarshal into unsupported " + $unmarshalContext(ctx, t));
+};
+
+// $jsUnmarshal stores x in the value pointed to by the Go pointer in the
+// interface i. It returns the error message, or null on success.
+$global.$jsUnmarshal = (x, i) => {
+    if (i === $ifaceNil || i.constructor.kind !== $kindPtr) {
+        return "js: Unmarshal(non-pointer " + (i === $ifaceNil ? "nil" : i.constructor.string) + ")";
+    }
+    const t = i.constructor;
+    if (i.$val === t.nil) {
+        return "js: Unmarshal(nil " + t.string + ")";
+    }
+    try {
+        $unmarshal(x, t, false, i.$val, undefined);
+    } catch (e) {
+        if (e instanceof $UnmarshalError) {
+            return e.message;
+        }
+        throw e;
+    }
+    return null;
+};
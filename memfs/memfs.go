@@ -0,0 +1,560 @@
+//go:build js
+
+// Package memfs provides an in-memory file system for environments without
+// the Node.js fs module, such as web browsers.
+//
+// Importing the package for its side effects installs the file system:
+//
+//	import _ "github.com/gopherjs/gopherjs/memfs"
+//
+// Afterwards functions of the os package, such as os.Open, os.WriteFile or
+// os.MkdirTemp, operate on files kept in memory. The file system starts with
+// empty / and /tmp directories. Paths must be absolute, because there is no
+// working directory, and symbolic and hard links aren't supported. Standard
+// output and error keep being written to the console.
+//
+// The files are lost when the page is closed, unless Persist is called to keep
+// them in IndexedDB.
+//
+// Under Node.js the real file system is used and importing the package has no
+// effect.
+package memfs
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// Flags of the open() call, which match the constants of the fs stub in the
+// prelude.
+const (
+	oAccMode = 0o3
+	oWronly  = 0o1
+	oRdwr    = 0o2
+	oCreat   = 0o100
+	oExcl    = 0o200
+	oTrunc   = 0o1000
+	oAppend  = 0o2000
+)
+
+// File types in stat() results, which match the syscall package.
+const (
+	sIFMT  = 0o170000
+	sIFDIR = 0o40000
+	sIFREG = 0o100000
+)
+
+// inode is a file or a directory.
+type inode struct {
+	ino     int
+	mode    uint32 // file type and permission bits
+	data    []byte
+	entries map[string]*inode // nil unless it is a directory
+	atime   float64           // in milliseconds since the Unix epoch
+	mtime   float64
+	ctime   float64
+}
+
+func (n *inode) isDir() bool { return n.mode&sIFMT == sIFDIR }
+
+// openFile is a file descriptor.
+type openFile struct {
+	node  *inode
+	flags int
+	pos   int64
+}
+
+var (
+	// installed is true if the file system is in use.
+	installed bool
+	root      *inode
+	files     = map[int]*openFile{}
+	nextFD    = 3 // Descriptors 0-2 are reserved for the standard streams.
+	nextIno   = 1
+	// changed is called after each modification of the file system.
+	changed = func() {}
+)
+
+func init() {
+	jsFS := js.Global.Get("fs")
+	if jsFS == js.Undefined || jsFS.Get("open") != js.Undefined {
+		return // Either no fs stub at all or a real file system.
+	}
+	install(jsFS)
+}
+
+// install adds the file system functions to the fs stub.
+func install(jsFS *js.Object) {
+	root = newInode(sIFDIR | 0o755)
+	files = map[int]*openFile{}
+	root.entries["tmp"] = newInode(sIFDIR | 0o1777)
+
+	// The syscall package keeps a reference to the stub, so it is extended
+	// rather than replaced. Writes to the standard streams keep going to the
+	// stub.
+	consoleWrite := jsFS.Get("write")
+	jsFS.Set("open", open)
+	jsFS.Set("close", closeFD)
+	jsFS.Set("read", read)
+	jsFS.Set("write", func(fd int, buf *js.Object, offset, length int, position *js.Object, callback *js.Object) {
+		if fd == 1 || fd == 2 {
+			consoleWrite.Call("call", jsFS, fd, buf, offset, length, position, callback)
+			return
+		}
+		write(fd, buf, offset, length, position, callback)
+	})
+	jsFS.Set("fstat", fstat)
+	jsFS.Set("stat", stat)
+	jsFS.Set("lstat", stat)
+	jsFS.Set("readdir", readdir)
+	jsFS.Set("mkdir", mkdir)
+	jsFS.Set("rmdir", rmdir)
+	jsFS.Set("unlink", unlink)
+	jsFS.Set("rename", rename)
+	jsFS.Set("truncate", truncate)
+	jsFS.Set("ftruncate", ftruncate)
+	jsFS.Set("chmod", chmod)
+	jsFS.Set("fchmod", fchmod)
+	jsFS.Set("chown", func(path string, uid, gid int, callback *js.Object) { callback.Invoke(nil) })
+	jsFS.Set("fchown", func(fd, uid, gid int, callback *js.Object) { callback.Invoke(nil) })
+	jsFS.Set("lchown", func(path string, uid, gid int, callback *js.Object) { callback.Invoke(nil) })
+	jsFS.Set("utimes", utimes)
+	jsFS.Set("fsync", func(fd int, callback *js.Object) { callback.Invoke(nil) })
+	installed = true
+}
+
+// Installed reports whether the in-memory file system is in use.
+func Installed() bool {
+	return installed
+}
+
+func now() float64 {
+	return float64(time.Now().UnixNano()) / 1e6
+}
+
+func newInode(mode uint32) *inode {
+	t := now()
+	n := &inode{ino: nextIno, mode: mode, atime: t, mtime: t, ctime: t}
+	nextIno++
+	if n.isDir() {
+		n.entries = map[string]*inode{}
+	}
+	return n
+}
+
+// fsError returns an error like the ones Node.js reports, which the syscall
+// package maps to Errno by its code.
+func fsError(code string) *js.Object {
+	err := js.Global.Get("Error").New(code)
+	err.Set("code", code)
+	return err
+}
+
+// lookupParent resolves all elements of a path but the last one, returning
+// the directory and the last element.
+func lookupParent(p string) (dir *inode, name string, errCode string) {
+	elems := strings.Split(path.Clean("/"+p), "/")[1:]
+	dir = root
+	for _, elem := range elems[:len(elems)-1] {
+		next, ok := dir.entries[elem]
+		switch {
+		case !ok:
+			return nil, "", "ENOENT"
+		case !next.isDir():
+			return nil, "", "ENOTDIR"
+		}
+		dir = next
+	}
+	return dir, elems[len(elems)-1], ""
+}
+
+// lookup resolves a path.
+func lookup(p string) (*inode, string) {
+	dir, name, code := lookupParent(p)
+	switch {
+	case code != "":
+		return nil, code
+	case name == "":
+		return root, ""
+	}
+	n, ok := dir.entries[name]
+	if !ok {
+		return nil, "ENOENT"
+	}
+	return n, ""
+}
+
+func open(p string, flags int, mode uint32, callback *js.Object) {
+	dir, name, code := lookupParent(p)
+	if code != "" {
+		callback.Invoke(fsError(code))
+		return
+	}
+	n, ok := root, true
+	if name != "" {
+		n, ok = dir.entries[name]
+	}
+	switch {
+	case ok && flags&oCreat != 0 && flags&oExcl != 0:
+		callback.Invoke(fsError("EEXIST"))
+		return
+	case !ok && flags&oCreat == 0:
+		callback.Invoke(fsError("ENOENT"))
+		return
+	case !ok:
+		n = newInode(sIFREG | mode&0o7777)
+		dir.entries[name] = n
+		dir.mtime = n.mtime
+		changed()
+	case n.isDir() && flags&oAccMode != 0:
+		callback.Invoke(fsError("EISDIR"))
+		return
+	}
+	if flags&oTrunc != 0 && flags&oAccMode != 0 && len(n.data) > 0 {
+		n.data = nil
+		n.mtime = now()
+		changed()
+	}
+	fd := nextFD
+	nextFD++
+	files[fd] = &openFile{node: n, flags: flags}
+	callback.Invoke(nil, fd)
+}
+
+func closeFD(fd int, callback *js.Object) {
+	if _, ok := files[fd]; !ok {
+		callback.Invoke(fsError("EBADF"))
+		return
+	}
+	delete(files, fd)
+	callback.Invoke(nil)
+}
+
+func read(fd int, buf *js.Object, offset, length int, position *js.Object, callback *js.Object) {
+	f, ok := files[fd]
+	switch {
+	case !ok || f.flags&oAccMode == oWronly:
+		callback.Invoke(fsError("EBADF"))
+		return
+	case f.node.isDir():
+		callback.Invoke(fsError("EISDIR"))
+		return
+	}
+	pos := f.pos
+	if position != nil {
+		pos = position.Int64()
+	}
+	var n int
+	if pos < int64(len(f.node.data)) {
+		chunk := f.node.data[pos:]
+		if len(chunk) > length {
+			chunk = chunk[:length]
+		}
+		buf.Call("set", chunk, offset)
+		n = len(chunk)
+	}
+	if position == nil {
+		f.pos += int64(n)
+	}
+	f.node.atime = now()
+	callback.Invoke(nil, n)
+}
+
+func write(fd int, buf *js.Object, offset, length int, position *js.Object, callback *js.Object) {
+	f, ok := files[fd]
+	if !ok || f.flags&oAccMode == 0 {
+		callback.Invoke(fsError("EBADF"))
+		return
+	}
+	data := js.Global.Get("Uint8Array").New(buf.Get("buffer"), buf.Get("byteOffset").Int()+offset, length).Interface().([]byte)
+	pos := f.pos
+	switch {
+	case position != nil:
+		pos = position.Int64()
+	case f.flags&oAppend != 0:
+		pos = int64(len(f.node.data))
+	}
+	n := f.node
+	if end := pos + int64(len(data)); end > int64(len(n.data)) {
+		n.data = append(n.data, make([]byte, int(end)-len(n.data))...)
+	}
+	copy(n.data[pos:], data)
+	if position == nil {
+		f.pos = pos + int64(len(data))
+	}
+	n.mtime = now()
+	changed()
+	callback.Invoke(nil, len(data))
+}
+
+// statObject returns an object like fs.Stats of Node.js.
+func statObject(n *inode) *js.Object {
+	size := len(n.data)
+	if n.isDir() {
+		size = 4096
+	}
+	return js.Global.Get("Object").Call("assign", js.Global.Get("Object").New(), js.M{
+		"dev":         0,
+		"ino":         n.ino,
+		"mode":        n.mode,
+		"nlink":       1,
+		"uid":         0,
+		"gid":         0,
+		"rdev":        0,
+		"size":        size,
+		"blksize":     4096,
+		"blocks":      (size + 511) / 512,
+		"atimeMs":     n.atime,
+		"mtimeMs":     n.mtime,
+		"ctimeMs":     n.ctime,
+		"isDirectory": func() bool { return n.isDir() },
+	})
+}
+
+func fstat(fd int, callback *js.Object) {
+	f, ok := files[fd]
+	if !ok {
+		callback.Invoke(fsError("EBADF"))
+		return
+	}
+	callback.Invoke(nil, statObject(f.node))
+}
+
+func stat(p string, callback *js.Object) {
+	n, code := lookup(p)
+	if code != "" {
+		callback.Invoke(fsError(code))
+		return
+	}
+	callback.Invoke(nil, statObject(n))
+}
+
+func readdir(p string, callback *js.Object) {
+	n, code := lookup(p)
+	switch {
+	case code != "":
+		callback.Invoke(fsError(code))
+		return
+	case !n.isDir():
+		callback.Invoke(fsError("ENOTDIR"))
+		return
+	}
+	names := make([]string, 0, len(n.entries))
+	for name := range n.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	callback.Invoke(nil, names)
+}
+
+func mkdir(p string, perm uint32, callback *js.Object) {
+	dir, name, code := lookupParent(p)
+	switch {
+	case code != "":
+		callback.Invoke(fsError(code))
+		return
+	case name == "":
+		callback.Invoke(fsError("EEXIST"))
+		return
+	}
+	if _, ok := dir.entries[name]; ok {
+		callback.Invoke(fsError("EEXIST"))
+		return
+	}
+	n := newInode(sIFDIR | perm&0o7777)
+	dir.entries[name] = n
+	dir.mtime = n.mtime
+	changed()
+	callback.Invoke(nil)
+}
+
+// remove removes a directory entry, which must be a directory if dir is true
+// and must not otherwise.
+func remove(p string, dir bool) *js.Object {
+	parent, name, code := lookupParent(p)
+	switch {
+	case code != "":
+		return fsError(code)
+	case name == "":
+		return fsError("EBUSY")
+	}
+	n, ok := parent.entries[name]
+	switch {
+	case !ok:
+		return fsError("ENOENT")
+	case dir && !n.isDir():
+		return fsError("ENOTDIR")
+	case !dir && n.isDir():
+		return fsError("EISDIR")
+	case dir && len(n.entries) > 0:
+		return fsError("ENOTEMPTY")
+	}
+	delete(parent.entries, name)
+	parent.mtime = now()
+	changed()
+	return nil
+}
+
+func rmdir(p string, callback *js.Object) {
+	callback.Invoke(remove(p, true))
+}
+
+func unlink(p string, callback *js.Object) {
+	callback.Invoke(remove(p, false))
+}
+
+func rename(from, to string, callback *js.Object) {
+	fromDir, fromName, code := lookupParent(from)
+	if code == "" && fromName == "" {
+		code = "EBUSY"
+	}
+	if code != "" {
+		callback.Invoke(fsError(code))
+		return
+	}
+	n, ok := fromDir.entries[fromName]
+	if !ok {
+		callback.Invoke(fsError("ENOENT"))
+		return
+	}
+	toDir, toName, code := lookupParent(to)
+	if code == "" && toName == "" {
+		code = "EBUSY"
+	}
+	if code != "" {
+		callback.Invoke(fsError(code))
+		return
+	}
+	// A directory can't be moved into itself.
+	fromPath, toPath := path.Clean("/"+from), path.Clean("/"+to)
+	if n.isDir() && strings.HasPrefix(toPath+"/", fromPath+"/") && toPath != fromPath {
+		callback.Invoke(fsError("EINVAL"))
+		return
+	}
+	if old, ok := toDir.entries[toName]; ok && old != n {
+		switch {
+		case n.isDir() && !old.isDir():
+			callback.Invoke(fsError("ENOTDIR"))
+			return
+		case !n.isDir() && old.isDir():
+			callback.Invoke(fsError("EISDIR"))
+			return
+		case old.isDir() && len(old.entries) > 0:
+			callback.Invoke(fsError("ENOTEMPTY"))
+			return
+		}
+	}
+	delete(fromDir.entries, fromName)
+	toDir.entries[toName] = n
+	t := now()
+	fromDir.mtime, toDir.mtime, n.ctime = t, t, t
+	changed()
+	callback.Invoke(nil)
+}
+
+// resize changes the size of a file.
+func resize(n *inode, length int64) *js.Object {
+	switch {
+	case n.isDir():
+		return fsError("EISDIR")
+	case length < 0:
+		return fsError("EINVAL")
+	case length <= int64(len(n.data)):
+		n.data = n.data[:length]
+	default:
+		n.data = append(n.data, make([]byte, int(length)-len(n.data))...)
+	}
+	n.mtime = now()
+	changed()
+	return nil
+}
+
+func truncate(p string, length int64, callback *js.Object) {
+	n, code := lookup(p)
+	if code != "" {
+		callback.Invoke(fsError(code))
+		return
+	}
+	callback.Invoke(resize(n, length))
+}
+
+func ftruncate(fd int, length int64, callback *js.Object) {
+	f, ok := files[fd]
+	if !ok || f.flags&oAccMode == 0 {
+		callback.Invoke(fsError("EBADF"))
+		return
+	}
+	callback.Invoke(resize(f.node, length))
+}
+
+func chmod(p string, mode uint32, callback *js.Object) {
+	n, code := lookup(p)
+	if code != "" {
+		callback.Invoke(fsError(code))
+		return
+	}
+	n.mode = n.mode&sIFMT | mode&0o7777
+	n.ctime = now()
+	changed()
+	callback.Invoke(nil)
+}
+
+func fchmod(fd int, mode uint32, callback *js.Object) {
+	f, ok := files[fd]
+	if !ok {
+		callback.Invoke(fsError("EBADF"))
+		return
+	}
+	f.node.mode = f.node.mode&sIFMT | mode&0o7777
+	f.node.ctime = now()
+	changed()
+	callback.Invoke(nil)
+}
+
+func utimes(p string, atime, mtime float64, callback *js.Object) {
+	n, code := lookup(p)
+	if code != "" {
+		callback.Invoke(fsError(code))
+		return
+	}
+	n.atime, n.mtime = atime*1000, mtime*1000
+	changed()
+	callback.Invoke(nil)
+}
+
+// CopyFS copies the file system fsys into the directory dir, creating it if
+// necessary, which makes it possible to seed the file system with files
+// embedded into the program:
+//
+//	//go:embed templates
+//	var templates embed.FS
+//
+//	func init() {
+//		if err := memfs.CopyFS("/app", templates); err != nil {
+//			panic(err)
+//		}
+//	}
+//
+// Existing files are overwritten.
+func CopyFS(dir string, fsys fs.FS) error {
+	return fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		target := path.Join(dir, p)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0o644)
+	})
+}
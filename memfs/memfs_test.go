@@ -0,0 +1,204 @@
+//go:build js
+
+package memfs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// newFS installs the file system onto a fresh object, like the one the
+// prelude provides in browsers.
+func newFS(t *testing.T) *js.Object {
+	t.Helper()
+	jsFS := js.Global.Get("Object").New()
+	install(jsFS)
+	t.Cleanup(func() {
+		installed = false
+		changed = func() {}
+	})
+	return jsFS
+}
+
+// fsCall calls a function of the file system, returning its result and the
+// code of the error it reports, if any.
+func fsCall(jsFS *js.Object, name string, args ...interface{}) (result *js.Object, code string) {
+	args = append(args, func(err, res *js.Object) {
+		if err != nil && err != js.Undefined {
+			code = err.Get("code").String()
+		}
+		result = res
+	})
+	jsFS.Call(name, args...)
+	return result, code
+}
+
+func mustCall(t *testing.T, jsFS *js.Object, name string, args ...interface{}) *js.Object {
+	t.Helper()
+	result, code := fsCall(jsFS, name, args...)
+	if code != "" {
+		t.Fatalf("%s(%v) returned error %s.", name, args, code)
+	}
+	return result
+}
+
+func readFile(t *testing.T, jsFS *js.Object, path string) string {
+	t.Helper()
+	fd := mustCall(t, jsFS, "open", path, 0, 0).Int()
+	defer mustCall(t, jsFS, "close", fd)
+	buf := js.Global.Get("Uint8Array").New(64)
+	n := mustCall(t, jsFS, "read", fd, buf, 0, 64, nil).Int()
+	return string(buf.Call("subarray", 0, n).Interface().([]byte))
+}
+
+func TestReadWrite(t *testing.T) {
+	jsFS := newFS(t)
+	fd := mustCall(t, jsFS, "open", "/tmp/file", oWronly|oCreat|oExcl, 0o600).Int()
+	for _, s := range []string{"hello", ", world"} {
+		if n := mustCall(t, jsFS, "write", fd, []byte(s), 0, len(s), nil).Int(); n != len(s) {
+			t.Errorf("Got %d bytes written. Want: %d.", n, len(s))
+		}
+	}
+	if _, code := fsCall(jsFS, "read", fd, js.Global.Get("Uint8Array").New(1), 0, 1, nil); code != "EBADF" {
+		t.Errorf("Got read() from a write-only descriptor error %q. Want: EBADF.", code)
+	}
+	mustCall(t, jsFS, "close", fd)
+
+	if got, want := readFile(t, jsFS, "/tmp/file"), "hello, world"; got != want {
+		t.Errorf("Got file contents %q. Want: %q.", got, want)
+	}
+	stat := mustCall(t, jsFS, "stat", "/tmp/file")
+	if got, want := stat.Get("mode").Int(), sIFREG|0o600; got != want {
+		t.Errorf("Got mode %o. Want: %o.", got, want)
+	}
+	if got := stat.Get("size").Int(); got != 12 {
+		t.Errorf("Got size %d. Want: 12.", got)
+	}
+	if _, code := fsCall(jsFS, "open", "/tmp/file", oWronly|oCreat|oExcl, 0o600); code != "EEXIST" {
+		t.Errorf("Got exclusive open() error %q. Want: EEXIST.", code)
+	}
+
+	fd = mustCall(t, jsFS, "open", "/tmp/file", oWronly|oAppend, 0).Int()
+	mustCall(t, jsFS, "write", fd, []byte("!"), 0, 1, nil)
+	mustCall(t, jsFS, "ftruncate", fd, 6)
+	mustCall(t, jsFS, "write", fd, []byte("!"), 0, 1, nil)
+	mustCall(t, jsFS, "close", fd)
+	if got, want := readFile(t, jsFS, "/tmp/file"), "hello,!"; got != want {
+		t.Errorf("Got file contents %q. Want: %q.", got, want)
+	}
+}
+
+func TestDirectories(t *testing.T) {
+	jsFS := newFS(t)
+	mustCall(t, jsFS, "mkdir", "/a", 0o755)
+	mustCall(t, jsFS, "mkdir", "/a/b", 0o755)
+	mustCall(t, jsFS, "close", mustCall(t, jsFS, "open", "/a/c", oCreat|oWronly, 0o644))
+
+	tests := []struct {
+		name string
+		args []interface{}
+		want string
+	}{
+		{"mkdir", []interface{}{"/a", 0o755}, "EEXIST"},
+		{"mkdir", []interface{}{"/x/y", 0o755}, "ENOENT"},
+		{"mkdir", []interface{}{"/a/c/d", 0o755}, "ENOTDIR"},
+		{"open", []interface{}{"/a", oWronly, 0}, "EISDIR"},
+		{"rmdir", []interface{}{"/a"}, "ENOTEMPTY"},
+		{"rmdir", []interface{}{"/a/c"}, "ENOTDIR"},
+		{"unlink", []interface{}{"/a/b"}, "EISDIR"},
+		{"rename", []interface{}{"/a", "/a/b/a"}, "EINVAL"},
+		{"rename", []interface{}{"/a/c", "/a/b"}, "EISDIR"},
+		{"stat", []interface{}{"/a/missing"}, "ENOENT"},
+	}
+	for _, test := range tests {
+		if _, code := fsCall(jsFS, test.name, test.args...); code != test.want {
+			t.Errorf("Got %s(%v) error %q. Want: %q.", test.name, test.args, code, test.want)
+		}
+	}
+
+	mustCall(t, jsFS, "rename", "/a/c", "/a/b/c")
+	mustCall(t, jsFS, "rename", "/a/b", "/d")
+	if got := mustCall(t, jsFS, "readdir", "/").Interface(); len(got.([]interface{})) != 3 {
+		t.Errorf("Got / entries %v. Want: [a d tmp].", got)
+	}
+	if got := mustCall(t, jsFS, "readdir", "/d").Interface(); len(got.([]interface{})) != 1 {
+		t.Errorf("Got /d entries %v. Want: [c].", got)
+	}
+	mustCall(t, jsFS, "unlink", "/d/c")
+	mustCall(t, jsFS, "rmdir", "/d")
+	if _, code := fsCall(jsFS, "stat", "/d"); code != "ENOENT" {
+		t.Errorf("Got stat() error %q for a removed directory. Want: ENOENT.", code)
+	}
+}
+
+// fakeIndexedDB implements the parts of the IndexedDB API used by Persist,
+// keeping the stores in the data property.
+const fakeIndexedDB = `({
+	data: {},
+	open: function(name) {
+		var data = this.data;
+		var req = {};
+		var db = {
+			createObjectStore: function(store) { data[store] = {}; },
+			transaction: function(store) {
+				return {objectStore: function() {
+					return {
+						get: function(key) { return respond(data[store][key]); },
+						put: function(value, key) { data[store][key] = value; return respond(key); },
+					};
+				}};
+			},
+		};
+		setTimeout(function() {
+			req.result = db;
+			if (!(name in data) && req.onupgradeneeded) {
+				data[name] = true;
+				req.onupgradeneeded();
+			}
+			req.onsuccess();
+		});
+		return req;
+	},
+});
+
+function respond(result) {
+	var req = {};
+	setTimeout(function() {
+		req.result = result;
+		if (req.onsuccess) req.onsuccess();
+	});
+	return req;
+}`
+
+func TestPersist(t *testing.T) {
+	if err := Persist("test"); err != errNotInstalled {
+		t.Fatalf("Got Persist() error %v without the file system installed. Want: %v.", err, errNotInstalled)
+	}
+	jsFS := newFS(t)
+	idb := js.Global.Call("eval", fakeIndexedDB)
+	js.Global.Set("indexedDB", idb)
+	defer js.Global.Delete("indexedDB")
+
+	if err := Persist("test"); err != nil {
+		t.Fatalf("Persist() returned error: %s", err)
+	}
+	mustCall(t, jsFS, "mkdir", "/data", 0o700)
+	fd := mustCall(t, jsFS, "open", "/data/file", oWronly|oCreat, 0o600)
+	mustCall(t, jsFS, "write", fd, []byte("saved"), 0, 5, nil)
+	mustCall(t, jsFS, "close", fd)
+	time.Sleep(2 * saveDelay * time.Millisecond)
+
+	// Loading the snapshot into a fresh file system restores the files.
+	jsFS = newFS(t)
+	if err := Persist("test"); err != nil {
+		t.Fatalf("Persist() returned error: %s", err)
+	}
+	if got, want := mustCall(t, jsFS, "stat", "/data").Get("mode").Int(), sIFDIR|0o700; got != want {
+		t.Errorf("Got restored directory mode %o. Want: %o.", got, want)
+	}
+	if got, want := readFile(t, jsFS, "/data/file"), "saved"; got != want {
+		t.Errorf("Got restored file contents %q. Want: %q.", got, want)
+	}
+}
@@ -0,0 +1,145 @@
+//go:build js
+
+package memfs
+
+import (
+	"errors"
+	"path"
+	"strings"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// saveDelay is the number of milliseconds modifications are collected for
+// before the file system is saved.
+const saveDelay = 100
+
+// storeName is the name of the IndexedDB object store, which holds a snapshot
+// of the whole file system under the snapshotKey key.
+const (
+	storeName   = "files"
+	snapshotKey = "snapshot"
+)
+
+var errNotInstalled = errors.New("memfs: the in-memory file system isn't in use")
+
+// Persist keeps the file system in the IndexedDB database with the given name.
+// Files saved by previous runs of the program are loaded, replacing the ones
+// at the same paths, and modifications are saved shortly after being made.
+//
+// Persist blocks until the files are loaded, so it must not be called from
+// functions invoked by JavaScript.
+func Persist(name string) error {
+	if !installed {
+		return errNotInstalled
+	}
+	idb := js.Global.Get("indexedDB")
+	if idb == js.Undefined {
+		return errors.New("memfs: IndexedDB is unavailable")
+	}
+
+	req := idb.Call("open", name, 1)
+	req.Set("onupgradeneeded", func() {
+		req.Get("result").Call("createObjectStore", storeName)
+	})
+	db, err := await(req)
+	if err != nil {
+		return err
+	}
+	snapshot, err := await(db.Call("transaction", storeName).Call("objectStore", storeName).Call("get", snapshotKey))
+	if err != nil {
+		return err
+	}
+	if snapshot != js.Undefined && snapshot != nil {
+		restore(snapshot)
+	}
+
+	scheduled := false
+	changed = func() {
+		if scheduled {
+			return
+		}
+		scheduled = true
+		js.Global.Call("setTimeout", func() {
+			scheduled = false
+			db.Call("transaction", storeName, "readwrite").Call("objectStore", storeName).Call("put", takeSnapshot(), snapshotKey)
+		}, saveDelay)
+	}
+	changed()
+	return nil
+}
+
+// await waits for an IndexedDB request to complete, returning its result.
+func await(req *js.Object) (*js.Object, error) {
+	done := make(chan error, 1)
+	req.Set("onsuccess", func() { done <- nil })
+	req.Set("onerror", func() {
+		done <- errors.New("memfs: IndexedDB request failed: " + req.Get("error").Get("message").String())
+	})
+	if err := <-done; err != nil {
+		return nil, err
+	}
+	return req.Get("result"), nil
+}
+
+// takeSnapshot returns the records of all files and directories, each of
+// which follows its parent directory.
+func takeSnapshot() *js.Object {
+	records := js.Global.Get("Array").New()
+	var walk func(p string, n *inode)
+	walk = func(p string, n *inode) {
+		record := js.M{
+			"path":  p,
+			"mode":  n.mode,
+			"atime": n.atime,
+			"mtime": n.mtime,
+			"ctime": n.ctime,
+		}
+		if !n.isDir() {
+			record["data"] = n.data
+		}
+		records.Call("push", record)
+		for name, child := range n.entries {
+			walk(path.Join(p, name), child)
+		}
+	}
+	walk("/", root)
+	return records
+}
+
+// restore adds the files and directories from a snapshot to the file system.
+func restore(snapshot *js.Object) {
+	for i := 0; i < snapshot.Length(); i++ {
+		record := snapshot.Index(i)
+		p := record.Get("path").String()
+		mode := uint32(record.Get("mode").Int())
+
+		n := root
+		if p != "/" {
+			dir := root
+			elems := strings.Split(p, "/")[1:]
+			for _, elem := range elems[:len(elems)-1] {
+				next, ok := dir.entries[elem]
+				if !ok || !next.isDir() {
+					next = newInode(sIFDIR | 0o755)
+					dir.entries[elem] = next
+				}
+				dir = next
+			}
+			name := elems[len(elems)-1]
+			old, ok := dir.entries[name]
+			if !ok || old.isDir() != (mode&sIFMT == sIFDIR) {
+				old = newInode(mode & sIFMT)
+				dir.entries[name] = old
+			}
+			n = old
+		}
+		n.mode = mode
+		n.atime = record.Get("atime").Float()
+		n.mtime = record.Get("mtime").Float()
+		n.ctime = record.Get("ctime").Float()
+		if data := record.Get("data"); data != js.Undefined {
+			n.data = append([]byte(nil), js.Global.Get("Uint8Array").New(data).Interface().([]byte)...)
+		}
+	}
+}
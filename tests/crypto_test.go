@@ -0,0 +1,74 @@
+//go:build js && gopherjs
+
+package tests
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding"
+	"hash"
+	"testing"
+)
+
+func TestCryptoHashLargeWrites(t *testing.T) {
+	payload := make([]byte, 1<<20)
+	for i := range payload {
+		payload[i] = byte(i * 7)
+	}
+	for name, newHash := range map[string]func() hash.Hash{
+		"sha1":   sha1.New,
+		"sha256": sha256.New,
+		"sha384": sha512.New384,
+		"sha512": sha512.New,
+	} {
+		t.Run(name, func(t *testing.T) {
+			h := newHash()
+			h.Write(payload[:100])
+			state, err := h.(encoding.BinaryMarshaler).MarshalBinary()
+			if err != nil {
+				t.Fatalf("MarshalBinary() returned error: %s", err)
+			}
+			// The unmarshaled hash continues with the Go implementation.
+			goHash := newHash()
+			if err := goHash.(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err != nil {
+				t.Fatalf("UnmarshalBinary() returned error: %s", err)
+			}
+			h.Write(payload)
+			goHash.Write(payload)
+			if got, want := h.Sum(nil), goHash.Sum(nil); !bytes.Equal(got, want) {
+				t.Errorf("Got sum %x. Want: %x.", got, want)
+			}
+
+			// Large streams are still hashed by Node.js crypto, but their
+			// state is too long to replay when marshaling.
+			if nh, ok := h.(interface{ UsesNodeCrypto() bool }); ok && !nh.UsesNodeCrypto() {
+				t.Errorf("Hash doesn't use Node.js crypto after a large write.")
+			}
+			if _, err := h.(encoding.BinaryMarshaler).MarshalBinary(); err == nil {
+				t.Errorf("MarshalBinary() succeeded after a large write. Want: error.")
+			}
+			h.Reset()
+			h.Write(payload[:100])
+			if _, err := h.(encoding.BinaryMarshaler).MarshalBinary(); err != nil {
+				t.Errorf("MarshalBinary() returned error %s after Reset.", err)
+			}
+		})
+	}
+}
+
+func TestCryptoHMACReset(t *testing.T) {
+	payload := bytes.Repeat([]byte("gopherjs"), 1<<15)
+	mac := hmac.New(sha256.New, []byte("key"))
+	mac.Write(payload)
+	want := mac.Sum(nil)
+	for i := 0; i < 2; i++ {
+		mac.Reset()
+		mac.Write(payload)
+		if got := mac.Sum(nil); !bytes.Equal(got, want) {
+			t.Errorf("Got HMAC %x after Reset. Want: %x.", got, want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+//go:build js && gopherjs
+
+package tests
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// helperCommand returns a command, which runs the test binary itself as
+// TestExecHelper with the given mode.
+func helperCommand(t *testing.T, mode string) *exec.Cmd {
+	t.Helper()
+	if js.Global.Get("require") == js.Undefined {
+		t.Skip("Processes can only be started under Node.js.")
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatalf("os.Executable() returned error: %s", err)
+	}
+	cmd := exec.Command(exe, os.Args[0], "-test.run=^TestExecHelper$")
+	cmd.Env = append(os.Environ(), "GOPHERJS_EXEC_HELPER="+mode)
+	return cmd
+}
+
+// TestExecHelper isn't a real test, it is run by other tests in a subprocess.
+func TestExecHelper(t *testing.T) {
+	switch os.Getenv("GOPHERJS_EXEC_HELPER") {
+	case "":
+		t.Skip("Only run as a subprocess.")
+	case "upper":
+		wd, _ := os.Getwd()
+		os.Stderr.WriteString(wd)
+		sc := bufio.NewScanner(os.Stdin)
+		for sc.Scan() {
+			os.Stdout.WriteString(strings.ToUpper(sc.Text()) + "\n")
+		}
+		os.Exit(3)
+	case "block":
+		io.Copy(io.Discard, os.Stdin)
+	case "zones":
+		for _, month := range []time.Month{time.January, time.July} {
+			os.Stdout.WriteString(time.Date(2024, month, 1, 12, 0, 0, 0, time.Local).Format("MST -0700\n"))
+		}
+	}
+	os.Exit(0)
+}
+
+func TestExecutable(t *testing.T) {
+	process := js.Global.Get("process")
+	if process == js.Undefined {
+		t.Skip("os.Executable() requires Node.js.")
+	}
+	exe, err := os.Executable()
+	if want := process.Get("execPath").String(); err != nil || exe != want {
+		t.Errorf("Got os.Executable() %q, %v. Want: %q, nil.", exe, err, want)
+	}
+	if want := process.Get("argv").Index(1).String(); os.Args[0] != want {
+		t.Errorf("Got os.Args[0] %q. Want the script path %q.", os.Args[0], want)
+	}
+}
+
+func TestExecOutput(t *testing.T) {
+	cmd := helperCommand(t, "upper")
+	cmd.Dir = t.TempDir()
+	cmd.Stdin = strings.NewReader("one\ntwo\n")
+	stderr := &strings.Builder{}
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Errorf("Got Output() error %v. Want: exit status 3.", err)
+	}
+	if want := "ONE\nTWO\n"; string(out) != want {
+		t.Errorf("Got output %q. Want: %q.", out, want)
+	}
+	if stderr.String() != cmd.Dir {
+		t.Errorf("Got working directory %q. Want: %q.", stderr, cmd.Dir)
+	}
+}
+
+func TestExecPipes(t *testing.T) {
+	cmd := helperCommand(t, "upper")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatalf("StdinPipe() returned error: %s", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatalf("StdoutPipe() returned error: %s", err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Start() returned error: %s", err)
+	}
+
+	// Each line must be echoed before the next one is sent.
+	r := bufio.NewReader(stdout)
+	for _, line := range []string{"one", "two", "three"} {
+		io.WriteString(stdin, line+"\n")
+		got, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Reading output line returned error: %s", err)
+		}
+		if want := strings.ToUpper(line) + "\n"; got != want {
+			t.Errorf("Got output line %q. Want: %q.", got, want)
+		}
+	}
+	stdin.Close()
+	if err := cmd.Wait(); cmd.ProcessState.ExitCode() != 3 {
+		t.Errorf("Got Wait() error %v. Want: exit status 3.", err)
+	}
+}
+
+func TestExecKill(t *testing.T) {
+	cmd := helperCommand(t, "block")
+	if _, err := cmd.StdinPipe(); err != nil {
+		t.Fatalf("StdinPipe() returned error: %s", err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Start() returned error: %s", err)
+	}
+	if err := cmd.Process.Kill(); err != nil {
+		t.Fatalf("Kill() returned error: %s", err)
+	}
+	err := cmd.Wait()
+	ws, ok := cmd.ProcessState.Sys().(syscall.WaitStatus)
+	if !ok || !ws.Signaled() || ws.Signal() != syscall.SIGKILL {
+		t.Errorf("Got Wait() error %v. Want: signal: killed.", err)
+	}
+}
+
+func TestExecNotFound(t *testing.T) {
+	if js.Global.Get("require") == js.Undefined {
+		t.Skip("Processes can only be started under Node.js.")
+	}
+	if _, err := exec.LookPath("gopherjs-nonexistent-command"); !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("Got LookPath() error %v. Want: %v.", err, exec.ErrNotFound)
+	}
+	err := exec.Command("/gopherjs/nonexistent/command").Run()
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Got Run() error %v. Want: %v.", err, os.ErrNotExist)
+	}
+}
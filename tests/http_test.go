@@ -0,0 +1,455 @@
+//go:build js && gopherjs
+
+package tests
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+func TestHTTPServerRequest(t *testing.T) {
+	skipUnlessNodeNet(t)
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("Reading request body returned error: %s", err)
+		}
+		w.Header().Set("X-Echo", r.Header.Get("X-Test"))
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, r.Method+" "+r.URL.String()+" "+r.Host+" "+string(body))
+	}))
+	defer s.Close()
+
+	req, _ := http.NewRequest("POST", s.URL+"/path?q=1", strings.NewReader("hello"))
+	req.Header.Set("X-Test", "value")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request returned error: %s", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Reading response body returned error: %s", err)
+	}
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("Got status %d. Want: %d.", resp.StatusCode, http.StatusCreated)
+	}
+	if got := resp.Header.Get("X-Echo"); got != "value" {
+		t.Errorf("Got X-Echo header %q. Want: %q.", got, "value")
+	}
+	if got := resp.Header.Get("Content-Type"); got != "text/plain; charset=utf-8" {
+		t.Errorf("Got sniffed Content-Type %q. Want: %q.", got, "text/plain; charset=utf-8")
+	}
+	want := "POST /path?q=1 " + s.Listener.Addr().String() + " hello"
+	if string(body) != want {
+		t.Errorf("Got response body %q. Want: %q.", body, want)
+	}
+	if resp.ContentLength != int64(len(want)) {
+		t.Errorf("Got Content-Length %d. Want: %d.", resp.ContentLength, len(want))
+	}
+}
+
+// testHTTPStreaming checks that client streams the request body to the server
+// and the server streams the response body back. Each line is only sent once
+// the previous one has arrived on the other side, so a buffered body would
+// make the test hang.
+//
+// Like with the gc toolchain, an HTTP/1 handler must read the request body
+// before it starts to write the response. The response is returned with its
+// body consumed.
+func testHTTPStreaming(t *testing.T, client *http.Client) *http.Response {
+	t.Helper()
+
+	lines := []string{"one", "two", "three"}
+	received := make(chan string)
+	next := make(chan struct{})
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sc := bufio.NewScanner(r.Body)
+		for sc.Scan() {
+			received <- sc.Text()
+		}
+		for _, line := range lines {
+			io.WriteString(w, strings.ToUpper(line)+"\n")
+			w.(http.Flusher).Flush()
+			<-next
+		}
+	}))
+	defer s.Close()
+
+	pr, pw := io.Pipe()
+	req, _ := http.NewRequest("POST", s.URL, pr)
+	type result struct {
+		resp *http.Response
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		resp, err := client.Do(req)
+		done <- result{resp, err}
+	}()
+
+	for _, line := range lines {
+		io.WriteString(pw, line+"\n")
+		if got := <-received; got != line {
+			t.Errorf("Got request line %q. Want: %q.", got, line)
+		}
+	}
+	pw.Close()
+
+	res := <-done
+	if res.err != nil {
+		t.Fatalf("Request returned error: %s", res.err)
+	}
+	defer res.resp.Body.Close()
+
+	r := bufio.NewReader(res.resp.Body)
+	for _, line := range lines {
+		got, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Reading response line returned error: %s", err)
+		}
+		if want := strings.ToUpper(line) + "\n"; got != want {
+			t.Errorf("Got response line %q. Want: %q.", got, want)
+		}
+		next <- struct{}{}
+	}
+	if rest, err := io.ReadAll(r); err != nil || len(rest) != 0 {
+		t.Errorf("Got the rest of response %q, %v. Want: \"\", nil.", rest, err)
+	}
+	return res.resp
+}
+
+func TestHTTPServerStreaming(t *testing.T) {
+	skipUnlessNodeNet(t)
+
+	// Setting DialContext makes the transport send requests over net.Conn
+	// instead of the Fetch API.
+	resp := testHTTPStreaming(t, &http.Client{Transport: &http.Transport{DialContext: (&net.Dialer{}).DialContext}})
+	if len(resp.TransferEncoding) != 1 || resp.TransferEncoding[0] != "chunked" {
+		t.Errorf("Got TransferEncoding %v. Want: [chunked].", resp.TransferEncoding)
+	}
+}
+
+func TestHTTPServerConcurrentHandlers(t *testing.T) {
+	skipUnlessNodeNet(t)
+
+	// Each handler runs in its own goroutine, so the first one can wait for
+	// the second one.
+	second := make(chan struct{})
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/first":
+			<-second
+		case "/second":
+			close(second)
+		}
+	}))
+	defer s.Close()
+
+	done := make(chan error)
+	go func() {
+		resp, err := http.Get(s.URL + "/first")
+		if err == nil {
+			resp.Body.Close()
+		}
+		done <- err
+	}()
+	resp, err := http.Get(s.URL + "/second")
+	if err != nil {
+		t.Fatalf("Second request returned error: %s", err)
+	}
+	resp.Body.Close()
+	if err := <-done; err != nil {
+		t.Fatalf("First request returned error: %s", err)
+	}
+}
+
+func TestHTTPServerShutdown(t *testing.T) {
+	skipUnlessNodeNet(t)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() returned error: %s", err)
+	}
+	states := make(chan http.ConnState, 10)
+	srv := &http.Server{
+		Handler:   http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+		ConnState: func(c net.Conn, s http.ConnState) { states <- s },
+	}
+	served := make(chan error)
+	go func() { served <- srv.Serve(l) }()
+
+	resp, err := http.Get("http://" + l.Addr().String())
+	if err != nil {
+		t.Fatalf("Request returned error: %s", err)
+	}
+	resp.Body.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		t.Errorf("Shutdown() returned error: %s", err)
+	}
+	if err := <-served; err != http.ErrServerClosed {
+		t.Errorf("Got Serve() error %v. Want: %v.", err, http.ErrServerClosed)
+	}
+
+	want := []http.ConnState{http.StateNew, http.StateActive, http.StateIdle, http.StateClosed}
+	for _, w := range want {
+		select {
+		case got := <-states:
+			if got != w {
+				t.Errorf("Got connection state %v. Want: %v.", got, w)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Connection never reached state %v.", w)
+		}
+	}
+}
+
+func TestHTTPServerUpgrade(t *testing.T) {
+	skipUnlessNodeNet(t)
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "echo" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		c, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("Hijack() returned error: %s", err)
+			return
+		}
+		defer c.Close()
+		io.WriteString(rw, "HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n")
+		rw.Flush()
+		io.Copy(c, rw)
+	}))
+	defer s.Close()
+
+	c, err := net.Dial("tcp", s.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("net.Dial() returned error: %s", err)
+	}
+	defer c.Close()
+	// The data sent right after the request must reach the hijacked connection.
+	io.WriteString(c, "GET / HTTP/1.1\r\nHost: example.com\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\nhello\n")
+	r := bufio.NewReader(c)
+	resp, err := http.ReadResponse(r, nil)
+	if err != nil {
+		t.Fatalf("Reading response returned error: %s", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("Got status %d. Want: %d.", resp.StatusCode, http.StatusSwitchingProtocols)
+	}
+	line, err := r.ReadString('\n')
+	if err != nil || line != "hello\n" {
+		t.Errorf("Got echoed %q, %v. Want: %q, nil.", line, err, "hello\n")
+	}
+}
+
+func TestHTTPFetchStreaming(t *testing.T) {
+	skipUnlessNodeNet(t)
+
+	// The default transport uses the Fetch API, which must stream the request
+	// body of unknown length and the response body.
+	testHTTPStreaming(t, http.DefaultClient)
+}
+
+func TestHTTPFetchCancel(t *testing.T) {
+	skipUnlessNodeNet(t)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/body" {
+			io.WriteString(w, "data: first\n\n")
+			w.(http.Flusher).Flush()
+		}
+		select {
+		case <-stop:
+		case <-r.Context().Done():
+		}
+	}))
+	defer s.Close()
+
+	// A deadline passing while waiting for the response headers.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", s.URL+"/headers", nil)
+	if _, err := http.DefaultClient.Do(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Got request error %v. Want: %v.", err, context.DeadlineExceeded)
+	}
+
+	// Cancellation while reading the response body.
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	req, _ = http.NewRequestWithContext(ctx, "GET", s.URL+"/body", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request returned error: %s", err)
+	}
+	defer resp.Body.Close()
+	r := bufio.NewReader(resp.Body)
+	if line, err := r.ReadString('\n'); err != nil || line != "data: first\n" {
+		t.Fatalf("Got first line %q, %v. Want: %q, nil.", line, err, "data: first\n")
+	}
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	if rest, err := io.ReadAll(r); !errors.Is(err, context.Canceled) {
+		t.Errorf("Got the rest of response %q, %v. Want: %v.", rest, err, context.Canceled)
+	}
+}
+
+// xhrClient returns a client using XHRTransport. Node.js has no XMLHttpRequest,
+// so a minimal one on top of the http module is installed for the test, which
+// delivers the response body in the x-user-defined charset as browsers do.
+func xhrClient(t *testing.T) *http.Client {
+	t.Helper()
+	skipUnlessNodeNet(t)
+
+	xhr := js.Global.Call("eval", `(() => {
+		const http = require("http");
+		return class XMLHttpRequest {
+			constructor() { this.readyState = 0; this.responseText = ""; this.headers = {}; }
+			open(method, url) { this.method = method; this.url = url; this.readyState = 1; }
+			overrideMimeType(type) {}
+			setRequestHeader(key, value) { this.headers[key] = value; }
+			getAllResponseHeaders() { return this.rawHeaders; }
+			send(body) {
+				this.req = http.request(this.url, { method: this.method, headers: this.headers }, res => {
+					this.status = res.statusCode;
+					this.statusText = res.statusMessage;
+					this.rawHeaders = "";
+					for (let i = 0; i < res.rawHeaders.length; i += 2) {
+						this.rawHeaders += res.rawHeaders[i].toLowerCase() + ": " + res.rawHeaders[i + 1] + "\r\n";
+					}
+					this.readyState = 2;
+					this.onreadystatechange();
+					res.on("data", chunk => {
+						this.readyState = 3;
+						for (const b of chunk) this.responseText += String.fromCharCode(b < 0x80 ? b : 0xF700 + b);
+						this.onprogress();
+					});
+					res.on("end", () => {
+						this.readyState = 4;
+						this.onload();
+					});
+				});
+				this.req.on("error", () => { if (!this.aborted) this.onerror(); });
+				if (body) this.req.write(Buffer.from(body));
+				this.req.end();
+			}
+			abort() {
+				this.aborted = true;
+				this.req.destroy();
+				this.onabort();
+			}
+		};
+	})()`)
+	old := js.Global.Get("XMLHttpRequest")
+	js.Global.Set("XMLHttpRequest", xhr)
+	t.Cleanup(func() { js.Global.Set("XMLHttpRequest", old) })
+	return &http.Client{Transport: &http.XHRTransport{}}
+}
+
+func TestHTTPXHRStreaming(t *testing.T) {
+	client := xhrClient(t)
+
+	// The handler only sends the second line once the client has read the
+	// first one, so the response body must be streamed.
+	next := make(chan struct{})
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Echo", r.Header.Get("X-Test"))
+		io.WriteString(w, string(body)+"\n")
+		w.(http.Flusher).Flush()
+		<-next
+		io.WriteString(w, "\xff\x80 done\n")
+	}))
+	defer s.Close()
+
+	req, _ := http.NewRequest("POST", s.URL, strings.NewReader("hello"))
+	req.Header.Set("X-Test", "value")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Request returned error: %s", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Got status %d. Want: %d.", resp.StatusCode, http.StatusOK)
+	}
+	if got := resp.Header.Get("X-Echo"); got != "value" {
+		t.Errorf("Got X-Echo header %q. Want: %q.", got, "value")
+	}
+
+	r := bufio.NewReader(resp.Body)
+	if line, err := r.ReadString('\n'); err != nil || line != "hello\n" {
+		t.Fatalf("Got first line %q, %v. Want: %q, nil.", line, err, "hello\n")
+	}
+	close(next)
+	if rest, err := io.ReadAll(r); err != nil || string(rest) != "\xff\x80 done\n" {
+		t.Errorf("Got the rest of response %q, %v. Want: %q, nil.", rest, err, "\xff\x80 done\n")
+	}
+}
+
+func TestHTTPXHRCancel(t *testing.T) {
+	client := xhrClient(t)
+
+	stop := make(chan struct{})
+	defer close(stop)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/body" {
+			io.WriteString(w, "data: first\n\n")
+			w.(http.Flusher).Flush()
+		}
+		select {
+		case <-stop:
+		case <-r.Context().Done():
+		}
+	}))
+	defer s.Close()
+
+	// A deadline passing while waiting for the response headers.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", s.URL+"/headers", nil)
+	if _, err := client.Do(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Got request error %v. Want: %v.", err, context.DeadlineExceeded)
+	}
+
+	// Cancellation while reading the response body.
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	req, _ = http.NewRequestWithContext(ctx, "GET", s.URL+"/body", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Request returned error: %s", err)
+	}
+	defer resp.Body.Close()
+	r := bufio.NewReader(resp.Body)
+	if line, err := r.ReadString('\n'); err != nil || line != "data: first\n" {
+		t.Fatalf("Got first line %q, %v. Want: %q, nil.", line, err, "data: first\n")
+	}
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	if rest, err := io.ReadAll(r); !errors.Is(err, context.Canceled) {
+		t.Errorf("Got the rest of response %q, %v. Want: %v.", rest, err, context.Canceled)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"unsafe"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 
 	"github.com/gopherjs/gopherjs/js"
 )
@@ -768,6 +769,14 @@ func TestNewArrayBuffer(t *testing.T) {
 	if a.Get("byteLength").Int() != 2 {
 		t.Fail()
 	}
+
+	buf := js.Global.Get("Uint8Array").New(js.S{1, 2, 3, 4, 5, 6, 7, 8}).Get("buffer")
+	var view []byte
+	js.SliceOfTypedArray(js.Global.Get("Uint8Array").New(buf, 4, 4), &view)
+	got := js.Global.Get("Uint8Array").New(js.NewArrayBuffer(view[1:3]))
+	if got.Length() != 2 || got.Index(0).Int() != 6 || got.Index(1).Int() != 7 {
+		t.Errorf("NewArrayBuffer of a view at byte offset 4 gave %v. Want: 6,7.", got.Call("join"))
+	}
 }
 
 func TestExternalize(t *testing.T) {
@@ -1014,3 +1023,554 @@ func TestSliceData(t *testing.T) {
 		t.Errorf("slice data for different slices were the same")
 	}
 }
+
+func TestAwait(t *testing.T) {
+	resolved := js.Global.Get("Promise").Call("resolve", 42)
+	if v, err := js.Await(resolved); err != nil || v.Int() != 42 {
+		t.Errorf("Await(resolved) returned %v, %v. Want: 42, <nil>.", v, err)
+	}
+
+	deferred := js.Global.Call("eval", `new Promise((resolve) => setTimeout(() => resolve("later"), 10))`)
+	if v, err := js.Await(deferred); err != nil || v.String() != "later" {
+		t.Errorf("Await(deferred) returned %v, %v. Want: later, <nil>.", v, err)
+	}
+
+	tests := []struct {
+		reason string
+		want   string
+	}{
+		{reason: `new TypeError("boom")`, want: "JavaScript error: boom"},
+		{reason: `"boom"`, want: "JavaScript error: boom"},
+		{reason: `null`, want: "JavaScript error: null"},
+		{reason: `undefined`, want: "JavaScript error: undefined"},
+	}
+	for _, test := range tests {
+		rejected := js.Global.Call("eval", "Promise.reject("+test.reason+")")
+		_, err := js.Await(rejected)
+		jsErr, ok := err.(*js.Error)
+		if !ok {
+			t.Errorf("Await(Promise.reject(%s)) returned error %#v. Want: *js.Error.", test.reason, err)
+			continue
+		}
+		if got := jsErr.Error(); got != test.want {
+			t.Errorf("Await(Promise.reject(%s)) returned error %q. Want: %q.", test.reason, got, test.want)
+		}
+	}
+}
+
+func TestAwaitInCallback(t *testing.T) {
+	c := make(chan any)
+	js.Global.Call("setTimeout", func() {
+		defer func() { c <- recover() }()
+		js.Await(js.Global.Get("Promise").Call("resolve"))
+	}, 0)
+	if r, ok := (<-c).(string); !ok || !strings.Contains(r, "fix by wrapping code in goroutine") {
+		t.Errorf("Await() in a JavaScript callback panicked with %v. Want a message about goroutines.", r)
+	}
+}
+
+func TestNewPromise(t *testing.T) {
+	p := js.NewPromise(func() (any, error) {
+		time.Sleep(time.Millisecond)
+		return map[string]any{"answer": 42}, nil
+	})
+	if v, err := js.Await(p); err != nil || v.Get("answer").Int() != 42 {
+		t.Errorf("Await(NewPromise(...)) returned %v, %v. Want: {answer: 42}, <nil>.", v, err)
+	}
+
+	p = js.NewPromise(func() (any, error) { return nil, fmt.Errorf("boom") })
+	_, err := js.Await(p)
+	if jsErr, ok := err.(*js.Error); !ok || jsErr.Get("constructor") != js.Global.Get("Error") || jsErr.Error() != "JavaScript error: boom" {
+		t.Errorf("Await(NewPromise(...)) returned error %v. Want: an Error with message boom.", err)
+	}
+
+	reason := js.Global.Get("RangeError").New("range")
+	p = js.NewPromise(func() (any, error) { return nil, &js.Error{Object: reason} })
+	if _, err := js.Await(p); err == nil || err.(*js.Error).Object != reason {
+		t.Errorf("Await(NewPromise(...)) returned error %v. Want: the rejection reason of the *js.Error.", err)
+	}
+}
+
+func TestMakeAsyncFunc(t *testing.T) {
+	f := js.MakeAsyncFunc(func(this *js.Object, arguments []*js.Object) any {
+		time.Sleep(time.Millisecond)
+		if arguments[0].Int() < 0 {
+			panic("negative")
+		}
+		return arguments[0].Int() * 2
+	})
+	if v, err := js.Await(f.Invoke(21)); err != nil || v.Int() != 42 {
+		t.Errorf("Await(f(21)) returned %v, %v. Want: 42, <nil>.", v, err)
+	}
+	if _, err := js.Await(f.Invoke(-1)); err == nil || err.Error() != "JavaScript error: negative" {
+		t.Errorf("Await(f(-1)) returned error %v. Want: JavaScript error: negative.", err)
+	}
+}
+
+func TestAsync(t *testing.T) {
+	c := make(chan string)
+	send := func(s string) int {
+		c <- s
+		return len(s)
+	}
+	go func() {
+		if got := <-c; got != "abc" {
+			t.Errorf("Got %q from the channel. Want: abc.", got)
+		}
+	}()
+	if v, err := js.Await(js.Async(send).Invoke("abc")); err != nil || v.Int() != 3 {
+		t.Errorf("Await(js.Async(send)(\"abc\")) returned %v, %v. Want: 3, <nil>.", v, err)
+	}
+
+	var f any = func(a, b int) (int, error) { return a + b, nil }
+	v, err := js.Await(js.Async(f).Invoke(1, 2))
+	if err != nil || v.Index(0).Int() != 3 || v.Index(1) != nil {
+		t.Errorf("Await(js.Async(f)(1, 2)) returned %v, %v. Want: [3, null], <nil>.", v, err)
+	}
+
+	_, err = js.Await(js.Async(func() { panic(fmt.Errorf("boom")) }).Invoke())
+	if err == nil || err.Error() != "JavaScript error: boom" {
+		t.Errorf("Await(js.Async(panicking)()) returned error %v. Want: JavaScript error: boom.", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("js.Async(42) didn't panic.")
+		}
+	}()
+	js.Async(42)
+}
+
+type marshalInner struct {
+	Count int64 `js:"count,string"`
+	Tags  []string
+}
+
+type MarshalEmbedded struct {
+	Shared string `js:"shared"`
+}
+
+type marshalOuter struct {
+	MarshalEmbedded
+	Name     string          `js:"name"`
+	Age      int             `js:"age,omitempty"`
+	Secret   string          `js:"-"`
+	Inner    *marshalInner   `js:"inner"`
+	Scores   map[string]int  `js:"scores"`
+	Data     []byte          `js:"data"`
+	When     time.Time       `js:"when"`
+	Flag     bool            `js:"flag,string"`
+	Extra    any             `js:"extra,omitempty"`
+	Nothing  *marshalInner   `js:"nothing"`
+	ByNumber map[int]float64 `js:"byNumber"`
+	Object   *js.Object      `js:"object"`
+	hidden   int
+}
+
+func TestMarshal(t *testing.T) {
+	when := time.UnixMilli(1234567890123)
+	v := &marshalOuter{
+		MarshalEmbedded: MarshalEmbedded{Shared: "s"},
+		Name:            "gopher",
+		Secret:          "x",
+		Inner:           &marshalInner{Count: 1 << 60, Tags: []string{"a", "ü"}},
+		Scores:          map[string]int{"b": 2, "a": 1},
+		Data:            []byte{1, 2, 3},
+		When:            when,
+		Flag:            true,
+		ByNumber:        map[int]float64{10: 0.5},
+		Object:          js.Global.Get("Math"),
+		hidden:          42,
+	}
+	o := js.Marshal(v)
+	got := js.Global.Get("JSON").Call("stringify", o).String()
+	want := `{"shared":"s","name":"gopher","inner":{"count":"1152921504606846976","Tags":["a","ü"]},"scores":{"a":1,"b":2},"data":{"0":1,"1":2,"2":3},"when":"2009-02-13T23:31:30.123Z","flag":"true","nothing":null,"byNumber":{"10":0.5},"object":{}}`
+	if got != want {
+		t.Errorf("Marshal gave %s.\nWant: %s.", got, want)
+	}
+	if o.Get("data").Get("constructor") != js.Global.Get("Uint8Array") {
+		t.Errorf("Marshal gave %v for []byte. Want: a Uint8Array.", o.Get("data"))
+	}
+	if o.Get("object") != js.Global.Get("Math") {
+		t.Errorf("Marshal didn't keep the *js.Object field.")
+	}
+	if got := js.Marshal(nil); got != nil {
+		t.Errorf("Marshal(nil) gave %v. Want: null.", got)
+	}
+
+	type cycle struct{ Next *cycle }
+	c := &cycle{}
+	c.Next = c
+	defer func() {
+		if err := recover(); err == nil || fmt.Sprint(err) != "js.Marshal: cycle through *tests_test.cycle" {
+			t.Errorf("Marshal of a cycle panicked with %v. Want: js.Marshal: cycle through *tests_test.cycle.", err)
+		}
+	}()
+	js.Marshal(c)
+}
+
+func TestUnmarshal(t *testing.T) {
+	o := js.Global.Call("eval", `({
+		shared: "s",
+		NAME: "gopher",
+		age: 7,
+		Secret: "x",
+		inner: { count: "-1152921504606846976", tags: ["a", "ü"] },
+		scores: { a: 1 },
+		data: new Uint8Array([1, 2, 3]),
+		when: new Date(1234567890123),
+		flag: "true",
+		extra: { list: [1, "two"] },
+		byNumber: { "10": 0.5 },
+		object: Math,
+		unknown: 1,
+	})`)
+	v := marshalOuter{Scores: map[string]int{"b": 2}, Nothing: &marshalInner{Count: 5}}
+	if err := js.Unmarshal(o, &v); err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+	want := marshalOuter{
+		MarshalEmbedded: MarshalEmbedded{Shared: "s"},
+		Name:            "gopher",
+		Age:             7,
+		Inner:           &marshalInner{Count: -1 << 60, Tags: []string{"a", "ü"}},
+		Scores:          map[string]int{"a": 1, "b": 2},
+		Data:            []byte{1, 2, 3},
+		When:            time.UnixMilli(1234567890123),
+		Flag:            true,
+		Extra:           map[string]any{"list": []any{1.0, "two"}},
+		Nothing:         &marshalInner{Count: 5},
+		ByNumber:        map[int]float64{10: 0.5},
+		Object:          js.Global.Get("Math"),
+	}
+	if diff := cmp.Diff(want, v, cmpopts.IgnoreUnexported(marshalOuter{}), cmp.Comparer(func(a, b *js.Object) bool { return a == b })); diff != "" {
+		t.Errorf("Unmarshal gave diff (-want,+got):\n%s", diff)
+	}
+
+	errors := []struct {
+		js   string
+		want string
+	}{
+		{`({ age: "7" })`, "js: cannot unmarshal string into Go struct field marshalOuter.Age of type int"},
+		{`({ age: 1.5 })`, "js: cannot unmarshal number 1.5 into Go struct field marshalOuter.Age of type int"},
+		{`({ inner: { count: 5 } })`, "js: cannot unmarshal number 5 into Go struct field marshalOuter.Inner.Count of type int64"},
+		{`({ byNumber: { x: 1 } })`, "js: cannot unmarshal number x into Go value of type int"},
+		{`[]`, "js: cannot unmarshal array into Go value of type tests_test.marshalOuter"},
+	}
+	for _, e := range errors {
+		var v marshalOuter
+		if err := js.Unmarshal(js.Global.Call("eval", e.js), &v); err == nil || err.Error() != e.want {
+			t.Errorf("Unmarshal(%s) returned error %v. Want: %s.", e.js, err, e.want)
+		}
+	}
+	// The largest int64 and uint64 round up to 2^63 and 2^64 as doubles, which
+	// overflow them.
+	var i64 int64
+	if err := js.Unmarshal(js.Global.Call("eval", "9223372036854775808"), &i64); err == nil {
+		t.Errorf("Unmarshal of 2^63 into int64 gave %d. Want: an error.", i64)
+	}
+	var u64 uint64
+	if err := js.Unmarshal(js.Global.Call("eval", "18446744073709551616"), &u64); err == nil {
+		t.Errorf("Unmarshal of 2^64 into uint64 gave %d. Want: an error.", u64)
+	}
+	if err := js.Unmarshal(js.Global.Call("eval", "9223372036854774784"), &i64); err != nil || i64 != 9223372036854774784 {
+		t.Errorf("Unmarshal of 2^63-1024 into int64 gave %d, %v. Want: 9223372036854774784, nil.", i64, err)
+	}
+	if err := js.Unmarshal(js.Global.Call("eval", "-9223372036854775808"), &i64); err != nil || i64 != -1<<63 {
+		t.Errorf("Unmarshal of -2^63 into int64 gave %d, %v. Want: -9223372036854775808, nil.", i64, err)
+	}
+	if err := js.Unmarshal(o, v); err == nil || err.Error() != "js: Unmarshal(non-pointer tests_test.marshalOuter)" {
+		t.Errorf("Unmarshal into a non-pointer returned error %v.", err)
+	}
+}
+
+type jsUUID [2]byte
+
+func (u jsUUID) ExternalizeJS() *js.Object {
+	return js.Global.Get("String").Invoke(fmt.Sprintf("%02x-%02x", u[0], u[1]))
+}
+
+func (u *jsUUID) InternalizeJS(o *js.Object) error {
+	if _, err := fmt.Sscanf(o.String(), "%02x-%02x", &u[0], &u[1]); err != nil {
+		return fmt.Errorf("invalid UUID %q", o.String())
+	}
+	return nil
+}
+
+type jsLevel int
+
+func (l jsLevel) ExternalizeJS() *js.Object {
+	return js.Global.Get("String").Invoke([]string{"low", "high"}[l])
+}
+
+func (l *jsLevel) InternalizeJS(o *js.Object) error {
+	switch o.String() {
+	case "low":
+		*l = 0
+	case "high":
+		*l = 1
+	default:
+		return fmt.Errorf("invalid level %q", o.String())
+	}
+	return nil
+}
+
+func TestMarshalerHooks(t *testing.T) {
+	o := js.Global.Get("Object").New()
+	o.Set("id", jsUUID{0xab, 0xcd})
+	o.Set("ids", []jsUUID{{1, 2}, {3, 4}})
+	o.Set("none", (*jsUUID)(nil))
+	o.Set("level", jsLevel(1))
+	got := js.Global.Get("JSON").Call("stringify", o).String()
+	if want := `{"id":"ab-cd","ids":["01-02","03-04"],"none":null,"level":"high"}`; got != want {
+		t.Errorf("Externalized values gave %s. Want: %s.", got, want)
+	}
+
+	call := js.Global.Call("eval", `((f, ...args) => f(...args))`)
+	if got := call.Invoke(func() jsLevel { return 0 }).String(); got != "low" {
+		t.Errorf("Returned jsLevel was externalized to %q. Want: low.", got)
+	}
+	var id jsUUID
+	var level jsLevel
+	call.Invoke(func(u jsUUID, l jsLevel) { id, level = u, l }, "12-34", "high")
+	if id != (jsUUID{0x12, 0x34}) || level != 1 {
+		t.Errorf("Internalized arguments are %v, %v. Want: [18 52], 1.", id, level)
+	}
+	var p *jsUUID
+	call.Invoke(func(u *jsUUID) { p = u }, "56-78")
+	if p == nil || *p != (jsUUID{0x56, 0x78}) {
+		t.Errorf("Internalized pointer is %v. Want: &[86 120].", p)
+	}
+
+	func() {
+		defer func() {
+			if err, ok := recover().(error); !ok || err.Error() != `invalid level "medium"` {
+				t.Errorf("Internalizing an invalid level panicked with %v. Want: invalid level \"medium\".", err)
+			}
+		}()
+		call.Invoke(func(l jsLevel) {}, "medium")
+	}()
+
+	type record struct {
+		ID    jsUUID   `js:"id"`
+		Owner *jsUUID  `js:"owner"`
+		Level jsLevel  `js:"level"`
+		Tags  []string `js:"tags"`
+	}
+	m := js.Marshal(record{ID: jsUUID{1, 2}, Level: 1})
+	if got := js.Global.Get("JSON").Call("stringify", m).String(); got != `{"id":"01-02","owner":null,"level":"high","tags":null}` {
+		t.Errorf("Marshal gave %s.", got)
+	}
+	var r record
+	if err := js.Unmarshal(js.Global.Call("eval", `({ id: "0a-0b", owner: "0c-0d", level: "low" })`), &r); err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+	if r.ID != (jsUUID{10, 11}) || r.Owner == nil || *r.Owner != (jsUUID{12, 13}) || r.Level != 0 {
+		t.Errorf("Unmarshal gave %+v.", r)
+	}
+	if err := js.Unmarshal(js.Global.Call("eval", `({ id: "xyz" })`), &r); err == nil || err.Error() != `invalid UUID "xyz"` {
+		t.Errorf("Unmarshal of an invalid UUID returned error %v. Want: invalid UUID \"xyz\".", err)
+	}
+}
+
+func TestTypedArrayOf(t *testing.T) {
+	b := make([]byte, 4, 8)[1:3]
+	a := js.TypedArrayOf(b)
+	if a.Get("constructor") != js.Global.Get("Uint8Array") || a.Length() != 2 {
+		t.Fatalf("TypedArrayOf([]byte) gave %v of length %d. Want: Uint8Array of length 2.", a.Get("constructor").Get("name"), a.Length())
+	}
+	a.SetIndex(0, 42)
+	b[1] = 7
+	if b[0] != 42 || a.Index(1).Int() != 7 {
+		t.Errorf("Slice and typed array don't share memory: %v, [%v %v].", b, a.Index(0), a.Index(1))
+	}
+	if a.Get("buffer").Get("byteLength").Int() != 8 {
+		t.Errorf("Typed array buffer has %v bytes. Want: the whole backing array of 8 bytes.", a.Get("buffer").Get("byteLength"))
+	}
+
+	f := []float64{1.5}
+	if a := js.TypedArrayOf(f); a.Get("constructor") != js.Global.Get("Float64Array") || a.Index(0).Float() != 1.5 {
+		t.Errorf("TypedArrayOf([]float64) gave %v.", a)
+	}
+
+	defer func() {
+		if err := recover(); fmt.Sprint(err) != "js.TypedArrayOf: argument is not a slice of numbers" {
+			t.Errorf("TypedArrayOf([]int64) panicked with %v.", err)
+		}
+	}()
+	js.TypedArrayOf([]int64{1})
+}
+
+func TestSliceOfTypedArray(t *testing.T) {
+	a := js.Global.Get("Int32Array").New(3)
+	var s []int32
+	js.SliceOfTypedArray(a, &s)
+	if len(s) != 3 || cap(s) != 3 {
+		t.Fatalf("SliceOfTypedArray gave len %d, cap %d. Want: 3, 3.", len(s), cap(s))
+	}
+	s[2] = -5
+	a.SetIndex(0, 9)
+	if a.Index(2).Int() != -5 || s[0] != 9 {
+		t.Errorf("Slice and typed array don't share memory: %v, [%v %v %v].", s, a.Index(0), a.Index(1), a.Index(2))
+	}
+
+	buf := js.Global.Get("ArrayBuffer").New(8)
+	var u []uint16
+	js.SliceOfTypedArray(buf, &u)
+	u[0] = 0x0102
+	if len(u) != 4 || js.Global.Get("Uint8Array").New(buf).Index(0).Int() != 0x02 {
+		t.Errorf("SliceOfTypedArray(ArrayBuffer) gave %v not sharing the buffer.", u)
+	}
+
+	var b []byte
+	js.SliceOfTypedArray(js.Global.Get("Uint8ClampedArray").New(2), &b)
+	if len(b) != 2 {
+		t.Errorf("SliceOfTypedArray(Uint8ClampedArray) gave %v. Want: 2 bytes.", b)
+	}
+
+	defer func() {
+		want := "js.SliceOfTypedArray: cannot use Int32Array as []float32, need Float32Array"
+		if err := recover(); fmt.Sprint(err) != want {
+			t.Errorf("SliceOfTypedArray with a mismatched type panicked with %v. Want: %s.", err, want)
+		}
+	}()
+	var f []float32
+	js.SliceOfTypedArray(a, &f)
+}
+
+func TestChanAsyncIterator(t *testing.T) {
+	ch := make(chan int)
+	go func() {
+		for i := 1; i <= 3; i++ {
+			ch <- i
+		}
+		close(ch)
+	}()
+	collect := js.Global.Call("eval", `(async ch => {
+		const got = [];
+		for await (const v of ch) got.push(v);
+		return got.join(",");
+	})`)
+	if v, err := js.Await(collect.Invoke((<-chan int)(ch))); err != nil || v.String() != "1,2,3" {
+		t.Errorf("for await over a channel gave %v, %v. Want: 1,2,3, <nil>.", v, err)
+	}
+
+	// Leaving the loop early cancels the receive, so no value is lost.
+	ch = make(chan int)
+	go func() {
+		ch <- 1
+		ch <- 2
+	}()
+	first := js.Global.Call("eval", `(async ch => { for await (const v of ch) return v; })`)
+	if v, err := js.Await(first.Invoke(ch)); err != nil || v.Int() != 1 {
+		t.Errorf("First value of the channel is %v, %v. Want: 1, <nil>.", v, err)
+	}
+	if v := <-ch; v != 2 {
+		t.Errorf("Received %d after leaving the loop. Want: 2.", v)
+	}
+}
+
+func TestReadableStreamOf(t *testing.T) {
+	ch := make(chan string, 1)
+	go func() {
+		ch <- "a"
+		ch <- "ü"
+		close(ch)
+	}()
+	read := js.Global.Call("eval", `(async stream => {
+		const reader = stream.getReader();
+		const got = [];
+		for (;;) {
+			const { value, done } = await reader.read();
+			if (done) return got.join(",");
+			got.push(value);
+		}
+	})`)
+	if v, err := js.Await(read.Invoke(js.ReadableStreamOf(ch))); err != nil || v.String() != "a,ü" {
+		t.Errorf("Reading the stream of a channel gave %v, %v. Want: a,ü, <nil>.", v, err)
+	}
+
+	defer func() {
+		if err := recover(); fmt.Sprint(err) != "js.ReadableStreamOf: argument is not a channel that can be received from" {
+			t.Errorf("ReadableStreamOf(chan<- int) panicked with %v.", err)
+		}
+	}()
+	js.ReadableStreamOf(make(chan<- int))
+}
+
+func TestChanOfAsyncIterable(t *testing.T) {
+	gen := js.Global.Call("eval", `(async function* () {
+		yield 1;
+		yield "two";
+		throw new Error("boom");
+	})`)
+	values, errc := js.ChanOfAsyncIterable(gen.Invoke())
+	var got []string
+	for v := range values {
+		got = append(got, v.String())
+	}
+	if strings.Join(got, ",") != "1,two" {
+		t.Errorf("Received %v from the channel. Want: [1 two].", got)
+	}
+	if err := <-errc; err == nil || err.Error() != "JavaScript error: boom" {
+		t.Errorf("Iteration failed with %v. Want: JavaScript error: boom.", err)
+	}
+
+	received := make(chan []string)
+	js.Global.Call("eval", `(f => f((async function* () { yield "a"; yield "b"; })()))`).Invoke(func(ch <-chan string) {
+		go func() {
+			var got []string
+			for s := range ch {
+				got = append(got, s)
+			}
+			received <- got
+		}()
+	})
+	if got := <-received; strings.Join(got, ",") != "a,b" {
+		t.Errorf("Channel argument received %v. Want: [a b].", got)
+	}
+}
+
+//gopherjs:import Math.hypot
+func jsHypot(x, y float64) float64
+
+//gopherjs:import Math.max
+func jsMax(values ...int) int
+
+//gopherjs:import JSON.stringify
+func jsStringify(v any) string
+
+//gopherjs:import new Date
+func jsNewDate(ms float64) *js.Object
+
+//gopherjs:import Array.from
+func jsArrayFrom(arrayLike *js.Object, mapFn func(v *js.Object, i int) int) []int
+
+//gopherjs:import Object.assign
+func jsAssign(target, source *js.Object) *StructWithJsField1
+
+func TestImportDirective(t *testing.T) {
+	if got := jsHypot(3, 4); got != 5 {
+		t.Errorf("jsHypot(3, 4) = %v. Want: 5.", got)
+	}
+	if got := jsMax(1, 7, 3); got != 7 {
+		t.Errorf("jsMax(1, 7, 3) = %v. Want: 7.", got)
+	}
+	if got := jsMax(); got != 0 {
+		t.Errorf("jsMax() = %v. Want: 0 for -Infinity converted to int.", got)
+	}
+	if got := jsStringify(map[string]string{"ü": "ö"}); got != `{"ü":"ö"}` {
+		t.Errorf(`jsStringify(map[ü:ö]) = %s. Want: {"ü":"ö"}.`, got)
+	}
+	if got := jsNewDate(0).Call("toISOString").String(); got != "1970-01-01T00:00:00.000Z" {
+		t.Errorf("jsNewDate(0) = %s. Want: 1970-01-01T00:00:00.000Z.", got)
+	}
+	got := jsArrayFrom(js.Global.Call("eval", `({length: 3})`), func(v *js.Object, i int) int { return i * 2 })
+	if diff := cmp.Diff([]int{0, 2, 4}, got); diff != "" {
+		t.Errorf("jsArrayFrom() returned diff (-want,+got):\n%s", diff)
+	}
+	s := jsAssign(js.Global.Get("Object").New(), js.Global.Call("eval", `({length: 42})`))
+	if s.Length != 42 {
+		t.Errorf("jsAssign() returned struct with Length %d. Want: 42.", s.Length)
+	}
+}
@@ -0,0 +1,127 @@
+//go:build js && gopherjs
+
+package tests
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// The decoder uses JSON.parse, except for documents it would decode
+// differently from the Go implementation.
+func TestJSONUnmarshalParity(t *testing.T) {
+	type inner struct{ X, Y int }
+	type value struct {
+		Name  string
+		Count int64
+		Ratio float32
+		In    inner
+	}
+	tests := []struct {
+		name string
+		json string
+		want value
+		err  bool
+	}{{
+		name: "case-insensitive keys in document order",
+		json: `{"NAME":"a","name":"b","Name":"c"}`,
+		want: value{Name: "c"},
+	}, {
+		name: "duplicate keys merged",
+		json: `{"In":{"X":1},"In":{"Y":2}}`,
+		want: value{In: inner{X: 1, Y: 2}},
+	}, {
+		name: "integer beyond float64 precision",
+		json: `{"Count":9007199254740993}`,
+		want: value{Count: 9007199254740993},
+	}, {
+		name: "fraction into integer",
+		json: `{"Count":1.0,"Name":"after"}`,
+		want: value{Name: "after"},
+		err:  true,
+	}, {
+		name: "float32 rounding",
+		json: `{"Ratio":16777217}`,
+		want: value{Ratio: 16777216},
+	}, {
+		name: "lone surrogate",
+		json: `{"Name":"a\ud800b"}`,
+		want: value{Name: "a�b"},
+	}, {
+		name: "invalid UTF-8",
+		json: "{\"Name\":\"a\xffb\"}",
+		want: value{Name: "a�b"},
+	}, {
+		name: "number as key",
+		json: `{1:2}`,
+		err:  true,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got value
+			err := json.Unmarshal([]byte(test.json), &got)
+			if (err != nil) != test.err {
+				t.Errorf("Got error: %v. Want an error: %t.", err, test.err)
+			}
+			if got != test.want {
+				t.Errorf("Got: %+v. Want: %+v.", got, test.want)
+			}
+		})
+	}
+}
+
+func TestJSONUseNumber(t *testing.T) {
+	var got any
+	if err := json.Unmarshal([]byte(`[1.50, 1e400]`), &got); err == nil {
+		t.Errorf("Got no error for a number overflowing float64.")
+	}
+	d := json.NewDecoder(strings.NewReader(`{"a":[1.50,-0,12345678901234567890]}`))
+	d.UseNumber()
+	if err := d.Decode(&got); err != nil {
+		t.Fatalf("Decode() returned error: %v", err)
+	}
+	want := map[string]any{"a": []any{json.Number("1.50"), json.Number("-0"), json.Number("12345678901234567890")}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got: %#v. Want: %#v.", got, want)
+	}
+}
+
+// The encoder uses JSON.stringify for maps and slices of decoded JSON values.
+func TestJSONMarshalParity(t *testing.T) {
+	tests := []struct {
+		name string
+		v    any
+		want string
+		err  bool
+	}{{
+		name: "sorted keys",
+		v:    map[string]any{"b": 1, "a": 2, "10": 3, "9": 4, "é": 5},
+		want: `{"10":3,"9":4,"a":2,"b":1,"é":5}`,
+	}, {
+		name: "escaping",
+		v:    []any{"<a href=\"x\">&</a>", "\b\f\n\r\t\x01\u2028\u2029\x7f", "\xff"},
+		want: `["\u003ca href=\"x\"\u003e\u0026\u003c/a\u003e","\u0008\u000c\n\r\t\u0001\u2028\u2029` + "\x7f" + `","\ufffd"]`,
+	}, {
+		name: "numbers",
+		v:    []any{1e21, 1e-7, 123456789.125, math.Copysign(0, -1), 42, float32(0.1)},
+		want: `[1e+21,1e-7,123456789.125,-0,42,0.1]`,
+	}, {
+		name: "unsupported value",
+		v:    map[string]any{"a": math.NaN()},
+		err:  true,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := json.Marshal(test.v)
+			if (err != nil) != test.err {
+				t.Errorf("Got error: %v. Want an error: %t.", err, test.err)
+			}
+			if string(got) != test.want {
+				t.Errorf("Got: %s. Want: %s.", got, test.want)
+			}
+		})
+	}
+}
@@ -49,3 +49,53 @@ func TestDeferBuiltin(t *testing.T) {
 		t.Fatalf("%v:\n%s", err, got)
 	}
 }
+
+// Test that JavaScript iterating a channel keeps the program alive while the
+// goroutines wait for it, even though none of them uses js.Await. In a test
+// binary the test timeout timer would hide a false deadlock report.
+func TestChanIteratorPlainGoroutine(t *testing.T) {
+	if runtime.GOOS == "js" {
+		t.Skip("test meant to be run using normal Go compiler (needs os/exec)")
+	}
+
+	got, err := exec.Command("gopherjs", "run", filepath.Join("testdata", "chan_iterator.go")).CombinedOutput()
+	if err != nil {
+		t.Fatalf("%v:\n%s", err, got)
+	}
+	if want := "async iterator: 0,1,2 <nil>\nreadable stream: 0,1,2 <nil>\n"; string(got) != want {
+		t.Fatalf("Got %q, want %q.", got, want)
+	}
+}
+
+// Test that the bindings gopherjs bindgen generates return nil for object
+// properties that are null or undefined.
+func TestBindgenNullProperties(t *testing.T) {
+	if runtime.GOOS == "js" {
+		t.Skip("test meant to be run using normal Go compiler (needs os/exec)")
+	}
+
+	dir := t.TempDir()
+	if out, err := exec.Command("gopherjs", "bindgen", "--package", "main", "-o", filepath.Join(dir, "tree.go"), filepath.Join("testdata", "bindgen", "tree.d.ts")).CombinedOutput(); err != nil {
+		t.Fatalf("%v:\n%s", err, out)
+	}
+	src, err := os.ReadFile(filepath.Join("testdata", "bindgen", "main.go"))
+	if err != nil {
+		t.Fatalf("error reading main.go: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), src, 0o644); err != nil {
+		t.Fatalf("error writing main.go: %v", err)
+	}
+	got, err := exec.Command("gopherjs", "run", filepath.Join(dir, "main.go"), filepath.Join(dir, "tree.go")).CombinedOutput()
+	if err != nil {
+		t.Fatalf("%v:\n%s", err, got)
+	}
+	want := "root parent is nil: true\n" +
+		"child parent: root\n" +
+		"root children: 1 child\n" +
+		"next is nil: true\n" +
+		"next: child\n" +
+		"next after clearing: true null\n"
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Fatalf("Got diff (-want,+got):\n%s", diff)
+	}
+}
@@ -0,0 +1,211 @@
+//go:build js && gopherjs
+
+package tests
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// skipUnlessNodeNet skips the test if the Node.js net module is unavailable.
+func skipUnlessNodeNet(t *testing.T) {
+	t.Helper()
+	if js.Global.Get("require") == js.Undefined {
+		t.Skip("Sockets are only supported under Node.js.")
+	}
+}
+
+// echoServer starts a loopback server, which sends back everything it receives.
+func echoServer(t *testing.T, network, address string) net.Listener {
+	t.Helper()
+	l, err := net.Listen(network, address)
+	if err != nil {
+		t.Fatalf("net.Listen(%q, %q) returned error: %s", network, address, err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				io.Copy(c, c)
+			}()
+		}
+	}()
+	return l
+}
+
+func TestNetTCPEcho(t *testing.T) {
+	skipUnlessNodeNet(t)
+
+	l := echoServer(t, "tcp", "127.0.0.1:0")
+	c, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("net.Dial() returned error: %s", err)
+	}
+	defer c.Close()
+
+	if got, want := c.RemoteAddr().String(), l.Addr().String(); got != want {
+		t.Errorf("Got remote address %q. Want: %q.", got, want)
+	}
+	if _, err := io.WriteString(c, "hello\n"); err != nil {
+		t.Fatalf("Write() returned error: %s", err)
+	}
+	r := bufio.NewReader(c)
+	line, err := r.ReadString('\n')
+	if err != nil {
+		t.Fatalf("ReadString() returned error: %s", err)
+	}
+	if line != "hello\n" {
+		t.Errorf("Got echoed %q. Want: %q.", line, "hello\n")
+	}
+
+	// Large writes must be delivered in full despite the read buffer limit.
+	payload := make([]byte, 1<<20)
+	for i := range payload {
+		payload[i] = byte(i)
+	}
+	go c.Write(payload)
+	got := make([]byte, len(payload))
+	if _, err := io.ReadFull(r, got); err != nil {
+		t.Fatalf("ReadFull() returned error: %s", err)
+	}
+	for i := range got {
+		if got[i] != payload[i] {
+			t.Fatalf("Got byte %d at offset %d. Want: %d.", got[i], i, payload[i])
+		}
+	}
+
+	// After half-closing the connection, the server sends EOF back.
+	if err := c.(*net.TCPConn).CloseWrite(); err != nil {
+		t.Fatalf("CloseWrite() returned error: %s", err)
+	}
+	if n, err := r.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("Got Read() = %d, %v after CloseWrite(). Want: 0, EOF.", n, err)
+	}
+}
+
+func TestNetDeadline(t *testing.T) {
+	skipUnlessNodeNet(t)
+
+	l := echoServer(t, "tcp", "127.0.0.1:0")
+	c, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("net.Dial() returned error: %s", err)
+	}
+	defer c.Close()
+
+	c.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+	_, err = c.Read(make([]byte, 1))
+	if !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("Got Read() error %v. Want: %v.", err, os.ErrDeadlineExceeded)
+	}
+	if ne, ok := err.(net.Error); !ok || !ne.Timeout() {
+		t.Errorf("Got Read() error %v, which is not a timeout.", err)
+	}
+
+	// Closing the connection unblocks pending reads.
+	done := make(chan error)
+	c.SetReadDeadline(time.Time{})
+	go func() {
+		_, err := c.Read(make([]byte, 1))
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	c.Close()
+	if err := <-done; !errors.Is(err, net.ErrClosed) {
+		t.Errorf("Got Read() error %v after Close(). Want: %v.", err, net.ErrClosed)
+	}
+}
+
+func TestNetListenerClose(t *testing.T) {
+	skipUnlessNodeNet(t)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() returned error: %s", err)
+	}
+	done := make(chan error)
+	go func() {
+		_, err := l.Accept()
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	l.Close()
+	if err := <-done; !errors.Is(err, net.ErrClosed) {
+		t.Errorf("Got Accept() error %v after Close(). Want: %v.", err, net.ErrClosed)
+	}
+}
+
+func TestNetDialRefused(t *testing.T) {
+	skipUnlessNodeNet(t)
+
+	// Find a port nobody listens on.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() returned error: %s", err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	_, err = net.Dial("tcp", addr)
+	if !errors.Is(err, syscall.ECONNREFUSED) {
+		t.Errorf("Got net.Dial() error %v. Want: %v.", err, syscall.ECONNREFUSED)
+	}
+}
+
+func TestNetUnixEcho(t *testing.T) {
+	skipUnlessNodeNet(t)
+
+	path := filepath.Join(t.TempDir(), "echo.sock")
+	echoServer(t, "unix", path)
+	c, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("net.Dial() returned error: %s", err)
+	}
+	defer c.Close()
+
+	if _, err := io.WriteString(c, "hello\n"); err != nil {
+		t.Fatalf("Write() returned error: %s", err)
+	}
+	line, err := bufio.NewReader(c).ReadString('\n')
+	if err != nil || line != "hello\n" {
+		t.Errorf("Got ReadString() = %q, %v. Want: %q, nil.", line, err, "hello\n")
+	}
+}
+
+func TestNetLookupHost(t *testing.T) {
+	skipUnlessNodeNet(t)
+
+	addrs, err := net.LookupHost("localhost")
+	if err != nil {
+		t.Fatalf("net.LookupHost(\"localhost\") returned error: %s", err)
+	}
+	found := false
+	for _, a := range addrs {
+		if ip := net.ParseIP(a); ip != nil && ip.IsLoopback() {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Got net.LookupHost(\"localhost\") = %v. Want a loopback address.", addrs)
+	}
+
+	_, err = net.LookupHost("nonexistent.invalid")
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+		t.Errorf("Got net.LookupHost(\"nonexistent.invalid\") error %v. Want a not found DNSError.", err)
+	}
+}
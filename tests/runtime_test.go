@@ -3,11 +3,19 @@
 package tests
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 	_ "unsafe"
 
 	"github.com/google/go-cmp/cmp"
@@ -215,3 +223,229 @@ func Test_GoDebugInjection(t *testing.T) {
 	t.Setenv(`NOT_GODEBUG`, `gopherJSTest=bob`)
 	check(`"gopherJSTest=tom", "gopherJSTest=sam"`)
 }
+
+type finalizable struct {
+	name string
+	n    int
+}
+
+func TestSetFinalizer(t *testing.T) {
+	if js.Global.Get("FinalizationRegistry") == js.Undefined {
+		t.Skip("FinalizationRegistry is not supported by the JS engine.")
+	}
+
+	done := make(chan string, 3)
+	func() {
+		// Allocate objects in a separate function, so that they don't remain
+		// reachable from the test function's locals.
+		s := &finalizable{name: "struct"}
+		runtime.SetFinalizer(s, func(s *finalizable) { done <- s.name + " " + strconv.Itoa(s.n) })
+		s.n = 42 // Modifications after SetFinalizer() must be visible to the finalizer.
+
+		i := new(int)
+		runtime.SetFinalizer(i, func(i any) { done <- "int " + strconv.Itoa(*i.(*int)) })
+		*i = 7
+
+		cleared := &finalizable{name: "cleared"}
+		runtime.SetFinalizer(cleared, func(*finalizable) { done <- "cleared" })
+		runtime.SetFinalizer(cleared, nil)
+	}()
+
+	got := []string{}
+	deadline := time.After(5 * time.Second)
+	for len(got) < 2 {
+		runtime.GC()
+		select {
+		case s := <-done:
+			got = append(got, s)
+		case <-time.After(10 * time.Millisecond):
+		case <-deadline:
+			t.Skipf("Finalizers didn't run in time, the JS engine may not expose GC. Got: %v", got)
+		}
+	}
+
+	if len(got) == 2 && got[0] > got[1] {
+		got[0], got[1] = got[1], got[0]
+	}
+	want := []string{"int 7", "struct 42"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Finalizers produced a diff (-want,+got):\n%s", diff)
+	}
+	select {
+	case s := <-done:
+		t.Errorf("Got unexpected finalizer call: %q", s)
+	default:
+	}
+}
+
+func TestSetFinalizerErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		obj       any
+		finalizer any
+		want      string
+	}{
+		{
+			name:      "not a pointer",
+			obj:       finalizable{},
+			finalizer: func(finalizable) {},
+			want:      "runtime error: runtime.SetFinalizer: first argument is tests.finalizable, not pointer",
+		}, {
+			name:      "not a function",
+			obj:       &finalizable{},
+			finalizer: 42,
+			want:      "runtime error: runtime.SetFinalizer: second argument is int, not a function",
+		}, {
+			name:      "wrong argument",
+			obj:       &finalizable{},
+			finalizer: func(*int) {},
+			want:      "runtime error: runtime.SetFinalizer: cannot pass *tests.finalizable to finalizer func(*int)",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defer func() {
+				got := fmt.Sprint(recover())
+				if got != test.want {
+					t.Errorf("Got panic: %q. Want: %q.", got, test.want)
+				}
+			}()
+			runtime.SetFinalizer(test.obj, test.finalizer)
+		})
+	}
+}
+
+func TestReadMemStats(t *testing.T) {
+	if js.Global.Get("process") == js.Undefined {
+		t.Skip("Memory usage is only reported under Node.js.")
+	}
+
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	if m.HeapAlloc == 0 || m.HeapAlloc != m.Alloc {
+		t.Errorf("Got HeapAlloc=%d, Alloc=%d. Want: equal non-zero values.", m.HeapAlloc, m.Alloc)
+	}
+	if m.HeapSys < m.HeapInuse || m.HeapSys != m.HeapInuse+m.HeapIdle {
+		t.Errorf("Got HeapSys=%d, HeapInuse=%d, HeapIdle=%d. Want: HeapSys = HeapInuse + HeapIdle.", m.HeapSys, m.HeapInuse, m.HeapIdle)
+	}
+	if m.Sys != m.HeapSys+m.OtherSys {
+		t.Errorf("Got Sys=%d, HeapSys=%d, OtherSys=%d. Want: Sys = HeapSys + OtherSys.", m.Sys, m.HeapSys, m.OtherSys)
+	}
+	if !m.EnableGC {
+		t.Errorf("Got EnableGC=false. Want: true.")
+	}
+}
+
+func TestTrace(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := trace.Start(buf); err != nil {
+		t.Fatalf("trace.Start() returned error: %s", err)
+	}
+	if err := trace.Start(buf); err == nil {
+		t.Errorf("Second trace.Start() call returned no error.")
+	}
+
+	ctx, task := trace.NewTask(context.Background(), "test task")
+	c := make(chan int)
+	go func() {
+		for range c {
+		}
+	}()
+	trace.WithRegion(ctx, "test region", func() {
+		c <- 1
+	})
+	close(c)
+	task.End()
+	trace.Stop()
+
+	var got struct {
+		TraceEvents []struct {
+			Name  string         `json:"name"`
+			Phase string         `json:"ph"`
+			TID   int            `json:"tid"`
+			Args  map[string]any `json:"args"`
+		} `json:"traceEvents"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Failed to parse trace: %s", err)
+	}
+
+	seen := map[string]bool{}
+	tracks := map[int]bool{}
+	for _, e := range got.TraceEvents {
+		seen[e.Phase+" "+e.Name] = true
+		if e.Phase == "M" && e.Name == "thread_name" {
+			tracks[e.TID] = true
+		}
+	}
+	for _, want := range []string{"i go", "i chan send", "i chan receive", "i chan close", "i unblock", "X running", "b test task", "B test region", "E test region"} {
+		if !seen[want] {
+			t.Errorf("Trace has no %q event.", want)
+		}
+	}
+	if len(tracks) < 2 {
+		t.Errorf("Got %d goroutine tracks in the trace. Want: at least 2.", len(tracks))
+	}
+}
+
+func spinCPU(d time.Duration) int {
+	n := 0
+	for start := time.Now(); time.Since(start) < d; n++ {
+	}
+	return n
+}
+
+func TestCPUProfile(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := pprof.StartCPUProfile(buf); err != nil {
+		t.Fatalf("StartCPUProfile() returned error: %s", err)
+	}
+	if err := pprof.StartCPUProfile(buf); err == nil {
+		t.Errorf("Second StartCPUProfile() call returned no error.")
+	}
+	spinCPU(200 * time.Millisecond)
+	pprof.StopCPUProfile()
+
+	r, err := gzip.NewReader(buf)
+	if err != nil {
+		t.Fatalf("CPU profile is not gzip-compressed: %s", err)
+	}
+	profile, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Failed to decompress CPU profile: %s", err)
+	}
+	// The string table of the profile must contain the Go function name, if the
+	// frame was mapped through the source map.
+	if want := "tests.spinCPU"; !bytes.Contains(profile, []byte(want)) {
+		t.Errorf("CPU profile doesn't mention %q.", want)
+	}
+}
+
+func TestGoroutineProfile(t *testing.T) {
+	p := pprof.Lookup("goroutine")
+	before := p.Count()
+
+	c := make(chan int)
+	for i := 0; i < 3; i++ {
+		go func() { <-c }()
+	}
+	runtime.Gosched()
+
+	if got, want := p.Count(), before+3; got != want {
+		t.Errorf("Got goroutine profile count %d. Want: %d.", got, want)
+	}
+	buf := &bytes.Buffer{}
+	if err := p.WriteTo(buf, 2); err != nil {
+		t.Fatalf("WriteTo() returned error: %s", err)
+	}
+	if got, want := strings.Count(buf.String(), "[chan receive]"), 3; got < want {
+		t.Errorf("Got %d goroutines blocked on a channel in the profile:\n%s\nWant: at least %d.", got, buf.String(), want)
+	}
+
+	close(c)
+	runtime.Gosched()
+	if got := p.Count(); got != before {
+		t.Errorf("Got goroutine profile count %d after goroutines exited. Want: %d.", got, before)
+	}
+}
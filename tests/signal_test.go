@@ -0,0 +1,57 @@
+//go:build js && gopherjs
+
+package tests
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+func TestSignalNotify(t *testing.T) {
+	process := js.Global.Get("process")
+	if process == js.Undefined || process.Get("kill") == js.Undefined {
+		t.Skip("Signals are only supported under Node.js.")
+	}
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGTERM)
+	if got := process.Call("listenerCount", "SIGTERM").Int(); got != 1 {
+		t.Errorf("Got %d SIGTERM listeners after signal.Notify(). Want: 1.", got)
+	}
+
+	process.Call("kill", process.Get("pid"), "SIGTERM")
+	select {
+	case sig := <-c:
+		if sig != syscall.SIGTERM {
+			t.Errorf("Got signal %v. Want: %v.", sig, syscall.SIGTERM)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for SIGTERM.")
+	}
+
+	signal.Stop(c)
+	if got := process.Call("listenerCount", "SIGTERM").Int(); got != 0 {
+		t.Errorf("Got %d SIGTERM listeners after signal.Stop(). Want: 0.", got)
+	}
+}
+
+func TestSignalIgnore(t *testing.T) {
+	if js.Global.Get("process") == js.Undefined {
+		t.Skip("Signals are only supported under Node.js.")
+	}
+	defer signal.Reset(syscall.SIGQUIT)
+
+	signal.Ignore(syscall.SIGQUIT)
+	if !signal.Ignored(syscall.SIGQUIT) {
+		t.Errorf("Got signal.Ignored(SIGQUIT) = false after signal.Ignore(). Want: true.")
+	}
+	signal.Reset(syscall.SIGQUIT)
+	if signal.Ignored(syscall.SIGQUIT) {
+		t.Errorf("Got signal.Ignored(SIGQUIT) = true after signal.Reset(). Want: false.")
+	}
+}
@@ -0,0 +1,80 @@
+//go:build js && gopherjs
+
+package tests
+
+import (
+	"errors"
+	"syscall/js"
+	"testing"
+
+	gopherjs "github.com/gopherjs/gopherjs/js"
+)
+
+func TestSyscallJSAwait(t *testing.T) {
+	p := js.NewPromise(func() (any, error) { return "done", nil })
+	if v, err := js.Await(p); err != nil || v.String() != "done" {
+		t.Errorf("Await(NewPromise(...)) returned %v, %v. Want: done, <nil>.", v, err)
+	}
+
+	p = js.NewPromise(func() (any, error) { return nil, errors.New("boom") })
+	_, err := js.Await(p)
+	if jsErr, ok := err.(js.Error); !ok || jsErr.Get("message").String() != "boom" {
+		t.Errorf("Await(NewPromise(...)) returned error %v. Want: js.Error with message boom.", err)
+	}
+
+	reason := js.Global().Get("TypeError").New("type")
+	p = js.NewPromise(func() (any, error) { return nil, js.Error{Value: reason} })
+	if _, err := js.Await(p); err == nil || !err.(js.Error).Equal(reason) {
+		t.Errorf("Await(NewPromise(...)) returned error %v. Want: the rejection reason of the js.Error.", err)
+	}
+}
+
+func TestSyscallJSAsyncFuncOf(t *testing.T) {
+	c := make(chan int, 1)
+	f := js.AsyncFuncOf(func(this js.Value, args []js.Value) any {
+		c <- args[0].Int()
+		return <-c + 1
+	})
+	defer f.Release()
+	if v, err := js.Await(f.Invoke(41)); err != nil || v.Int() != 42 {
+		t.Errorf("Await(f(41)) returned %v, %v. Want: 42, <nil>.", v, err)
+	}
+}
+
+type syscallJSWrapper struct{ name string }
+
+func (w syscallJSWrapper) JSValue() js.Value { return js.ValueOf("wrapper " + w.name) }
+
+type syscallJSMarshaler int
+
+func (m syscallJSMarshaler) ExternalizeJS() *gopherjs.Object {
+	return gopherjs.Global.Get("String").Invoke(int(m) * 2)
+}
+
+func TestSyscallJSValueOfHooks(t *testing.T) {
+	if got := js.ValueOf(syscallJSWrapper{"a"}).String(); got != "wrapper a" {
+		t.Errorf("ValueOf(Wrapper) gave %q. Want: wrapper a.", got)
+	}
+	if got := js.ValueOf(syscallJSMarshaler(21)); got.Type() != js.TypeString || got.String() != "42" {
+		t.Errorf("ValueOf(js.Marshaler) gave %v. Want: string 42.", got)
+	}
+	if got := js.ValueOf([]any{syscallJSMarshaler(1)}).Index(0).String(); got != "2" {
+		t.Errorf("ValueOf([]any{js.Marshaler}) gave element %q. Want: 2.", got)
+	}
+}
+
+func TestSyscallJSTypedArrayOf(t *testing.T) {
+	b := []byte{1, 2, 3}
+	a := js.TypedArrayOf(b)
+	a.SetIndex(1, 20)
+	if b[1] != 20 {
+		t.Errorf("TypedArrayOf(b) doesn't share memory with b = %v.", b)
+	}
+
+	var view []byte
+	js.SliceOfTypedArray(a, &view)
+	view[2] = 30
+	if b[2] != 30 {
+		t.Errorf("SliceOfTypedArray(TypedArrayOf(b)) doesn't share memory with b = %v.", b)
+	}
+}
@@ -0,0 +1,34 @@
+//go:build js
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// Uses the bindings gopherjs bindgen generates for tree.d.ts, see
+// TestBindgenNullProperties.
+func main() {
+	js.Global.Call("eval", `globalThis.TreeNode = class {
+		constructor(name, parent) {
+			this.name = name;
+			this.parent = parent ?? null;
+			this.children = [];
+			this.next = undefined;
+			if (parent) parent.children.push(this);
+		}
+	}`)
+
+	root := NewTreeNode("root")
+	child := NewTreeNode("child", root)
+	fmt.Println("root parent is nil:", root.Parent() == nil)
+	fmt.Println("child parent:", child.Parent().Name)
+	fmt.Println("root children:", len(root.Children()), root.Children()[0].Name)
+	fmt.Println("next is nil:", root.Next() == nil)
+	root.SetNext(child)
+	fmt.Println("next:", root.Next().Name)
+	root.SetNext(nil)
+	fmt.Println("next after clearing:", root.Next() == nil, root.Object.Get("next"))
+}
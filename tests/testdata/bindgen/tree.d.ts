@@ -0,0 +1,11 @@
+interface TreeNode {
+    readonly name: string;
+    readonly parent: TreeNode | null;
+    readonly children: TreeNode[];
+    next: TreeNode | null;
+}
+
+declare var TreeNode: {
+    prototype: TreeNode;
+    new(name: string, parent?: TreeNode): TreeNode;
+};
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// produce sends 0, 1 and 2 on a new channel from a goroutine that doesn't use
+// js.Await, and closes done after the last value was received.
+func produce() (ch chan int, done chan struct{}) {
+	ch, done = make(chan int), make(chan struct{})
+	go func() {
+		for i := 0; i < 3; i++ {
+			ch <- i
+		}
+		close(ch)
+		close(done)
+	}()
+	return ch, done
+}
+
+func main() {
+	collect := js.Global.Call("eval", `(async it => {
+		const got = [];
+		for await (const v of it) got.push(v);
+		return got.join(",");
+	})`)
+
+	ch, done := produce()
+	p := collect.Invoke((<-chan int)(ch))
+	<-done
+	v, err := js.Await(p)
+	fmt.Println("async iterator:", v, err)
+
+	ch, done = produce()
+	p = collect.Invoke(js.ReadableStreamOf(ch))
+	<-done
+	v, err = js.Await(p)
+	fmt.Println("readable stream:", v, err)
+}
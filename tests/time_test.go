@@ -0,0 +1,114 @@
+//go:build js && gopherjs
+
+package tests
+
+import (
+	"testing"
+	"time"
+	_ "unsafe" // for go:linkname
+)
+
+func TestTimeLocalZone(t *testing.T) {
+	// Node.js reports the time zone given by the TZ environment variable to
+	// the Intl API, which the local time zone is based on.
+	cmd := helperCommand(t, "zones")
+	cmd.Env = append(cmd.Env, "TZ=America/New_York")
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("Output() returned error: %s", err)
+	}
+	if want := "EST -0500\nEDT -0400\n"; string(out) != want {
+		t.Errorf("Got local zones %q. Want: %q.", out, want)
+	}
+}
+
+// loadIntlLocation loads a time zone from the Intl API, even if it is found in
+// the IANA Time Zone database sources of the system.
+//
+//go:linkname loadIntlLocation time.loadIntlLocation
+func loadIntlLocation(name string) (*time.Location, bool)
+
+func TestTimeLoadLocation(t *testing.T) {
+	tests := []struct {
+		zone       string
+		time       time.Time // in UTC
+		want       string
+		start, end time.Time
+	}{{
+		zone:  "Europe/Berlin",
+		time:  time.Date(2024, time.January, 15, 11, 0, 0, 0, time.UTC),
+		want:  "2024-01-15T12:00:00+01:00 CET",
+		start: time.Date(2023, time.October, 29, 1, 0, 0, 0, time.UTC),
+		end:   time.Date(2024, time.March, 31, 1, 0, 0, 0, time.UTC),
+	}, {
+		zone:  "Europe/Berlin",
+		time:  time.Date(2024, time.July, 15, 10, 0, 0, 0, time.UTC),
+		want:  "2024-07-15T12:00:00+02:00 CEST",
+		start: time.Date(2024, time.March, 31, 1, 0, 0, 0, time.UTC),
+		end:   time.Date(2024, time.October, 27, 1, 0, 0, 0, time.UTC),
+	}, {
+		zone:  "Australia/Sydney",
+		time:  time.Date(2024, time.January, 15, 1, 0, 0, 0, time.UTC),
+		want:  "2024-01-15T12:00:00+11:00 AEDT",
+		start: time.Date(2023, time.September, 30, 16, 0, 0, 0, time.UTC),
+		end:   time.Date(2024, time.April, 6, 16, 0, 0, 0, time.UTC),
+	}}
+	for _, test := range tests {
+		loc, ok := loadIntlLocation(test.zone)
+		if !ok {
+			t.Fatalf("Intl doesn't know time zone %q.", test.zone)
+		}
+		if loc.String() != test.zone {
+			t.Errorf("Got location name %q. Want: %q.", loc, test.zone)
+		}
+		tm := test.time.In(loc)
+		if got := tm.Format(time.RFC3339 + " MST"); got != test.want {
+			t.Errorf("Got time %q in %s. Want: %q.", got, test.zone, test.want)
+		}
+		if start, end := tm.ZoneBounds(); !start.Equal(test.start) || !end.Equal(test.end) {
+			t.Errorf("Got zone bounds %v, %v for %v. Want: %v, %v.", start, end, tm, test.start, test.end)
+		}
+		if got, err := time.ParseInLocation("2006-01-02 15:04 MST", tm.Format("2006-01-02 15:04 MST"), loc); err != nil || !got.Equal(test.time.Truncate(time.Minute)) {
+			t.Errorf("Got parsed time %v, %v. Want: %v, nil.", got, err, test.time)
+		}
+	}
+	if _, ok := loadIntlLocation("Nowhere/Special"); ok {
+		t.Errorf("Intl loaded an unknown time zone.")
+	}
+	if _, err := time.LoadLocation("Nowhere/Special"); err == nil {
+		t.Errorf("LoadLocation() loaded an unknown time zone.")
+	}
+}
+
+func TestTimeLoadLocationMatchesTZData(t *testing.T) {
+	for _, zone := range []string{"Europe/Berlin", "America/New_York", "America/Sao_Paulo", "Australia/Lord_Howe"} {
+		want, err := time.LoadLocation(zone)
+		if err != nil {
+			t.Skipf("The IANA Time Zone database is unavailable: %s", err)
+		}
+		got, _ := loadIntlLocation(zone)
+		// Transitions are probed weekly, so they're only found if the zone
+		// lasts longer than that, which is the case since 1990.
+		for sec := time.Date(1990, time.January, 1, 0, 0, 0, 0, time.UTC).Unix(); sec < time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC).Unix(); sec += 86400 {
+			g, w := time.Unix(sec, 0).In(got), time.Unix(sec, 0).In(want)
+			gName, gOffset := g.Zone()
+			wName, wOffset := w.Zone()
+			if gOffset != wOffset {
+				t.Fatalf("Got offset %d of %s at %v. Want: %d.", gOffset, zone, g, wOffset)
+			}
+			gStart, gEnd := g.ZoneBounds()
+			wStart, wEnd := w.ZoneBounds()
+			// The database ends the last zone of zones without daylight
+			// saving time at the end of its 32-bit transition times, in 2038.
+			if gEnd.IsZero() && wEnd.Year() >= 2037 {
+				wEnd = gEnd
+			}
+			if !gStart.Equal(wStart) || !gEnd.Equal(wEnd) {
+				t.Fatalf("Got zone bounds %v, %v of %s at %v. Want: %v, %v.", gStart, gEnd, zone, g, wStart, wEnd)
+			}
+			if gName != wName && wName[0] != '-' && wName[0] != '+' {
+				t.Fatalf("Got zone name %q of %s at %v. Want: %q.", gName, zone, g, wName)
+			}
+		}
+	}
+}
@@ -34,6 +34,7 @@ import (
 	"github.com/gopherjs/gopherjs/compiler"
 	"github.com/gopherjs/gopherjs/compiler/errlist"
 	"github.com/gopherjs/gopherjs/compiler/incjs"
+	"github.com/gopherjs/gopherjs/internal/bindgen"
 	"github.com/gopherjs/gopherjs/internal/sourcemapx"
 	"github.com/gopherjs/gopherjs/internal/sysutil"
 )
@@ -83,6 +84,7 @@ func main() {
 	compilerFlags.BoolVar(&options.MapToLocalDisk, "localmap", false, "use local paths for sourcemap")
 	compilerFlags.BoolVarP(&options.NoCache, "no_cache", "a", false, "rebuild all packages from scratch")
 	compilerFlags.BoolVarP(&options.CreateMapFile, "source_map", "s", true, "enable generation of source maps")
+	compilerFlags.BoolVar(&options.BlockingCallbackErrors, "blocking_callback_errors", false, "report functions passed to JavaScript that may block as errors instead of warnings")
 
 	flagWatch := pflag.NewFlagSet("", 0)
 	flagWatch.BoolVarP(&options.Watch, "watch", "w", false, "watch for changes to the source files")
@@ -515,6 +517,43 @@ func main() {
 		return nil
 	}
 
+	cmdBindgen := &cobra.Command{
+		Use:   "bindgen [files.d.ts]",
+		Short: "generate Go bindings from TypeScript declaration files",
+		Args:  cobra.MinimumNArgs(1),
+	}
+	var bindgenOpts bindgen.Options
+	var bindgenOutput string
+	cmdBindgen.Flags().StringVarP(&bindgenOpts.Package, "package", "", "", "name of the generated package (default: the base name of the first file)")
+	cmdBindgen.Flags().StringVarP(&bindgenOpts.Module, "module", "", "", "load declarations from the given module with require() instead of the global object")
+	cmdBindgen.Flags().StringVarP(&bindgenOutput, "output", "o", "", "output file (default: standard output)")
+	cmdBindgen.RunE = func(cmd *cobra.Command, args []string) error {
+		var files []bindgen.File
+		for _, name := range args {
+			content, err := os.ReadFile(name)
+			if err != nil {
+				return err
+			}
+			files = append(files, bindgen.File{Name: name, Content: string(content)})
+		}
+		opts := bindgenOpts
+		if opts.Package == "" {
+			opts.Package = bindgenPackageName(args[0])
+		}
+		src, warnings, err := bindgen.Generate(files, opts)
+		if err != nil {
+			return err
+		}
+		for _, w := range warnings {
+			fmt.Fprintln(os.Stderr, w)
+		}
+		if bindgenOutput == "" {
+			_, err := os.Stdout.Write(src)
+			return err
+		}
+		return os.WriteFile(bindgenOutput, src, 0o666)
+	}
+
 	cmdVersion := &cobra.Command{
 		Use:   "version",
 		Short: "print GopherJS compiler version",
@@ -538,7 +577,7 @@ func main() {
 		SilenceUsage:  true,
 		SilenceErrors: true,
 	}
-	rootCmd.AddCommand(cmdBuild, cmdGet, cmdInstall, cmdRun, cmdTest, cmdServe, cmdVersion, cmdDoc, cmdClean)
+	rootCmd.AddCommand(cmdBuild, cmdGet, cmdInstall, cmdRun, cmdTest, cmdServe, cmdBindgen, cmdVersion, cmdDoc, cmdClean)
 
 	{
 		var logLevel string
@@ -957,3 +996,25 @@ func runTestDir(p *gbuild.PackageData) string {
 	// Run tests in the package directory.
 	return p.Dir
 }
+
+// bindgenPackageName returns the default package name for bindings generated
+// from the declaration file at path, for example "dom" for "lib.dom.d.ts".
+func bindgenPackageName(path string) string {
+	base := strings.TrimSuffix(filepath.Base(path), ".d.ts")
+	if i := strings.LastIndexByte(base, '.'); i >= 0 {
+		base = base[i+1:]
+	}
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+		if r >= 'A' && r <= 'Z' {
+			return r - 'A' + 'a'
+		}
+		return -1
+	}, base)
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		name = "bindings" + name
+	}
+	return name
+}